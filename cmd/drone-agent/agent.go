@@ -35,6 +35,7 @@ import (
 	"github.com/cncd/pipeline/pipeline/multipart"
 	"github.com/cncd/pipeline/pipeline/rpc"
 
+	"github.com/drone/drone/version"
 	"github.com/drone/signal"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -75,10 +76,12 @@ func loop(c *cli.Context) error {
 	counter.Running = 0
 
 	if c.BoolT("healthcheck") {
+		if c.Bool("metrics") {
+			handleMetrics()
+		}
 		go http.ListenAndServe(":3000", nil)
 	}
 
-	// TODO pass version information to grpc server
 	// TODO authenticate to grpc server
 
 	// grpc.Dial(target, ))
@@ -106,7 +109,11 @@ func loop(c *cli.Context) error {
 	sigterm := abool.New()
 	ctx := metadata.NewOutgoingContext(
 		context.Background(),
-		metadata.Pairs("hostname", hostname),
+		metadata.Pairs(
+			"hostname", hostname,
+			"capacity", strconv.Itoa(c.Int("max-procs")),
+			"version", version.Version.String(),
+		),
 	)
 	ctx = signal.WithContextFunc(ctx, func() {
 		println("ctrl+c received, terminating process")
@@ -375,6 +382,7 @@ func (r *runner) run(ctx context.Context) error {
 				Msg("update step status complete")
 		}()
 		if state.Process.Exited {
+			stepCount.WithLabelValues(exitStatus(state.Process.ExitCode)).Inc()
 			return nil
 		}
 		if state.Pipeline.Step.Environment == nil {
@@ -426,6 +434,7 @@ func (r *runner) run(ctx context.Context) error {
 			state.ExitCode = 137
 		}
 	}
+	pipelineCount.WithLabelValues(exitStatus(state.ExitCode)).Inc()
 
 	logger.Debug().
 		Str("error", state.Error).
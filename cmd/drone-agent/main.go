@@ -98,6 +98,11 @@ func main() {
 			Name:   "healthcheck",
 			Usage:  "enable healthcheck endpoint",
 		},
+		cli.BoolFlag{
+			EnvVar: "DRONE_METRICS",
+			Name:   "metrics",
+			Usage:  "enable prometheus metrics endpoint on the healthcheck server",
+		},
 		cli.DurationFlag{
 			EnvVar: "DRONE_KEEPALIVE_TIME",
 			Name:   "keepalive-time",
@@ -0,0 +1,80 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// the file implements the prometheus metrics served on the healthcheck
+// endpoint when the metrics flag is enabled.
+
+var (
+	pipelineCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "drone_agent",
+			Name:      "pipelines_total",
+			Help:      "Total number of pipelines executed by the agent.",
+		},
+		[]string{"status"},
+	)
+
+	stepCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "drone_agent",
+			Name:      "steps_total",
+			Help:      "Total number of pipeline steps executed by the agent.",
+		},
+		[]string{"status"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(
+		pipelineCount,
+		stepCount,
+		prometheus.NewGaugeFunc(
+			prometheus.GaugeOpts{
+				Namespace: "drone_agent",
+				Name:      "pipelines_running",
+				Help:      "Number of pipelines running on the agent.",
+			},
+			func() float64 {
+				counter.Lock()
+				defer counter.Unlock()
+				return float64(counter.Running)
+			},
+		),
+	)
+}
+
+func handleMetrics() {
+	http.Handle("/metrics", promhttp.Handler())
+}
+
+// exitStatus returns the status of a pipeline or step from its exit code.
+func exitStatus(code int) string {
+	switch code {
+	case 0:
+		return "success"
+	case 137:
+		return "killed"
+	default:
+		return "failure"
+	}
+}
@@ -32,6 +32,9 @@ func main() {
 	app.Action = server
 	app.Flags = flags
 	app.Before = before
+	app.Commands = []cli.Command{
+		migrateLogsCommand,
+	}
 
 	if err := app.Run(os.Args); err != nil {
 		fmt.Fprintln(os.Stderr, err)
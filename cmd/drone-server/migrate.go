@@ -0,0 +1,106 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/drone/drone/model"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+var migrateLogsCommand = cli.Command{
+	Name:   "migrate-logs",
+	Usage:  "move build logs from the database to the log storage",
+	Action: migrateLogs,
+	Flags: append(
+		flagsNamed("driver", "datasource"),
+		flagsWithPrefix("log-")...,
+	),
+}
+
+func migrateLogs(c *cli.Context) error {
+	if c.String("log-storage") == "" || c.String("log-storage") == "database" {
+		return fmt.Errorf("log storage is the database, nothing to migrate")
+	}
+
+	store_ := setupStore(c)
+	logStore := setupLogStore(c, store_)
+
+	var after int64
+	var count int
+	for {
+		ids, err := store_.LogListProcs(after, 100)
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			break
+		}
+		for _, id := range ids {
+			after = id
+			proc := &model.Proc{ID: id}
+			if err := migrateLog(store_, logStore, proc); err != nil {
+				logrus.Errorf("cannot migrate logs for proc %d. %s", id, err)
+				continue
+			}
+			count++
+		}
+	}
+	fmt.Printf("migrated %d logs\n", count)
+	return nil
+}
+
+// migrateLog copies the proc logs from the database to the log storage,
+// and deletes the logs from the database once copied.
+func migrateLog(from, to model.LogStore, proc *model.Proc) error {
+	rc, err := from.LogFind(proc)
+	if err != nil {
+		return err
+	}
+	err = to.LogSave(proc, rc)
+	rc.Close()
+	if err != nil {
+		return err
+	}
+	return from.LogDelete(proc)
+}
+
+// flagsNamed returns the server flags with the given names.
+func flagsNamed(names ...string) []cli.Flag {
+	var out []cli.Flag
+	for _, flag := range flags {
+		for _, name := range names {
+			if flag.GetName() == name {
+				out = append(out, flag)
+			}
+		}
+	}
+	return out
+}
+
+// flagsWithPrefix returns the server flags with the given name prefix.
+func flagsWithPrefix(prefix string) []cli.Flag {
+	var out []cli.Flag
+	for _, flag := range flags {
+		if strings.HasPrefix(flag.GetName(), prefix) {
+			out = append(out, flag)
+		}
+	}
+	return out
+}
@@ -16,6 +16,7 @@ package main
 
 import (
 	"context"
+	"crypto/subtle"
 	"crypto/tls"
 	"errors"
 	"net"
@@ -33,9 +34,8 @@ import (
 	"golang.org/x/crypto/acme/autocert"
 	"golang.org/x/sync/errgroup"
 
-	"github.com/cncd/logging"
 	"github.com/cncd/pipeline/pipeline/rpc/proto"
-	"github.com/cncd/pubsub"
+	"github.com/drone/drone/model"
 	"github.com/drone/drone/plugins/sender"
 	"github.com/drone/drone/remote"
 	"github.com/drone/drone/router"
@@ -119,6 +119,35 @@ var flags = []cli.Flag{
 		Usage:  "session expiration time",
 		Value:  time.Hour * 72,
 	},
+	cli.DurationFlag{
+		EnvVar: "DRONE_CRON_INTERVAL",
+		Name:   "cron-interval",
+		Usage:  "interval at which cron jobs are evaluated",
+		Value:  time.Minute,
+	},
+	cli.DurationFlag{
+		EnvVar: "DRONE_RETENTION_INTERVAL",
+		Name:   "retention-interval",
+		Usage:  "interval at which build retention policies are applied",
+		Value:  time.Hour,
+	},
+	cli.IntFlag{
+		EnvVar: "DRONE_WEBHOOK_RETRIES",
+		Name:   "webhook-retries",
+		Usage:  "number of webhook delivery attempts",
+		Value:  5,
+	},
+	cli.DurationFlag{
+		EnvVar: "DRONE_WEBHOOK_BACKOFF",
+		Name:   "webhook-backoff",
+		Usage:  "initial backoff between webhook delivery attempts",
+		Value:  time.Second * 5,
+	},
+	cli.BoolFlag{
+		EnvVar: "DRONE_WEBHOOK_ALLOW_PRIVATE",
+		Name:   "webhook-allow-private",
+		Usage:  "allow repository webhooks to target private network addresses",
+	},
 	cli.StringSliceFlag{
 		EnvVar: "DRONE_ESCALATE",
 		Name:   "escalate",
@@ -157,6 +186,103 @@ var flags = []cli.Flag{
 		Name:   "gating-service",
 		Usage:  "gated build endpoint",
 	},
+	cli.StringFlag{
+		EnvVar: "DRONE_ENVIRON_ENDPOINT",
+		Name:   "environ-service",
+		Usage:  "environment plugin endpoint",
+	},
+	cli.StringFlag{
+		EnvVar: "DRONE_ENVIRON_FILE",
+		Name:   "environ-file",
+		Usage:  "file path for global environment variables",
+	},
+	cli.BoolFlag{
+		EnvVar: "DRONE_QUEUE_DATABASE",
+		Name:   "queue-database",
+		Usage:  "dispatch builds from the database queue, allowing multiple servers",
+	},
+	cli.IntFlag{
+		EnvVar: "DRONE_LIMIT_REPO_PIPELINES",
+		Name:   "limit-repo-pipelines",
+		Usage:  "maximum number of concurrently running pipelines per repository",
+	},
+	cli.IntFlag{
+		EnvVar: "DRONE_LIMIT_OWNER_PIPELINES",
+		Name:   "limit-owner-pipelines",
+		Usage:  "maximum number of concurrently running pipelines per repository owner",
+	},
+	cli.BoolFlag{
+		EnvVar: "DRONE_STATUS_PIPELINES",
+		Name:   "status-pipelines",
+		Usage:  "send a commit status for each matrix pipeline in addition to the build status",
+	},
+	cli.StringFlag{
+		EnvVar: "DRONE_PUBSUB_DRIVER",
+		Name:   "pubsub-driver",
+		Usage:  "pubsub driver (memory or redis)",
+		Value:  "memory",
+	},
+	cli.StringFlag{
+		EnvVar: "DRONE_LOGGING_DRIVER",
+		Name:   "logging-driver",
+		Usage:  "live log streaming driver (memory or redis)",
+		Value:  "memory",
+	},
+	cli.StringFlag{
+		EnvVar: "DRONE_REDIS_URL",
+		Name:   "redis-url",
+		Usage:  "redis server url, required by the redis drivers",
+		Value:  "redis://localhost:6379",
+	},
+	cli.StringFlag{
+		EnvVar: "DRONE_LOG_STORAGE",
+		Name:   "log-storage",
+		Usage:  "log storage driver (database, filesystem or s3)",
+		Value:  "database",
+	},
+	cli.StringFlag{
+		EnvVar: "DRONE_LOG_STORAGE_PATH",
+		Name:   "log-storage-path",
+		Usage:  "log storage directory for the filesystem driver",
+		Value:  "/var/lib/drone/logs",
+	},
+	cli.StringFlag{
+		EnvVar: "DRONE_LOG_S3_ENDPOINT",
+		Name:   "log-s3-endpoint",
+		Usage:  "log storage s3 endpoint",
+		Value:  "https://s3.amazonaws.com",
+	},
+	cli.StringFlag{
+		EnvVar: "DRONE_LOG_S3_BUCKET",
+		Name:   "log-s3-bucket",
+		Usage:  "log storage s3 bucket",
+	},
+	cli.StringFlag{
+		EnvVar: "DRONE_LOG_S3_PREFIX",
+		Name:   "log-s3-prefix",
+		Usage:  "log storage s3 key prefix",
+	},
+	cli.StringFlag{
+		EnvVar: "DRONE_LOG_S3_REGION,AWS_REGION",
+		Name:   "log-s3-region",
+		Usage:  "log storage s3 region",
+		Value:  "us-east-1",
+	},
+	cli.StringFlag{
+		EnvVar: "DRONE_LOG_S3_ACCESS_KEY,AWS_ACCESS_KEY_ID",
+		Name:   "log-s3-access-key",
+		Usage:  "log storage s3 access key",
+	},
+	cli.StringFlag{
+		EnvVar: "DRONE_LOG_S3_SECRET_KEY,AWS_SECRET_ACCESS_KEY",
+		Name:   "log-s3-secret-key",
+		Usage:  "log storage s3 secret key",
+	},
+	cli.BoolFlag{
+		EnvVar: "DRONE_LOG_S3_PATH_STYLE",
+		Name:   "log-s3-path-style",
+		Usage:  "log storage s3 path style addressing, required for minio",
+	},
 	cli.StringFlag{
 		EnvVar: "DRONE_DATABASE_DRIVER,DATABASE_DRIVER",
 		Name:   "driver",
@@ -259,6 +385,26 @@ var flags = []cli.Flag{
 		Name:   "github-git-password",
 		Usage:  "github machine user password",
 	},
+	cli.Int64Flag{
+		EnvVar: "DRONE_GITHUB_APP_ID",
+		Name:   "github-app-id",
+		Usage:  "github app id, authenticates repository access as the app installation",
+	},
+	cli.StringFlag{
+		EnvVar: "DRONE_GITHUB_APP_PRIVATE_KEY",
+		Name:   "github-app-private-key",
+		Usage:  "github app private key file",
+	},
+	cli.StringFlag{
+		EnvVar: "DRONE_GITHUB_APP_PRIVATE_KEY_STRING",
+		Name:   "github-app-private-key-string",
+		Usage:  "github app private key string",
+	},
+	cli.BoolFlag{
+		EnvVar: "DRONE_GITHUB_CHECKS",
+		Name:   "github-checks",
+		Usage:  "github reports pipelines as check runs, requires a github app",
+	},
 	cli.BoolTFlag{
 		EnvVar: "DRONE_GITHUB_MERGE_REF",
 		Name:   "github-merge-ref",
@@ -305,6 +451,47 @@ var flags = []cli.Flag{
 		Name:   "gogs-skip-verify",
 		Usage:  "gogs skip ssl verification",
 	},
+	cli.BoolFlag{
+		EnvVar: "DRONE_GERRIT",
+		Name:   "gerrit",
+		Usage:  "gerrit driver is enabled",
+	},
+	cli.StringFlag{
+		EnvVar: "DRONE_GERRIT_URL",
+		Name:   "gerrit-server",
+		Usage:  "gerrit server address",
+	},
+	cli.StringFlag{
+		EnvVar: "DRONE_GERRIT_GIT_USERNAME",
+		Name:   "gerrit-git-username",
+		Usage:  "gerrit service account username",
+	},
+	cli.StringFlag{
+		EnvVar: "DRONE_GERRIT_GIT_PASSWORD",
+		Name:   "gerrit-git-password",
+		Usage:  "gerrit service account http password",
+	},
+	cli.StringFlag{
+		EnvVar: "DRONE_GERRIT_LABEL",
+		Name:   "gerrit-label",
+		Usage:  "gerrit label voted on with the build status",
+		Value:  "Verified",
+	},
+	cli.BoolFlag{
+		EnvVar: "DRONE_GERRIT_OAUTH",
+		Name:   "gerrit-oauth",
+		Usage:  "gerrit authenticates with oauth access tokens",
+	},
+	cli.BoolFlag{
+		EnvVar: "DRONE_GERRIT_PRIVATE_MODE",
+		Name:   "gerrit-private-mode",
+		Usage:  "gerrit private mode enabled",
+	},
+	cli.BoolFlag{
+		EnvVar: "DRONE_GERRIT_SKIP_VERIFY",
+		Name:   "gerrit-skip-verify",
+		Usage:  "gerrit skip ssl verification",
+	},
 	cli.BoolFlag{
 		EnvVar: "DRONE_GITEA",
 		Name:   "gitea",
@@ -536,6 +723,13 @@ func server(c *cli.Context) error {
 	store_ := setupStore(c)
 	setupEvilGlobals(c, store_, remote_)
 
+	dispatcher := &droneserver.WebhookDispatcher{
+		Store:   store_,
+		Pubsub:  localPubsub(droneserver.Config.Services.Pubsub),
+		Retries: c.Int("webhook-retries"),
+		Backoff: c.Duration("webhook-backoff"),
+	}
+
 	// we are switching from gin to httpservermux|treemux,
 	// so if this code looks strange, that is why.
 	tree := setupTree(c)
@@ -548,6 +742,7 @@ func server(c *cli.Context) error {
 		middleware.Config(c),
 		middleware.Store(c, store_),
 		middleware.Remote(remote_),
+		middleware.Webhooks(dispatcher),
 	)
 
 	var g errgroup.Group
@@ -562,6 +757,7 @@ func server(c *cli.Context) error {
 		}
 		auther := &authorizer{
 			password: c.String("agent-secret"),
+			store:    store_,
 		}
 		s := grpc.NewServer(
 			grpc.StreamInterceptor(auther.streamInterceptor),
@@ -577,6 +773,7 @@ func server(c *cli.Context) error {
 		ss.Remote = remote_
 		ss.Store = store_
 		ss.Host = droneserver.Config.Server.Host
+		ss.ProcStatus = c.Bool("status-pipelines")
 		proto.RegisterDroneServer(s, ss)
 
 		err = s.Serve(lis)
@@ -587,6 +784,29 @@ func server(c *cli.Context) error {
 		return nil
 	})
 
+	// start the cron scheduler
+	g.Go(func() error {
+		sched := &droneserver.Scheduler{
+			Remote:   remote_,
+			Store:    store_,
+			Interval: c.Duration("cron-interval"),
+		}
+		return sched.Start(context.Background())
+	})
+
+	g.Go(func() error {
+		pruner := &droneserver.Pruner{
+			Store:    store_,
+			Logs:     droneserver.Config.Storage.Logs,
+			Interval: c.Duration("retention-interval"),
+		}
+		return pruner.Start(context.Background())
+	})
+
+	g.Go(func() error {
+		return dispatcher.Start(context.Background())
+	})
+
 	// start the server with tls enabled
 	if c.String("server-cert") != "" {
 		g.Go(func() error {
@@ -659,11 +879,12 @@ func setupEvilGlobals(c *cli.Context, v store.Store, r remote.Remote) {
 	// storage
 	droneserver.Config.Storage.Files = v
 	droneserver.Config.Storage.Config = v
+	droneserver.Config.Storage.Logs = setupLogStore(c, v)
 
 	// services
 	droneserver.Config.Services.Queue = setupQueue(c, v)
-	droneserver.Config.Services.Logs = logging.New()
-	droneserver.Config.Services.Pubsub = pubsub.New()
+	droneserver.Config.Services.Logs = setupStream(c)
+	droneserver.Config.Services.Pubsub = setupPubsub(c)
 	droneserver.Config.Services.Pubsub.Create(context.Background(), "topic/events")
 	droneserver.Config.Services.Registries = setupRegistryService(c, v)
 	droneserver.Config.Services.Secrets = setupSecretService(c, v)
@@ -691,6 +912,7 @@ func setupEvilGlobals(c *cli.Context, v store.Store, r remote.Remote) {
 	droneserver.Config.Server.Port = c.String("server-addr")
 	droneserver.Config.Server.RepoConfig = c.String("repo-config")
 	droneserver.Config.Server.SessionExpires = c.Duration("session-expires")
+	droneserver.Config.Server.WebhookPrivate = c.Bool("webhook-allow-private")
 	droneserver.Config.Pipeline.Networks = c.StringSlice("network")
 	droneserver.Config.Pipeline.Volumes = c.StringSlice("volume")
 	droneserver.Config.Pipeline.Privileged = c.StringSlice("escalate")
@@ -705,30 +927,55 @@ func setupEvilGlobals(c *cli.Context, v store.Store, r remote.Remote) {
 type authorizer struct {
 	username string
 	password string
+	store    store.Store
 }
 
 func (a *authorizer) streamInterceptor(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
-	if err := a.authorize(stream.Context()); err != nil {
+	ctx, err := a.authorize(stream.Context())
+	if err != nil {
 		return err
 	}
-	return handler(srv, stream)
+	return handler(srv, &authorizedStream{stream, ctx})
 }
 
 func (a *authorizer) unaryIntercaptor(ctx oldcontext.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
-	if err := a.authorize(ctx); err != nil {
+	ctx, err = a.authorize(ctx)
+	if err != nil {
 		return nil, err
 	}
 	return handler(ctx, req)
 }
 
-func (a *authorizer) authorize(ctx context.Context) error {
+// authorize authorizes the agent using the shared secret or, as an
+// alternative, an individually issued agent token. The agent token is
+// added to the returned context so that its restrictions can be enforced.
+func (a *authorizer) authorize(ctx context.Context) (context.Context, error) {
 	if md, ok := metadata.FromContext(ctx); ok {
-		if len(md["password"]) > 0 && md["password"][0] == a.password {
-			return nil
+		if len(md["password"]) == 0 || md["password"][0] == "" {
+			return nil, errors.New("invalid agent token")
+		}
+		password := md["password"][0]
+		if a.password != "" && subtle.ConstantTimeCompare([]byte(password), []byte(a.password)) == 1 {
+			return ctx, nil
 		}
-		return errors.New("invalid agent token")
+		token, err := droneserver.FindAgentToken(a.store, model.HashAgentToken(password))
+		if err != nil {
+			return nil, errors.New("invalid agent token")
+		}
+		return droneserver.WithAgentToken(ctx, token), nil
 	}
-	return errors.New("missing agent token")
+	return nil, errors.New("missing agent token")
+}
+
+// authorizedStream wraps the server stream to provide the
+// authorized context.
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authorizedStream) Context() oldcontext.Context {
+	return s.ctx
 }
 
 func redirect(w http.ResponseWriter, req *http.Request) {
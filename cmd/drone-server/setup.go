@@ -15,18 +15,26 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"time"
 
+	"github.com/cncd/logging"
+	"github.com/cncd/pubsub"
 	"github.com/cncd/queue"
 	"github.com/dimfeld/httptreemux"
 	"github.com/drone/drone/model"
+	"github.com/drone/drone/plugins/environ"
+	"github.com/drone/drone/plugins/logs"
+	"github.com/drone/drone/plugins/redis"
 	"github.com/drone/drone/plugins/registry"
 	"github.com/drone/drone/plugins/secrets"
 	"github.com/drone/drone/remote"
 	"github.com/drone/drone/remote/bitbucket"
 	"github.com/drone/drone/remote/bitbucketserver"
 	"github.com/drone/drone/remote/coding"
+	"github.com/drone/drone/remote/gerrit"
 	"github.com/drone/drone/remote/gitea"
 	"github.com/drone/drone/remote/github"
 	"github.com/drone/drone/remote/gitlab"
@@ -36,6 +44,7 @@ import (
 	"github.com/drone/drone/store"
 	"github.com/drone/drone/store/datastore"
 
+	"github.com/Sirupsen/logrus"
 	"github.com/urfave/cli"
 )
 
@@ -46,8 +55,111 @@ func setupStore(c *cli.Context) store.Store {
 	)
 }
 
+func setupLogStore(c *cli.Context, s store.Store) model.LogStore {
+	var (
+		logStore model.LogStore
+		err      error
+	)
+	switch c.String("log-storage") {
+	case "", "database":
+		return s
+	case "filesystem":
+		logStore, err = logs.NewFilesystem(c.String("log-storage-path"))
+	case "s3":
+		logStore, err = logs.NewS3(logs.S3Config{
+			Endpoint:  c.String("log-s3-endpoint"),
+			Bucket:    c.String("log-s3-bucket"),
+			Prefix:    c.String("log-s3-prefix"),
+			Region:    c.String("log-s3-region"),
+			AccessKey: c.String("log-s3-access-key"),
+			SecretKey: c.String("log-s3-secret-key"),
+			PathStyle: c.Bool("log-s3-path-style"),
+		})
+	default:
+		err = fmt.Errorf("unknown driver %q", c.String("log-storage"))
+	}
+	if err != nil {
+		logrus.Fatalf("cannot setup log storage. %s", err)
+	}
+	return logStore
+}
+
+func setupPubsub(c *cli.Context) pubsub.Publisher {
+	switch c.String("pubsub-driver") {
+	case "", "memory":
+		return pubsub.New()
+	case "redis":
+		// events are also published to an in-memory publisher, so that
+		// services such as webhooks handle each event once, on the server
+		// where it originated.
+		return &localPublisher{
+			Publisher: redis.NewPublisher(redis.NewPool(c.String("redis-url"))),
+			local:     pubsub.New(),
+		}
+	default:
+		logrus.Fatalf("unknown pubsub driver %q", c.String("pubsub-driver"))
+		return nil
+	}
+}
+
+func setupStream(c *cli.Context) logging.Log {
+	switch c.String("logging-driver") {
+	case "", "memory":
+		return logging.New()
+	case "redis":
+		return redis.NewLog(redis.NewPool(c.String("redis-url")))
+	default:
+		logrus.Fatalf("unknown logging driver %q", c.String("logging-driver"))
+		return nil
+	}
+}
+
+// localPublisher publishes messages to the shared publisher and to an
+// in-memory publisher that only delivers to subscribers on this server.
+type localPublisher struct {
+	pubsub.Publisher
+	local pubsub.Publisher
+}
+
+func (p *localPublisher) Create(c context.Context, topic string) error {
+	p.local.Create(c, topic)
+	return p.Publisher.Create(c, topic)
+}
+
+func (p *localPublisher) Publish(c context.Context, topic string, message pubsub.Message) error {
+	p.local.Publish(c, topic, message)
+	return p.Publisher.Publish(c, topic, message)
+}
+
+// localPubsub returns the publisher that only delivers messages
+// published on this server.
+func localPubsub(p pubsub.Publisher) pubsub.Publisher {
+	if l, ok := p.(*localPublisher); ok {
+		return l.local
+	}
+	return p
+}
+
 func setupQueue(c *cli.Context, s store.Store) queue.Queue {
-	return model.WithTaskStore(queue.New(), s)
+	var q queue.Queue
+	if c.Bool("queue-database") {
+		q = model.NewTaskQueue(s)
+	} else {
+		q = model.WithTaskStore(queue.New(), s)
+	}
+	limits := model.QueueLimits{
+		Repo:  c.Int("limit-repo-pipelines"),
+		Owner: c.Int("limit-owner-pipelines"),
+	}
+	if limits.Repo != 0 || limits.Owner != 0 {
+		// running pipelines are counted per server, which cannot
+		// enforce the limits when multiple servers share the queue.
+		if c.Bool("queue-database") {
+			logrus.Fatalln("DRONE_LIMIT_REPO_PIPELINES and DRONE_LIMIT_OWNER_PIPELINES cannot be used with DRONE_QUEUE_DATABASE")
+		}
+		q = model.WithQueueLimits(q, limits)
+	}
+	return q
 }
 
 func setupSecretService(c *cli.Context, s store.Store) model.SecretService {
@@ -59,15 +171,25 @@ func setupRegistryService(c *cli.Context, s store.Store) model.RegistryService {
 }
 
 func setupEnvironService(c *cli.Context, s store.Store) model.EnvironService {
-	return nil
+	if endpoint := c.String("environ-service"); endpoint != "" {
+		return environ.NewRemote(endpoint)
+	}
+	var vars []*environ.Variable
+	if path := c.String("environ-file"); path != "" {
+		parsed, err := environ.ParseFile(path)
+		if err != nil {
+			logrus.Fatalf("cannot load environment file %s. %s", path, err)
+		}
+		vars = append(vars, parsed...)
+	}
+	vars = append(vars, environ.ParseEnviron(os.Environ())...)
+	return environ.New(vars)
 }
 
 func setupLimiter(c *cli.Context, s store.Store) model.Limiter {
 	return new(model.NoLimit)
 }
 
-func setupPubsub(c *cli.Context)        {}
-func setupStream(c *cli.Context)        {}
 func setupGatingService(c *cli.Context) {}
 
 // helper function to setup the remote from the CLI arguments.
@@ -87,6 +209,8 @@ func SetupRemote(c *cli.Context) (remote.Remote, error) {
 		return setupGitea(c)
 	case c.Bool("coding"):
 		return setupCoding(c)
+	case c.Bool("gerrit"):
+		return setupGerrit(c)
 	default:
 		return nil, fmt.Errorf("version control system not configured")
 	}
@@ -111,6 +235,19 @@ func setupGogs(c *cli.Context) (remote.Remote, error) {
 	})
 }
 
+// helper function to setup the Gerrit remote from the CLI arguments.
+func setupGerrit(c *cli.Context) (remote.Remote, error) {
+	return gerrit.New(gerrit.Opts{
+		URL:         c.String("gerrit-server"),
+		Username:    c.String("gerrit-git-username"),
+		Password:    c.String("gerrit-git-password"),
+		Label:       c.String("gerrit-label"),
+		OAuth:       c.Bool("gerrit-oauth"),
+		PrivateMode: c.Bool("gerrit-private-mode"),
+		SkipVerify:  c.Bool("gerrit-skip-verify"),
+	})
+}
+
 // helper function to setup the Gitea remote from the CLI arguments.
 func setupGitea(c *cli.Context) (remote.Remote, error) {
 	return gitea.New(gitea.Opts{
@@ -173,6 +310,11 @@ func setupGithub(c *cli.Context) (remote.Remote, error) {
 		PrivateMode: c.Bool("github-private-mode"),
 		SkipVerify:  c.Bool("github-skip-verify"),
 		MergeRef:    c.BoolT("github-merge-ref"),
+		Checks:      c.Bool("github-checks"),
+
+		AppID:        c.Int64("github-app-id"),
+		AppKey:       c.String("github-app-private-key"),
+		AppKeyString: c.String("github-app-private-key-string"),
 	})
 }
 
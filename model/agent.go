@@ -0,0 +1,46 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+// AgentStore persists agent information to storage.
+type AgentStore interface {
+	AgentFind(string) (*Agent, error)
+	AgentList() ([]*Agent, error)
+	AgentCreate(*Agent) error
+	AgentUpdate(*Agent) error
+	AgentCheckin(*Agent) error
+	AgentSeen(*Agent) error
+	AgentDelete(*Agent) error
+}
+
+// Agent represents an agent that polls the server for work.
+// swagger:model agent
+type Agent struct {
+	ID       int64             `json:"id"        meddler:"agent_id,pk"`
+	Hostname string            `json:"hostname"  meddler:"agent_addr"`
+	Platform string            `json:"platform"  meddler:"agent_platform"`
+	Capacity int               `json:"capacity"  meddler:"agent_capacity"`
+	Labels   map[string]string `json:"labels"    meddler:"agent_labels,json"`
+	Filter   string            `json:"filter"    meddler:"agent_filter"`
+	Version  string            `json:"version"   meddler:"agent_version"`
+	Draining bool              `json:"draining"  meddler:"agent_draining"`
+	Created  int64             `json:"created"   meddler:"agent_created"`
+	LastSeen int64             `json:"last_seen" meddler:"agent_updated"`
+}
+
+// AgentPatch represents an agent patch object.
+type AgentPatch struct {
+	Draining *bool `json:"draining,omitempty"`
+}
@@ -0,0 +1,87 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+var errAgentTokenNameInvalid = errors.New("Invalid Agent Token Name")
+
+// AgentTokenStore persists agent token information to storage.
+type AgentTokenStore interface {
+	AgentTokenFind(int64) (*AgentToken, error)
+	AgentTokenFindHash(string) (*AgentToken, error)
+	AgentTokenList() ([]*AgentToken, error)
+	AgentTokenCreate(*AgentToken) error
+	AgentTokenDelete(*AgentToken) error
+}
+
+// AgentToken represents an individually issued agent token. Agents that
+// authenticate with a token only receive work matching its restrictions.
+// swagger:model agentToken
+type AgentToken struct {
+	ID        int64             `json:"id"              meddler:"token_id,pk"`
+	Name      string            `json:"name"            meddler:"token_name"`
+	Hash      string            `json:"-"               meddler:"token_hash"`
+	Platforms []string          `json:"platforms"       meddler:"token_platforms,json"`
+	Labels    map[string]string `json:"labels"          meddler:"token_labels,json"`
+	Created   int64             `json:"created"         meddler:"token_created"`
+	Token     string            `json:"token,omitempty" meddler:"-"`
+}
+
+// HashAgentToken returns the hash of the token value that is persisted
+// to storage in place of the value.
+func HashAgentToken(token string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(token)))
+}
+
+// Match returns true if the task labels satisfy the platform and label
+// restrictions of the token.
+func (t *AgentToken) Match(labels map[string]string) bool {
+	if len(t.Platforms) != 0 {
+		var matched bool
+		for _, pattern := range t.Platforms {
+			if match, _ := filepath.Match(pattern, labels["platform"]); match {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	for k, v := range t.Labels {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate validates the required fields and formats.
+func (t *AgentToken) Validate() error {
+	switch {
+	case len(t.Name) == 0:
+		return errAgentTokenNameInvalid
+	case len(t.Name) > 250:
+		return errAgentTokenNameInvalid
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/franela/goblin"
+)
+
+func TestAgentToken(t *testing.T) {
+	g := goblin.Goblin(t)
+	g.Describe("Agent token", func() {
+		g.It("should match any task when unrestricted", func() {
+			token := AgentToken{}
+			g.Assert(token.Match(map[string]string{"platform": "linux/amd64"})).IsTrue()
+		})
+		g.It("should match platform patterns", func() {
+			token := AgentToken{Platforms: []string{"linux/*"}}
+			g.Assert(token.Match(map[string]string{"platform": "linux/arm64"})).IsTrue()
+			g.Assert(token.Match(map[string]string{"platform": "windows/amd64"})).IsFalse()
+		})
+		g.It("should match required labels", func() {
+			token := AgentToken{Labels: map[string]string{"repo": "octocat/hello-world"}}
+			g.Assert(token.Match(map[string]string{"repo": "octocat/hello-world"})).IsTrue()
+			g.Assert(token.Match(map[string]string{"repo": "octocat/spoon-knife"})).IsFalse()
+		})
+		g.It("should hash the token value", func() {
+			g.Assert(HashAgentToken("foo") == HashAgentToken("foo")).IsTrue()
+			g.Assert(HashAgentToken("foo") == "foo").IsFalse()
+		})
+		g.It("should require a name", func() {
+			token := AgentToken{}
+			g.Assert(token.Validate() != nil).IsTrue()
+		})
+	})
+}
@@ -0,0 +1,73 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// AnnotationMime is the mime type of uploaded files that contain line-level
+// annotations, such as linter warnings or compiler errors.
+const AnnotationMime = "application/vnd.drone.annotations+json"
+
+// Annotation levels.
+const (
+	AnnotationNotice  = "notice"
+	AnnotationWarning = "warning"
+	AnnotationFailure = "failure"
+)
+
+// Annotation represents a line-level warning or error reported by a
+// pipeline step.
+type Annotation struct {
+	Path    string `json:"path"`
+	Line    int    `json:"line"`
+	EndLine int    `json:"end_line,omitempty"`
+	Level   string `json:"level"`
+	Title   string `json:"title,omitempty"`
+	Message string `json:"message"`
+}
+
+// ParseAnnotations parses a list of annotations from the reader. Annotations
+// without a path or message are ignored, and missing or unknown levels
+// default to a warning.
+func ParseAnnotations(r io.Reader) ([]*Annotation, error) {
+	var in []*Annotation
+	if err := json.NewDecoder(r).Decode(&in); err != nil {
+		return nil, err
+	}
+	var out []*Annotation
+	for _, a := range in {
+		if a == nil || a.Path == "" || a.Message == "" {
+			continue
+		}
+		if a.Line < 1 {
+			a.Line = 1
+		}
+		if a.EndLine < a.Line {
+			a.EndLine = a.Line
+		}
+		switch a.Level {
+		case AnnotationNotice, AnnotationWarning, AnnotationFailure:
+		case "error":
+			a.Level = AnnotationFailure
+		default:
+			a.Level = AnnotationWarning
+		}
+		out = append(out, a)
+	}
+	return out, nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseAnnotations(t *testing.T) {
+	data := `[
+		{"path": "main.go", "line": 12, "level": "error", "message": "undefined: foo"},
+		{"path": "main.go", "line": 3, "end_line": 5, "level": "notice", "message": "exported function should have comment"},
+		{"path": "util.go", "message": "missing level"},
+		{"path": "util.go", "line": 4}
+	]`
+	annotations, err := ParseAnnotations(strings.NewReader(data))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(annotations), 3; got != want {
+		t.Errorf("Want %d annotations, got %d", want, got)
+		return
+	}
+	if got, want := annotations[0].Level, AnnotationFailure; got != want {
+		t.Errorf("Want level %s, got %s", want, got)
+	}
+	if got, want := annotations[0].EndLine, 12; got != want {
+		t.Errorf("Want end line %d, got %d", want, got)
+	}
+	if got, want := annotations[1].EndLine, 5; got != want {
+		t.Errorf("Want end line %d, got %d", want, got)
+	}
+	if got, want := annotations[2].Level, AnnotationWarning; got != want {
+		t.Errorf("Want level %s, got %s", want, got)
+	}
+	if got, want := annotations[2].Line, 1; got != want {
+		t.Errorf("Want line %d, got %d", want, got)
+	}
+
+	if _, err := ParseAnnotations(strings.NewReader("{")); err == nil {
+		t.Errorf("Want error parsing malformed annotations")
+	}
+}
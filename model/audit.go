@@ -0,0 +1,114 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// AuditRedacted replaces the value of sensitive fields in the audit diff.
+const AuditRedacted = "[redacted]"
+
+// auditRedactedFields lists the json fields holding sensitive values,
+// such as secret values, registry passwords and tokens.
+var auditRedactedFields = map[string]bool{
+	"value":    true,
+	"password": true,
+	"token":    true,
+	"secret":   true,
+}
+
+// AuditStore persists audit events to storage.
+type AuditStore interface {
+	AuditCreate(*AuditEvent) error
+	AuditList(*AuditFilter) ([]*AuditEvent, error)
+}
+
+// AuditEvent represents an administrative or security-sensitive action
+// performed by a user.
+// swagger:model auditEvent
+type AuditEvent struct {
+	ID      int64                   `json:"id"             meddler:"audit_id,pk"`
+	Actor   string                  `json:"actor"          meddler:"audit_actor"`
+	Action  string                  `json:"action"         meddler:"audit_action"`
+	Repo    string                  `json:"repo,omitempty" meddler:"audit_repo"`
+	Target  string                  `json:"target"         meddler:"audit_target"`
+	IP      string                  `json:"ip"             meddler:"audit_ip"`
+	Diff    map[string]*AuditChange `json:"diff,omitempty" meddler:"audit_diff,json"`
+	Created int64                   `json:"created"        meddler:"audit_created"`
+}
+
+// AuditChange represents the before and after value of a changed field.
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditFilter defines the filters applied when listing audit events.
+// Empty filters match all events.
+type AuditFilter struct {
+	Actor  string
+	Repo   string
+	Action string
+	Limit  int
+	Offset int
+}
+
+// AuditDiff returns the fields that differ between the json encoding of
+// the before and after values. Either value may be nil when an object is
+// created or deleted. Sensitive values are redacted.
+func AuditDiff(before, after interface{}) map[string]*AuditChange {
+	a, b := auditFields(before), auditFields(after)
+	diff := map[string]*AuditChange{}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || !reflect.DeepEqual(v, w) {
+			diff[k] = &AuditChange{Before: v, After: w}
+		}
+	}
+	for k, w := range b {
+		if _, ok := a[k]; !ok {
+			diff[k] = &AuditChange{After: w}
+		}
+	}
+	for k, change := range diff {
+		if auditRedactedFields[k] {
+			change.Before = auditRedact(change.Before)
+			change.After = auditRedact(change.After)
+		}
+	}
+	return diff
+}
+
+// auditFields returns the json fields of the value.
+func auditFields(v interface{}) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if v == nil || reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil() {
+		return fields
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fields
+	}
+	json.Unmarshal(data, &fields)
+	return fields
+}
+
+func auditRedact(v interface{}) interface{} {
+	if v == nil || v == "" {
+		return v
+	}
+	return AuditRedacted
+}
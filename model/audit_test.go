@@ -0,0 +1,57 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/franela/goblin"
+)
+
+func TestAudit(t *testing.T) {
+	g := goblin.Goblin(t)
+	g.Describe("Audit", func() {
+		g.It("should diff changed fields", func() {
+			before := &Repo{ID: 1, FullName: "octocat/hello-world", IsTrusted: false}
+			after := &Repo{ID: 1, FullName: "octocat/hello-world", IsTrusted: true}
+			diff := AuditDiff(before, after)
+			g.Assert(len(diff)).Equal(1)
+			g.Assert(diff["trusted"].Before).Equal(false)
+			g.Assert(diff["trusted"].After).Equal(true)
+		})
+		g.It("should diff created objects", func() {
+			diff := AuditDiff(nil, &User{Login: "octocat"})
+			g.Assert(diff["login"].Before == nil).IsTrue()
+			g.Assert(diff["login"].After).Equal("octocat")
+		})
+		g.It("should diff deleted objects", func() {
+			var user *User
+			diff := AuditDiff(&User{Login: "octocat"}, user)
+			g.Assert(diff["login"].Before).Equal("octocat")
+			g.Assert(diff["login"].After == nil).IsTrue()
+		})
+		g.It("should redact sensitive values", func() {
+			before := &Secret{Name: "password", Value: "correct-horse"}
+			after := &Secret{Name: "password", Value: "battery-staple"}
+			diff := AuditDiff(before, after)
+			g.Assert(diff["value"].Before).Equal(AuditRedacted)
+			g.Assert(diff["value"].After).Equal(AuditRedacted)
+		})
+		g.It("should not report unchanged sensitive values", func() {
+			secret := &Secret{Name: "password", Value: "correct-horse"}
+			g.Assert(len(AuditDiff(secret, secret))).Equal(0)
+		})
+	})
+}
@@ -46,6 +46,7 @@ type Build struct {
 	Verified  bool    `json:"verified"      meddler:"build_verified"` // deprecate
 	Reviewer  string  `json:"reviewed_by"   meddler:"build_reviewer"`
 	Reviewed  int64   `json:"reviewed_at"   meddler:"build_reviewed"`
+	Pruned    bool    `json:"pruned"        meddler:"build_pruned"`
 	Procs     []*Proc `json:"procs,omitempty" meddler:"-"`
 	Files     []*File `json:"files,omitempty" meddler:"-"`
 }
@@ -60,3 +61,20 @@ func (b *Build) Trim() {
 		b.Message = b.Message[:2000]
 	}
 }
+
+// BuildFilter defines the filters applied when listing builds. Empty
+// filters match all builds. Builds are listed in descending order and
+// paginated by cursor, where the cursor is the number of the last build
+// of the previous page, or its id when searching all repositories.
+type BuildFilter struct {
+	Repo   string // repository full name glob pattern, e.g. octocat/*
+	Branch string // branch glob pattern, e.g. release/*
+	Event  string
+	Status string
+	Author string
+	Commit string // commit sha prefix
+	After  int64  // created at or after, in unix seconds
+	Before int64  // created before, in unix seconds
+	Cursor int64
+	Limit  int
+}
@@ -0,0 +1,83 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/robfig/cron"
+)
+
+var (
+	errCronNameInvalid = errors.New("Invalid Cron Name")
+	errCronExprInvalid = errors.New("Invalid Cron Expression")
+)
+
+// CronStore persists cron information to storage.
+type CronStore interface {
+	CronFind(*Repo, string) (*Cron, error)
+	CronList(*Repo) ([]*Cron, error)
+	CronListReady(int64) ([]*Cron, error)
+	CronCreate(*Cron) error
+	CronUpdate(*Cron) error
+	CronClaim(*Cron, int64) (bool, error)
+	CronDelete(*Cron) error
+}
+
+// Cron represents a scheduled build for a repository branch.
+type Cron struct {
+	ID       int64  `json:"id"       meddler:"cron_id,pk"`
+	RepoID   int64  `json:"-"        meddler:"cron_repo_id"`
+	Name     string `json:"name"     meddler:"cron_name"`
+	Expr     string `json:"expr"     meddler:"cron_expr"`
+	Branch   string `json:"branch"   meddler:"cron_branch"`
+	Next     int64  `json:"next"     meddler:"cron_next"`
+	Prev     int64  `json:"prev"     meddler:"cron_prev"`
+	Disabled bool   `json:"disabled" meddler:"cron_disabled"`
+	Created  int64  `json:"created"  meddler:"cron_created"`
+	Updated  int64  `json:"updated"  meddler:"cron_updated"`
+}
+
+// CronPatch represents a cron job patch object.
+type CronPatch struct {
+	Expr     *string `json:"expr,omitempty"`
+	Branch   *string `json:"branch,omitempty"`
+	Disabled *bool   `json:"disabled,omitempty"`
+}
+
+// Validate validates the required fields and formats.
+func (c *Cron) Validate() error {
+	switch {
+	case len(c.Name) == 0:
+		return errCronNameInvalid
+	case len(c.Name) > 250:
+		return errCronNameInvalid
+	}
+	if _, err := cron.ParseStandard(c.Expr); err != nil {
+		return errCronExprInvalid
+	}
+	return nil
+}
+
+// Update calculates the next execution time after the given time.
+// The cron expression must be validated before calling this method.
+func (c *Cron) Update(now time.Time) {
+	sched, err := cron.ParseStandard(c.Expr)
+	if err != nil {
+		return
+	}
+	c.Next = sched.Next(now).Unix()
+}
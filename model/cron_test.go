@@ -0,0 +1,57 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/franela/goblin"
+)
+
+func TestCron(t *testing.T) {
+
+	g := goblin.Goblin(t)
+	g.Describe("Cron", func() {
+
+		g.It("should pass validation", func() {
+			cron := Cron{}
+			cron.Name = "nightly"
+			cron.Expr = "@daily"
+			g.Assert(cron.Validate()).Equal(nil)
+		})
+		g.Describe("should fail validation", func() {
+			g.It("when no name", func() {
+				cron := Cron{}
+				cron.Expr = "@daily"
+				g.Assert(cron.Validate() != nil).IsTrue()
+			})
+			g.It("when invalid expression", func() {
+				cron := Cron{}
+				cron.Name = "nightly"
+				cron.Expr = "* * *"
+				g.Assert(cron.Validate() != nil).IsTrue()
+			})
+		})
+		g.It("should calculate next execution", func() {
+			now := time.Date(2018, time.January, 1, 10, 30, 0, 0, time.UTC)
+			cron := Cron{}
+			cron.Expr = "0 12 * * *"
+			cron.Update(now)
+			want := time.Date(2018, time.January, 1, 12, 0, 0, 0, time.UTC)
+			g.Assert(cron.Next).Equal(want.Unix())
+		})
+	})
+}
@@ -0,0 +1,94 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+// DeploymentStore persists deployment history to storage.
+type DeploymentStore interface {
+	DeploymentFindBuild(*Build) (*Deployment, error)
+	DeploymentList(*Repo, string) ([]*Deployment, error)
+	DeploymentCreate(*Deployment) error
+	DeploymentUpdate(*Deployment) error
+}
+
+// Deployment represents the deployment of a build to a deployment
+// target, created by a promotion, a rollback or a deployment event.
+// Number is the deployment build and Source is the deployed build.
+// swagger:model deployment
+type Deployment struct {
+	ID       int64  `json:"id"       meddler:"deployment_id,pk"`
+	RepoID   int64  `json:"-"        meddler:"deployment_repo_id"`
+	BuildID  int64  `json:"-"        meddler:"deployment_build_id"`
+	Number   int    `json:"number"   meddler:"deployment_number"`
+	Source   int    `json:"source"   meddler:"deployment_source"`
+	Target   string `json:"target"   meddler:"deployment_target"`
+	Commit   string `json:"commit"   meddler:"deployment_commit"`
+	Status   string `json:"status"   meddler:"deployment_status"`
+	Rollback bool   `json:"rollback" meddler:"deployment_rollback"`
+	Creator  string `json:"creator"  meddler:"deployment_creator"`
+	Created  int64  `json:"created"  meddler:"deployment_created"`
+	Updated  int64  `json:"updated"  meddler:"deployment_updated"`
+}
+
+// DeploymentLive returns the deployment that is currently live, which
+// is the most recent successful deployment. The deployment history
+// must be sorted from newest to oldest.
+func DeploymentLive(history []*Deployment) *Deployment {
+	for _, deployment := range history {
+		if deployment.Status == StatusSuccess {
+			return deployment
+		}
+	}
+	return nil
+}
+
+// DeploymentLatest returns the deployment of the newest build that is
+// live or being deployed, which is the live deployment or a pending or
+// running deployment created after it. The deployment history must be
+// sorted from newest to oldest.
+func DeploymentLatest(history []*Deployment) *Deployment {
+	var latest *Deployment
+	for _, deployment := range history {
+		switch deployment.Status {
+		case StatusPending, StatusRunning, StatusSuccess:
+		default:
+			continue
+		}
+		if latest == nil || deployment.Source > latest.Source {
+			latest = deployment
+		}
+		if deployment.Status == StatusSuccess {
+			break
+		}
+	}
+	return latest
+}
+
+// DeploymentPrevious returns the most recent successful deployment of
+// a build other than the live build. The deployment history must be
+// sorted from newest to oldest.
+func DeploymentPrevious(history []*Deployment) *Deployment {
+	live := DeploymentLive(history)
+	if live == nil {
+		return nil
+	}
+	for _, deployment := range history {
+		if deployment.ID < live.ID &&
+			deployment.Status == StatusSuccess &&
+			deployment.Source != live.Source {
+			return deployment
+		}
+	}
+	return nil
+}
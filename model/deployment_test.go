@@ -0,0 +1,56 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/franela/goblin"
+)
+
+func TestDeployment(t *testing.T) {
+	g := goblin.Goblin(t)
+	g.Describe("Deployment history", func() {
+		history := []*Deployment{
+			{ID: 5, Source: 9, Status: StatusFailure},
+			{ID: 4, Source: 8, Status: StatusSuccess},
+			{ID: 3, Source: 8, Status: StatusSuccess},
+			{ID: 2, Source: 7, Status: StatusKilled},
+			{ID: 1, Source: 6, Status: StatusSuccess},
+		}
+		g.It("should return the live deployment", func() {
+			g.Assert(DeploymentLive(history).ID).Equal(int64(4))
+		})
+		g.It("should return the previous deployment", func() {
+			g.Assert(DeploymentPrevious(history).ID).Equal(int64(1))
+		})
+		g.It("should return the latest deployment", func() {
+			g.Assert(DeploymentLatest(history).ID).Equal(int64(4))
+			inflight := append([]*Deployment{
+				{ID: 7, Source: 10, Status: StatusPending},
+				{ID: 6, Source: 11, Status: StatusRunning},
+			}, history...)
+			g.Assert(DeploymentLatest(inflight).ID).Equal(int64(6))
+		})
+		g.It("should return nil without successful deployments", func() {
+			g.Assert(DeploymentLive(history[:1]) == nil).IsTrue()
+			g.Assert(DeploymentPrevious(history[:1]) == nil).IsTrue()
+			g.Assert(DeploymentLatest(history[:1]) == nil).IsTrue()
+		})
+		g.It("should return nil without a previous build", func() {
+			g.Assert(DeploymentPrevious(history[:3]) == nil).IsTrue()
+		})
+	})
+}
@@ -22,6 +22,7 @@ type Feed struct {
 	Name     string `json:"name"          meddler:"repo_name"`
 	FullName string `json:"full_name"     meddler:"repo_full_name"`
 
+	ID       int64  `json:"id,omitempty"            meddler:"build_id,zeroisnull"`
 	Number   int    `json:"number,omitempty"        meddler:"build_number,zeroisnull"`
 	Event    string `json:"event,omitempty"         meddler:"build_event,zeroisnull"`
 	Status   string `json:"status,omitempty"        meddler:"build_status,zeroisnull"`
@@ -14,6 +14,12 @@
 
 package model
 
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
 // ProcStore persists process information to storage.
 type ProcStore interface {
 	ProcLoad(int64) (*Proc, error)
@@ -55,6 +61,21 @@ func (p *Proc) Failing() bool {
 	return p.State == StatusError || p.State == StatusKilled || p.State == StatusFailure
 }
 
+// Axis returns a label identifying the matrix axis of the process, derived
+// from its environment (e.g. GO_VERSION=1.9 REDIS=3.2). If the process has
+// no environment the label falls back to the process identifier.
+func (p *Proc) Axis() string {
+	if len(p.Environ) == 0 {
+		return strconv.Itoa(p.PID)
+	}
+	var parts []string
+	for k, v := range p.Environ {
+		parts = append(parts, k+"="+v)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, " ")
+}
+
 // Tree creates a process tree from a flat process list.
 func Tree(procs []*Proc) []*Proc {
 	var (
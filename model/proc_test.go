@@ -0,0 +1,35 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import "testing"
+
+func TestProcAxis(t *testing.T) {
+	proc := Proc{
+		PID: 2,
+		Environ: map[string]string{
+			"REDIS_VERSION": "3.2",
+			"GO_VERSION":    "1.9",
+		},
+	}
+	if got, want := proc.Axis(), "GO_VERSION=1.9 REDIS_VERSION=3.2"; got != want {
+		t.Errorf("Want axis %q, got %q", want, got)
+	}
+
+	proc.Environ = nil
+	if got, want := proc.Axis(), "2"; got != want {
+		t.Errorf("Want axis %q, got %q", want, got)
+	}
+}
@@ -16,16 +16,29 @@ package model
 
 import (
 	"context"
+	"time"
 
 	"github.com/Sirupsen/logrus"
 	"github.com/cncd/queue"
 )
 
+// Task status values persisted to storage.
+const (
+	TaskPending = "pending"
+	TaskRunning = "running"
+	TaskDone    = "done"
+)
+
 // Task defines scheduled pipeline Task.
 type Task struct {
-	ID     string            `meddler:"task_id"`
-	Data   []byte            `meddler:"task_data"`
-	Labels map[string]string `meddler:"task_labels,json"`
+	ID       string            `meddler:"task_id"`
+	Data     []byte            `meddler:"task_data"`
+	Labels   map[string]string `meddler:"task_labels,json"`
+	Status   string            `meddler:"task_status"`
+	Error    string            `meddler:"task_error"`
+	Deadline int64             `meddler:"task_deadline"`
+	Created  int64             `meddler:"task_created"`
+	Updated  int64             `meddler:"task_updated"`
 }
 
 // TaskStore defines storage for scheduled Tasks.
@@ -35,6 +48,42 @@ type TaskStore interface {
 	TaskDelete(string) error
 }
 
+// TaskQueueStore defines storage for Tasks that are dispatched directly
+// from the database, see NewTaskQueue.
+type TaskQueueStore interface {
+	TaskStore
+
+	// TaskFind returns the task with the given id.
+	TaskFind(string) (*Task, error)
+
+	// TaskListStatus returns tasks with the given status, oldest first.
+	TaskListStatus(string) ([]*Task, error)
+
+	// TaskListReady returns pending tasks and running tasks with a
+	// deadline before the given time, oldest first.
+	TaskListReady(int64) ([]*Task, error)
+
+	// TaskClaim marks the task running with the given deadline, provided
+	// its status and deadline are unchanged since it was read. It returns
+	// false if the task was claimed by another process.
+	TaskClaim(*Task, int64) (bool, error)
+
+	// TaskExtend updates the deadline of the running task. It returns
+	// false if the task is not running.
+	TaskExtend(string, int64) (bool, error)
+
+	// TaskComplete marks the running task done with the given error
+	// message. It returns false if the task is not running.
+	TaskComplete(string, string) (bool, error)
+
+	// TaskEvict deletes the pending task. It returns false if the task
+	// is not pending.
+	TaskEvict(string) (bool, error)
+
+	// TaskPurge deletes done tasks last updated before the given time.
+	TaskPurge(int64) error
+}
+
 // WithTaskStore returns a queue that is backed by the TaskStore. This
 // ensures the task Queue can be restored when the system starts.
 func WithTaskStore(q queue.Queue, s TaskStore) queue.Queue {
@@ -56,12 +105,17 @@ type persistentQueue struct {
 
 // Push pushes an task to the tail of this queue.
 func (q *persistentQueue) Push(c context.Context, task *queue.Task) error {
-	q.store.TaskInsert(&Task{
-		ID:     task.ID,
-		Data:   task.Data,
-		Labels: task.Labels,
+	err := q.store.TaskInsert(&Task{
+		ID:      task.ID,
+		Data:    task.Data,
+		Labels:  task.Labels,
+		Status:  TaskPending,
+		Created: time.Now().Unix(),
 	})
-	err := q.Queue.Push(c, task)
+	if err != nil {
+		return err
+	}
+	err = q.Queue.Push(c, task)
 	if err != nil {
 		q.store.TaskDelete(task.ID)
 	}
@@ -0,0 +1,226 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cncd/queue"
+)
+
+// QueueLimits defines the maximum number of concurrently running
+// pipelines per repository and per repository owner. A zero value
+// disables the limit.
+type QueueLimits struct {
+	Repo  int
+	Owner int
+}
+
+// Blocked returns the reason the task cannot run while the given tasks
+// are running, or an empty string if the task is within the limits.
+// Tasks are attributed to a repository by the repo label.
+func (l QueueLimits) Blocked(task *queue.Task, running []*queue.Task) string {
+	repo := task.Labels["repo"]
+	if repo == "" {
+		return ""
+	}
+	owner := repoOwner(repo)
+
+	var repoCount, ownerCount int
+	for _, other := range running {
+		name := other.Labels["repo"]
+		if name == repo {
+			repoCount++
+		}
+		if name != "" && repoOwner(name) == owner {
+			ownerCount++
+		}
+	}
+	switch {
+	case l.Repo != 0 && repoCount >= l.Repo:
+		return fmt.Sprintf("repository %s has %d running pipelines, limit %d", repo, repoCount, l.Repo)
+	case l.Owner != 0 && ownerCount >= l.Owner:
+		return fmt.Sprintf("owner %s has %d running pipelines, limit %d", owner, ownerCount, l.Owner)
+	default:
+		return ""
+	}
+}
+
+func repoOwner(repo string) string {
+	if i := strings.Index(repo, "/"); i != -1 {
+		return repo[:i]
+	}
+	return repo
+}
+
+// QueueLimiter is implemented by a queue that keeps tasks pending while
+// they exceed the concurrency limits, see WithQueueLimits.
+type QueueLimiter interface {
+	// Blocked returns the reason each pending task exceeds the
+	// concurrency limits, keyed by task id.
+	Blocked(queue.InfoT) map[string]string
+}
+
+// WithQueueLimits returns a queue that only dispatches tasks that are
+// within the concurrency limits. Tasks over the limits remain pending
+// until running tasks of the same repository or owner are complete.
+// Running tasks are counted on this server only, therefore the queue
+// must not be shared by multiple servers.
+func WithQueueLimits(q queue.Queue, limits QueueLimits) queue.Queue {
+	return &limitedQueue{
+		Queue:    q,
+		limits:   limits,
+		interval: time.Second * 10,
+		running:  map[string]*queue.Task{},
+	}
+}
+
+type limitedQueue struct {
+	queue.Queue
+	limits QueueLimits
+
+	// interval at which the running tasks are refreshed while workers
+	// are polling, to release slots of tasks that are no longer running
+	// in the underlying queue, such as tasks with an expired lease.
+	interval time.Duration
+
+	sync.Mutex
+	running   map[string]*queue.Task
+	refreshed time.Time
+	pollers   int
+	looping   bool
+}
+
+// Poll retrieves and removes a task head of this queue that matches the
+// filter and is within the concurrency limits.
+func (q *limitedQueue) Poll(c context.Context, f queue.Filter) (*queue.Task, error) {
+	q.Lock()
+	q.pollers++
+	if !q.looping {
+		q.looping = true
+		go q.loop()
+	}
+	q.Unlock()
+
+	defer func() {
+		q.Lock()
+		q.pollers--
+		q.Unlock()
+	}()
+
+	return q.Queue.Poll(c, func(task *queue.Task) bool {
+		return f(task) && q.reserve(task)
+	})
+}
+
+// Done signals the task is complete.
+func (q *limitedQueue) Done(c context.Context, id string) error {
+	return q.Error(c, id, nil)
+}
+
+// Error signals the task is complete with errors.
+func (q *limitedQueue) Error(c context.Context, id string, err error) error {
+	err = q.Queue.Error(c, id, err)
+	q.refresh(c)
+	q.kick(c)
+	return err
+}
+
+// Blocked returns the reason each pending task exceeds the concurrency
+// limits, keyed by task id.
+func (q *limitedQueue) Blocked(info queue.InfoT) map[string]string {
+	blocked := map[string]string{}
+	for _, task := range info.Pending {
+		if reason := q.limits.Blocked(task, info.Running); reason != "" {
+			blocked[task.ID] = reason
+		}
+	}
+	return blocked
+}
+
+// helper function that reserves a slot for the task if it is within the
+// concurrency limits. It is invoked by the underlying queue once a task
+// matches the worker filter, which dispatches the task when true.
+func (q *limitedQueue) reserve(task *queue.Task) bool {
+	q.Lock()
+	defer q.Unlock()
+
+	if _, ok := q.running[task.ID]; ok {
+		return true
+	}
+	running := make([]*queue.Task, 0, len(q.running))
+	for _, other := range q.running {
+		running = append(running, other)
+	}
+	if q.limits.Blocked(task, running) != "" {
+		return false
+	}
+	q.running[task.ID] = task
+	return true
+}
+
+// helper function that replaces the reserved tasks with the tasks that
+// are running in the underlying queue. This releases tasks that are
+// complete, including tasks returned to the queue when the lease expired.
+// It must not be invoked from within the filter since the underlying
+// queue may hold a lock while filtering.
+func (q *limitedQueue) refresh(c context.Context) {
+	info := q.Queue.Info(c)
+	running := map[string]*queue.Task{}
+	for _, task := range info.Running {
+		running[task.ID] = task
+	}
+	q.Lock()
+	q.running = running
+	q.refreshed = time.Now()
+	q.Unlock()
+}
+
+// helper function that refreshes the running tasks and re-offers the
+// pending tasks to the polling workers at the interval. A single loop is
+// shared by all polling workers and exits once no worker is polling.
+func (q *limitedQueue) loop() {
+	ticker := time.NewTicker(q.interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		q.Lock()
+		if q.pollers == 0 {
+			q.looping = false
+			q.Unlock()
+			return
+		}
+		due := time.Since(q.refreshed) >= q.interval
+		q.Unlock()
+
+		if due {
+			q.refresh(context.Background())
+			q.kick(context.Background())
+		}
+	}
+}
+
+// helper function that re-offers the pending tasks to the polling
+// workers once slots are released. The underlying queue only dispatches
+// tasks when a task is pushed or a worker polls, so the dispatch is
+// triggered with a poll that matches no task and returns immediately.
+func (q *limitedQueue) kick(c context.Context) {
+	ctx, cancel := context.WithCancel(c)
+	cancel()
+	q.Queue.Poll(ctx, func(*queue.Task) bool { return false })
+}
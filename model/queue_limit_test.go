@@ -0,0 +1,150 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cncd/queue"
+	"github.com/franela/goblin"
+)
+
+func TestQueueLimits(t *testing.T) {
+	g := goblin.Goblin(t)
+	g.Describe("Queue limits", func() {
+		task := func(id, repo string) *queue.Task {
+			return &queue.Task{ID: id, Labels: map[string]string{"repo": repo}}
+		}
+		match := func(*queue.Task) bool { return true }
+		poll := func(q queue.Queue) *queue.Task {
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			task, _ := q.Poll(ctx, match)
+			return task
+		}
+
+		g.It("should block tasks over the repository limit", func() {
+			limits := QueueLimits{Repo: 1}
+			running := []*queue.Task{task("1", "octocat/hello-world")}
+			g.Assert(limits.Blocked(task("2", "octocat/hello-world"), running)).Equal("repository octocat/hello-world has 1 running pipelines, limit 1")
+			g.Assert(limits.Blocked(task("3", "octocat/spoon-knife"), running)).Equal("")
+		})
+		g.It("should block tasks over the owner limit", func() {
+			limits := QueueLimits{Owner: 2}
+			running := []*queue.Task{task("1", "octocat/hello-world"), task("2", "octocat/spoon-knife")}
+			g.Assert(limits.Blocked(task("3", "octocat/linguist"), running)).Equal("owner octocat has 2 running pipelines, limit 2")
+			g.Assert(limits.Blocked(task("4", "github/linguist"), running)).Equal("")
+		})
+		g.It("should not block tasks without a repository", func() {
+			limits := QueueLimits{Repo: 1, Owner: 1}
+			running := []*queue.Task{task("1", "octocat/hello-world")}
+			g.Assert(limits.Blocked(&queue.Task{ID: "2"}, running)).Equal("")
+		})
+		g.It("should keep tasks over the limit pending", func() {
+			c := context.Background()
+			q := WithQueueLimits(queue.New(), QueueLimits{Repo: 1})
+			q.Push(c, task("1", "octocat/hello-world"))
+			q.Push(c, task("2", "octocat/hello-world"))
+			q.Push(c, task("3", "octocat/spoon-knife"))
+
+			g.Assert(poll(q).ID).Equal("1")
+			g.Assert(poll(q).ID).Equal("3")
+			g.Assert(poll(q) == nil).IsTrue()
+
+			blocked := q.(QueueLimiter).Blocked(q.Info(c))
+			g.Assert(blocked["2"]).Equal("repository octocat/hello-world has 1 running pipelines, limit 1")
+
+			q.Done(c, "1")
+			g.Assert(poll(q).ID).Equal("2")
+		})
+		g.It("should dispatch held back tasks to waiting workers", func() {
+			c := context.Background()
+			q := WithQueueLimits(queue.New(), QueueLimits{Repo: 1})
+			q.Push(c, task("1", "octocat/hello-world"))
+			q.Push(c, task("2", "octocat/hello-world"))
+			g.Assert(poll(q).ID).Equal("1")
+
+			polled := make(chan *queue.Task)
+			go func() {
+				ctx, cancel := context.WithTimeout(c, time.Second)
+				defer cancel()
+				task, _ := q.Poll(ctx, match)
+				polled <- task
+			}()
+
+			// wait for the worker to block on the held back task.
+			time.Sleep(50 * time.Millisecond)
+			q.Done(c, "1")
+
+			task := <-polled
+			g.Assert(task != nil).IsTrue()
+			g.Assert(task.ID).Equal("2")
+		})
+		g.It("should release slots of tasks completed in the underlying queue", func() {
+			c := context.Background()
+			fifo := queue.New()
+			q := WithQueueLimits(fifo, QueueLimits{Repo: 1})
+			q.(*limitedQueue).interval = 20 * time.Millisecond
+			q.Push(c, task("1", "octocat/hello-world"))
+			q.Push(c, task("2", "octocat/hello-world"))
+			g.Assert(poll(q).ID).Equal("1")
+
+			polled := make(chan *queue.Task)
+			go func() {
+				ctx, cancel := context.WithTimeout(c, time.Second)
+				defer cancel()
+				task, _ := q.Poll(ctx, match)
+				polled <- task
+			}()
+
+			// complete the task in the underlying queue, as the queue
+			// does when the lease of the task expires.
+			time.Sleep(50 * time.Millisecond)
+			fifo.Done(c, "1")
+
+			task := <-polled
+			g.Assert(task != nil).IsTrue()
+			g.Assert(task.ID).Equal("2")
+		})
+		g.It("should share the refresh loop between workers", func() {
+			c := context.Background()
+			q := WithQueueLimits(queue.New(), QueueLimits{Repo: 1}).(*limitedQueue)
+			q.interval = 20 * time.Millisecond
+
+			done := make(chan struct{})
+			for i := 0; i < 3; i++ {
+				go func() {
+					ctx, cancel := context.WithTimeout(c, 100*time.Millisecond)
+					defer cancel()
+					q.Poll(ctx, match)
+					done <- struct{}{}
+				}()
+			}
+			for i := 0; i < 3; i++ {
+				<-done
+			}
+			g.Assert(q.pollers).Equal(0)
+
+			// the loop exits on the next tick once no worker is polling.
+			time.Sleep(50 * time.Millisecond)
+			q.Lock()
+			looping := q.looping
+			q.Unlock()
+			g.Assert(looping).IsFalse()
+		})
+	})
+}
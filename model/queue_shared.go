@@ -0,0 +1,272 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/cncd/queue"
+)
+
+// NewTaskQueue returns a queue that keeps pending and running tasks in
+// the TaskQueueStore instead of in memory. Multiple servers that share a
+// database can use this queue to dispatch work without dispatching the
+// same task twice.
+func NewTaskQueue(s TaskQueueStore) queue.Queue {
+	return &sharedQueue{
+		store:     s,
+		signal:    make(chan struct{}),
+		interval:  time.Second,
+		extension: time.Minute * 10,
+		retention: time.Hour,
+	}
+}
+
+type sharedQueue struct {
+	sync.Mutex
+
+	store  TaskQueueStore
+	signal chan struct{}
+
+	// number of workers polling this server.
+	workers int32
+
+	// interval at which the database is polled for changes
+	// made by other servers.
+	interval time.Duration
+
+	// extension is the duration a running task is leased
+	// to a worker before it is returned to the queue.
+	extension time.Duration
+
+	// retention is the duration a completed task is retained
+	// so that waiters on other servers can observe the result.
+	retention time.Duration
+	purged    time.Time
+}
+
+// Push pushes an task to the tail of this queue.
+func (q *sharedQueue) Push(c context.Context, task *queue.Task) error {
+	err := q.store.TaskInsert(&Task{
+		ID:      task.ID,
+		Data:    task.Data,
+		Labels:  task.Labels,
+		Status:  TaskPending,
+		Created: time.Now().Unix(),
+	})
+	if err == nil {
+		q.broadcast()
+	}
+	return err
+}
+
+// Poll retrieves and removes a task head of this queue.
+func (q *sharedQueue) Poll(c context.Context, f queue.Filter) (*queue.Task, error) {
+	atomic.AddInt32(&q.workers, 1)
+	defer atomic.AddInt32(&q.workers, -1)
+
+	for {
+		signal := q.wait()
+
+		task, err := q.claim(f)
+		if err != nil {
+			logrus.Errorf("queue: cannot claim task: %s", err)
+		}
+		if task != nil {
+			return task, nil
+		}
+
+		select {
+		case <-c.Done():
+			return nil, nil
+		case <-signal:
+		case <-time.After(q.interval):
+		}
+	}
+}
+
+// Extend extends the deadline for a task.
+func (q *sharedQueue) Extend(c context.Context, id string) error {
+	deadline := time.Now().Add(q.extension).Unix()
+	ok, err := q.store.TaskExtend(id, deadline)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return queue.ErrNotFound
+	}
+	return nil
+}
+
+// Done signals the task is complete.
+func (q *sharedQueue) Done(c context.Context, id string) error {
+	return q.Error(c, id, nil)
+}
+
+// Error signals the task is complete with errors.
+func (q *sharedQueue) Error(c context.Context, id string, err error) error {
+	var message string
+	if err != nil {
+		message = err.Error()
+	}
+	ok, serr := q.store.TaskComplete(id, message)
+	if serr != nil {
+		return serr
+	}
+	if ok {
+		q.broadcast()
+	}
+	return nil
+}
+
+// Evict removes a pending task from the queue.
+func (q *sharedQueue) Evict(c context.Context, id string) error {
+	ok, err := q.store.TaskEvict(id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return queue.ErrNotFound
+	}
+	return nil
+}
+
+// Wait waits until the task is complete.
+func (q *sharedQueue) Wait(c context.Context, id string) error {
+	for {
+		signal := q.wait()
+
+		task, err := q.store.TaskFind(id)
+		if err != nil {
+			return nil
+		}
+		switch {
+		case task.Status == TaskDone && task.Error == queue.ErrCancel.Error():
+			return queue.ErrCancel
+		case task.Status == TaskDone && task.Error != "":
+			return errors.New(task.Error)
+		case task.Status == TaskDone:
+			return nil
+		case task.Status == TaskRunning && task.Deadline < time.Now().Unix():
+			// the lease expired and the task is returned to the
+			// queue, therefore the current execution is complete.
+			return nil
+		}
+
+		select {
+		case <-c.Done():
+			return nil
+		case <-signal:
+		case <-time.After(q.interval):
+		}
+	}
+}
+
+// Info returns internal queue information.
+func (q *sharedQueue) Info(c context.Context) queue.InfoT {
+	stats := queue.InfoT{}
+	stats.Stats.Workers = int(atomic.LoadInt32(&q.workers))
+
+	pending, err := q.store.TaskListStatus(TaskPending)
+	if err != nil {
+		logrus.Errorf("queue: cannot list pending tasks: %s", err)
+	}
+	for _, task := range pending {
+		stats.Pending = append(stats.Pending, toQueueTask(task))
+	}
+	running, err := q.store.TaskListStatus(TaskRunning)
+	if err != nil {
+		logrus.Errorf("queue: cannot list running tasks: %s", err)
+	}
+	for _, task := range running {
+		stats.Running = append(stats.Running, toQueueTask(task))
+	}
+	stats.Stats.Pending = len(stats.Pending)
+	stats.Stats.Running = len(stats.Running)
+	return stats
+}
+
+// helper function that attempts to claim the oldest ready task that
+// matches the filter. A task that is claimed concurrently by another
+// server is skipped.
+func (q *sharedQueue) claim(f queue.Filter) (*queue.Task, error) {
+	now := time.Now()
+	q.purge(now)
+
+	tasks, err := q.store.TaskListReady(now.Unix())
+	if err != nil {
+		return nil, err
+	}
+	for _, task := range tasks {
+		item := toQueueTask(task)
+		if !f(item) {
+			continue
+		}
+		ok, err := q.store.TaskClaim(task, now.Add(q.extension).Unix())
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return item, nil
+		}
+	}
+	return nil, nil
+}
+
+// helper function that deletes completed tasks once the
+// retention period has elapsed.
+func (q *sharedQueue) purge(now time.Time) {
+	q.Lock()
+	if now.Sub(q.purged) < q.retention/2 {
+		q.Unlock()
+		return
+	}
+	q.purged = now
+	q.Unlock()
+
+	if err := q.store.TaskPurge(now.Add(-q.retention).Unix()); err != nil {
+		logrus.Errorf("queue: cannot purge completed tasks: %s", err)
+	}
+}
+
+// helper function returns a channel that is closed on the next
+// change to the queue made by this server.
+func (q *sharedQueue) wait() <-chan struct{} {
+	q.Lock()
+	defer q.Unlock()
+	return q.signal
+}
+
+// helper function that wakes all workers and waiters blocked
+// on this server.
+func (q *sharedQueue) broadcast() {
+	q.Lock()
+	close(q.signal)
+	q.signal = make(chan struct{})
+	q.Unlock()
+}
+
+func toQueueTask(task *Task) *queue.Task {
+	return &queue.Task{
+		ID:     task.ID,
+		Data:   task.Data,
+		Labels: task.Labels,
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cncd/queue"
+)
+
+type fakeTaskStore struct {
+	TaskQueueStore
+	tasks []*Task
+	err   error
+}
+
+func (s *fakeTaskStore) TaskList() ([]*Task, error) { return nil, nil }
+
+func (s *fakeTaskStore) TaskInsert(task *Task) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.tasks = append(s.tasks, task)
+	return nil
+}
+
+func (s *fakeTaskStore) TaskDelete(string) error { return nil }
+
+func TestTaskCreated(t *testing.T) {
+	store := new(fakeTaskStore)
+	queues := []queue.Queue{
+		WithTaskStore(queue.New(), store),
+		NewTaskQueue(store),
+	}
+
+	before := time.Now().Unix()
+	for _, q := range queues {
+		if err := q.Push(context.Background(), &queue.Task{ID: "1"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	after := time.Now().Unix()
+
+	if got, want := len(store.tasks), len(queues); got != want {
+		t.Fatalf("Want %d stored tasks, got %d", want, got)
+	}
+	for _, task := range store.tasks {
+		if task.Created < before || task.Created > after {
+			t.Errorf("Want created in seconds between %d and %d, got %d", before, after, task.Created)
+		}
+	}
+}
+
+func TestTaskInsertError(t *testing.T) {
+	store := &fakeTaskStore{err: errors.New("insert failed")}
+	q := WithTaskStore(queue.New(), store)
+
+	if err := q.Push(context.Background(), &queue.Task{ID: "1"}); err != store.err {
+		t.Errorf("Want insert error, got %v", err)
+	}
+	if got := len(q.Info(context.Background()).Pending); got != 0 {
+		t.Errorf("Want task not queued, got %d pending", got)
+	}
+}
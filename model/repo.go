@@ -51,6 +51,8 @@ type Repo struct {
 	AllowPush   bool   `json:"allow_push"               meddler:"repo_allow_push"`
 	AllowDeploy bool   `json:"allow_deploys"            meddler:"repo_allow_deploys"`
 	AllowTag    bool   `json:"allow_tags"               meddler:"repo_allow_tags"`
+	CancelPush  bool   `json:"cancel_push"              meddler:"repo_cancel_push"`
+	CancelPull  bool   `json:"cancel_pr"                meddler:"repo_cancel_pull"`
 	Counter     int    `json:"last_build"               meddler:"repo_counter"`
 	Config      string `json:"config_file"              meddler:"repo_config_path"`
 	Hash        string `json:"-"                        meddler:"repo_hash"`
@@ -104,5 +106,20 @@ type RepoPatch struct {
 	AllowPush    *bool   `json:"allow_push,omitempty"`
 	AllowDeploy  *bool   `json:"allow_deploy,omitempty"`
 	AllowTag     *bool   `json:"allow_tag,omitempty"`
+	CancelPush   *bool   `json:"cancel_push,omitempty"`
+	CancelPull   *bool   `json:"cancel_pr,omitempty"`
 	BuildCounter *int    `json:"build_counter,omitempty"`
 }
+
+// CancelPrevious returns true if builds for the given event should cancel
+// pending and running builds of the same ref.
+func (r *Repo) CancelPrevious(event string) bool {
+	switch event {
+	case EventPush:
+		return r.CancelPush
+	case EventPull:
+		return r.CancelPull
+	default:
+		return false
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"errors"
+	"sort"
+)
+
+var errRetentionInvalid = errors.New("Invalid Retention Policy")
+
+// RetentionStore persists retention policies to storage.
+type RetentionStore interface {
+	RetentionFind(int64) (*Retention, error)
+	RetentionList() ([]*Retention, error)
+	RetentionCreate(*Retention) error
+	RetentionUpdate(*Retention) error
+	RetentionDelete(*Retention) error
+}
+
+// Retention represents a build log retention policy. A policy with a
+// zero RepoID is global and applies to repositories without a policy.
+// swagger:model retention
+type Retention struct {
+	ID           int64 `json:"id"            meddler:"policy_id,pk"`
+	RepoID       int64 `json:"repo_id"       meddler:"policy_repo_id"`
+	MaxAge       int64 `json:"max_age"       meddler:"policy_max_age"`
+	KeepBuilds   int   `json:"keep_builds"   meddler:"policy_keep_builds"`
+	KeepSuccess  bool  `json:"keep_success"  meddler:"policy_keep_success"`
+	DeleteBuilds bool  `json:"delete_builds" meddler:"policy_delete_builds"`
+	Created      int64 `json:"created"       meddler:"policy_created"`
+	Updated      int64 `json:"updated"       meddler:"policy_updated"`
+}
+
+// Validate validates the required fields and formats.
+func (r *Retention) Validate() error {
+	switch {
+	case r.MaxAge < 0:
+		return errRetentionInvalid
+	case r.KeepBuilds < 0:
+		return errRetentionInvalid
+	default:
+		return nil
+	}
+}
+
+// Prune returns the finished builds that fall outside the retention
+// policy. A build is pruned when it is older than the maximum age, in
+// seconds, or when newer builds on the same branch exceed the number of
+// builds to keep. The last successful build of each branch is retained
+// when configured. Builds that are already pruned are not returned
+// unless the policy deletes builds.
+func (r *Retention) Prune(builds []*Build, now int64) []*Build {
+	sorted := make([]*Build, len(builds))
+	copy(sorted, builds)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Number > sorted[j].Number
+	})
+
+	var (
+		pruned   []*Build
+		count    = map[string]int{}
+		success  = map[string]bool{}
+		deadline = now - r.MaxAge
+	)
+	for _, build := range sorted {
+		switch build.Status {
+		case StatusPending, StatusRunning, StatusBlocked:
+			continue
+		}
+		index := count[build.Branch]
+		count[build.Branch]++
+
+		if r.KeepSuccess && build.Status == StatusSuccess && !success[build.Branch] {
+			success[build.Branch] = true
+			continue
+		}
+		expired := r.MaxAge > 0 && build.Created < deadline
+		exceeded := r.KeepBuilds > 0 && index >= r.KeepBuilds
+		if !expired && !exceeded {
+			continue
+		}
+		if build.Pruned && !r.DeleteBuilds {
+			continue
+		}
+		pruned = append(pruned, build)
+	}
+	return pruned
+}
@@ -0,0 +1,69 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/franela/goblin"
+)
+
+func TestRetention(t *testing.T) {
+	g := goblin.Goblin(t)
+	g.Describe("Retention policy", func() {
+		builds := []*Build{
+			{Number: 1, Branch: "master", Status: StatusSuccess, Created: 100},
+			{Number: 2, Branch: "master", Status: StatusFailure, Created: 200},
+			{Number: 3, Branch: "develop", Status: StatusFailure, Created: 300},
+			{Number: 4, Branch: "master", Status: StatusFailure, Created: 400},
+			{Number: 5, Branch: "master", Status: StatusRunning, Created: 500},
+		}
+		numbers := func(builds []*Build) []int {
+			out := []int{}
+			for _, build := range builds {
+				out = append(out, build.Number)
+			}
+			return out
+		}
+		g.It("should not prune without limits", func() {
+			policy := Retention{}
+			g.Assert(len(policy.Prune(builds, 1000))).Equal(0)
+		})
+		g.It("should prune builds exceeding the branch limit", func() {
+			policy := Retention{KeepBuilds: 1}
+			g.Assert(numbers(policy.Prune(builds, 1000))).Equal([]int{2, 1})
+		})
+		g.It("should prune builds exceeding the maximum age", func() {
+			policy := Retention{MaxAge: 750}
+			g.Assert(numbers(policy.Prune(builds, 1000))).Equal([]int{2, 1})
+		})
+		g.It("should keep the last successful build", func() {
+			policy := Retention{KeepBuilds: 1, KeepSuccess: true}
+			g.Assert(numbers(policy.Prune(builds, 1000))).Equal([]int{2})
+		})
+		g.It("should skip pruned builds", func() {
+			policy := Retention{MaxAge: 1}
+			pruned := []*Build{{Number: 1, Status: StatusSuccess, Pruned: true}}
+			g.Assert(len(policy.Prune(pruned, 1000))).Equal(0)
+			policy.DeleteBuilds = true
+			g.Assert(len(policy.Prune(pruned, 1000))).Equal(1)
+		})
+		g.It("should validate limits", func() {
+			g.Assert((&Retention{MaxAge: -1}).Validate() != nil).IsTrue()
+			g.Assert((&Retention{KeepBuilds: -1}).Validate() != nil).IsTrue()
+			g.Assert((&Retention{KeepBuilds: 5}).Validate() == nil).IsTrue()
+		})
+	})
+}
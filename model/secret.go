@@ -32,6 +32,11 @@ type SecretService interface {
 	SecretCreate(*Repo, *Secret) error
 	SecretUpdate(*Repo, *Secret) error
 	SecretDelete(*Repo, string) error
+	OrgSecretFind(string, string) (*Secret, error)
+	OrgSecretList(string) ([]*Secret, error)
+	OrgSecretCreate(string, *Secret) error
+	OrgSecretUpdate(string, *Secret) error
+	OrgSecretDelete(string, string) error
 }
 
 // SecretStore persists secret information to storage.
@@ -41,13 +46,25 @@ type SecretStore interface {
 	SecretCreate(*Secret) error
 	SecretUpdate(*Secret) error
 	SecretDelete(*Secret) error
+	OrgSecretFind(string, string) (*Secret, error)
+	OrgSecretList(string) ([]*Secret, error)
+	OrgSecretCreate(*Secret) error
+	OrgSecretUpdate(*Secret) error
+	OrgSecretDelete(*Secret) error
 }
 
+// GlobalOwner is the owner of secrets that are shared with all
+// repositories in the system.
+const GlobalOwner = ""
+
 // Secret represents a secret variable, such as a password or token.
+// Secrets belong to a repository, or are shared by all repositories of
+// an owner (organization) or by all repositories in the system.
 // swagger:model registry
 type Secret struct {
 	ID         int64    `json:"id"              meddler:"secret_id,pk"`
 	RepoID     int64    `json:"-"               meddler:"secret_repo_id"`
+	Owner      string   `json:"owner,omitempty" meddler:"-"`
 	Name       string   `json:"name"            meddler:"secret_name"`
 	Value      string   `json:"value,omitempty" meddler:"secret_value"`
 	Images     []string `json:"image"           meddler:"secret_images,json"`
@@ -86,6 +103,7 @@ func (s *Secret) Copy() *Secret {
 	return &Secret{
 		ID:     s.ID,
 		RepoID: s.RepoID,
+		Owner:  s.Owner,
 		Name:   s.Name,
 		Images: s.Images,
 		Events: s.Events,
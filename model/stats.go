@@ -0,0 +1,206 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"sort"
+	"time"
+)
+
+// Stats intervals.
+const (
+	StatsDay  = "day"
+	StatsWeek = "week"
+)
+
+// StatsStore loads the timings of the builds and steps created since
+// the given time, optionally filtered by branch, that are aggregated in
+// the repository statistics.
+type StatsStore interface {
+	StatsBuildList(repo *Repo, branch string, since int64) ([]*StatsBuild, error)
+	StatsProcList(repo *Repo, branch string, since int64) ([]*StatsProc, error)
+}
+
+// StatsBuild represents the timings of a finished build.
+type StatsBuild struct {
+	Branch   string `meddler:"build_branch"`
+	Status   string `meddler:"build_status"`
+	Enqueued int64  `meddler:"build_enqueued"`
+	Created  int64  `meddler:"build_created"`
+	Started  int64  `meddler:"build_started"`
+	Finished int64  `meddler:"build_finished"`
+}
+
+// StatsProc represents the timings of a pipeline step, and the branch
+// and creation time of its build.
+type StatsProc struct {
+	Name    string `meddler:"proc_name"`
+	State   string `meddler:"proc_state"`
+	Started int64  `meddler:"proc_started"`
+	Stopped int64  `meddler:"proc_stopped"`
+	Branch  string `meddler:"build_branch"`
+	Created int64  `meddler:"build_created"`
+}
+
+// StatsOptions defines how the statistics are bucketed.
+type StatsOptions struct {
+	Interval      string // day or week
+	GroupByBranch bool
+}
+
+// StatsBucket represents the aggregated statistics of the builds
+// created in a day or week, for a single branch or for all branches
+// when the branch is empty. Durations are in seconds.
+//
+// swagger:model statsBucket
+type StatsBucket struct {
+	Start       int64        `json:"start"`
+	Branch      string       `json:"branch,omitempty"`
+	Builds      int          `json:"builds"`
+	Success     int          `json:"success"`
+	SuccessRate float64      `json:"success_rate"`
+	DurationP50 int64        `json:"duration_p50"`
+	DurationP95 int64        `json:"duration_p95"`
+	QueueP50    int64        `json:"queue_p50"`
+	QueueP95    int64        `json:"queue_p95"`
+	Steps       []*StatsStep `json:"steps"`
+
+	durations []int64
+	queue     []int64
+	steps     map[string]*StatsStep
+}
+
+// StatsStep represents the aggregated duration of a named step.
+type StatsStep struct {
+	Name        string `json:"name"`
+	Count       int    `json:"count"`
+	Failures    int    `json:"failures"`
+	DurationP50 int64  `json:"duration_p50"`
+	DurationP95 int64  `json:"duration_p95"`
+
+	durations []int64
+}
+
+// Stats aggregates the build and step timings into buckets, ordered by
+// start time and branch.
+func Stats(builds []*StatsBuild, procs []*StatsProc, opts StatsOptions) []*StatsBucket {
+	buckets := map[statsKey]*StatsBucket{}
+	bucket := func(created int64, branch string) *StatsBucket {
+		key := statsKey{start: statsStart(created, opts.Interval)}
+		if opts.GroupByBranch {
+			key.branch = branch
+		}
+		b, ok := buckets[key]
+		if !ok {
+			b = &StatsBucket{
+				Start:  key.start,
+				Branch: key.branch,
+				Steps:  []*StatsStep{},
+				steps:  map[string]*StatsStep{},
+			}
+			buckets[key] = b
+		}
+		return b
+	}
+
+	for _, build := range builds {
+		b := bucket(build.Created, build.Branch)
+		b.Builds++
+		if build.Status == StatusSuccess {
+			b.Success++
+		}
+		if build.Started != 0 && build.Finished >= build.Started {
+			b.durations = append(b.durations, build.Finished-build.Started)
+		}
+		if build.Enqueued != 0 && build.Started >= build.Enqueued {
+			b.queue = append(b.queue, build.Started-build.Enqueued)
+		}
+	}
+	for _, proc := range procs {
+		if proc.Started == 0 || proc.Stopped < proc.Started {
+			continue
+		}
+		b := bucket(proc.Created, proc.Branch)
+		step, ok := b.steps[proc.Name]
+		if !ok {
+			step = &StatsStep{Name: proc.Name}
+			b.steps[proc.Name] = step
+			b.Steps = append(b.Steps, step)
+		}
+		step.Count++
+		if proc.State == StatusFailure || proc.State == StatusError || proc.State == StatusKilled {
+			step.Failures++
+		}
+		step.durations = append(step.durations, proc.Stopped-proc.Started)
+	}
+
+	list := make([]*StatsBucket, 0, len(buckets))
+	for _, b := range buckets {
+		if b.Builds != 0 {
+			b.SuccessRate = float64(b.Success) / float64(b.Builds)
+		}
+		b.DurationP50, b.DurationP95 = percentiles(b.durations)
+		b.QueueP50, b.QueueP95 = percentiles(b.queue)
+		for _, step := range b.Steps {
+			step.DurationP50, step.DurationP95 = percentiles(step.durations)
+		}
+		sort.Slice(b.Steps, func(i, j int) bool {
+			return b.Steps[i].Name < b.Steps[j].Name
+		})
+		list = append(list, b)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Start != list[j].Start {
+			return list[i].Start < list[j].Start
+		}
+		return list[i].Branch < list[j].Branch
+	})
+	return list
+}
+
+type statsKey struct {
+	start  int64
+	branch string
+}
+
+// statsStart returns the start of the day, or of the week starting on
+// Monday, in UTC.
+func statsStart(created int64, interval string) int64 {
+	t := time.Unix(created, 0).UTC()
+	t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if interval == StatsWeek {
+		t = t.AddDate(0, 0, -(int(t.Weekday())+6)%7)
+	}
+	return t.Unix()
+}
+
+// percentiles returns the 50th and 95th percentile of the values using
+// the nearest rank method.
+func percentiles(values []int64) (p50, p95 int64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return values[i] < values[j]
+	})
+	rank := func(p int) int64 {
+		i := (p*len(values)+99)/100 - 1
+		if i < 0 {
+			i = 0
+		}
+		return values[i]
+	}
+	return rank(50), rank(95)
+}
@@ -0,0 +1,79 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/franela/goblin"
+)
+
+func TestStats(t *testing.T) {
+	g := goblin.Goblin(t)
+	g.Describe("Stats", func() {
+		// 2018-01-03 is a Wednesday
+		var (
+			day1 int64 = 1514937600 // 2018-01-03
+			day2 int64 = 1515024000 // 2018-01-04
+		)
+		builds := []*StatsBuild{
+			{Branch: "master", Status: StatusSuccess, Created: day1 + 60, Enqueued: day1 + 60, Started: day1 + 70, Finished: day1 + 170},
+			{Branch: "master", Status: StatusFailure, Created: day1 + 120, Enqueued: day1 + 120, Started: day1 + 150, Finished: day1 + 450},
+			{Branch: "develop", Status: StatusSuccess, Created: day2 + 60, Enqueued: day2 + 60, Started: day2 + 60, Finished: day2 + 260},
+		}
+		procs := []*StatsProc{
+			{Name: "test", State: StatusSuccess, Started: day1 + 70, Stopped: day1 + 130, Branch: "master", Created: day1 + 60},
+			{Name: "test", State: StatusFailure, Started: day1 + 150, Stopped: day1 + 450, Branch: "master", Created: day1 + 120},
+			{Name: "build", State: StatusSuccess, Started: day1 + 130, Stopped: day1 + 170, Branch: "master", Created: day1 + 60},
+			{Name: "deploy", State: StatusSkipped, Branch: "master", Created: day1 + 120},
+		}
+
+		g.It("should bucket by day", func() {
+			stats := Stats(builds, procs, StatsOptions{Interval: StatsDay})
+			g.Assert(len(stats)).Equal(2)
+			g.Assert(stats[0].Start).Equal(day1)
+			g.Assert(stats[0].Builds).Equal(2)
+			g.Assert(stats[0].SuccessRate).Equal(0.5)
+			g.Assert(stats[0].DurationP50).Equal(int64(100))
+			g.Assert(stats[0].DurationP95).Equal(int64(300))
+			g.Assert(stats[0].QueueP50).Equal(int64(10))
+			g.Assert(stats[0].QueueP95).Equal(int64(30))
+			g.Assert(stats[1].Start).Equal(day2)
+		})
+		g.It("should bucket by week", func() {
+			stats := Stats(builds, procs, StatsOptions{Interval: StatsWeek})
+			g.Assert(len(stats)).Equal(1)
+			g.Assert(stats[0].Start).Equal(int64(1514764800)) // 2018-01-01
+			g.Assert(stats[0].Builds).Equal(3)
+		})
+		g.It("should bucket by branch", func() {
+			stats := Stats(builds, procs, StatsOptions{Interval: StatsWeek, GroupByBranch: true})
+			g.Assert(len(stats)).Equal(2)
+			g.Assert(stats[0].Branch).Equal("develop")
+			g.Assert(stats[1].Branch).Equal("master")
+		})
+		g.It("should aggregate step durations", func() {
+			stats := Stats(builds, procs, StatsOptions{Interval: StatsDay})
+			steps := stats[0].Steps
+			g.Assert(len(steps)).Equal(2)
+			g.Assert(steps[0].Name).Equal("build")
+			g.Assert(steps[1].Name).Equal("test")
+			g.Assert(steps[1].Count).Equal(2)
+			g.Assert(steps[1].Failures).Equal(1)
+			g.Assert(steps[1].DurationP50).Equal(int64(60))
+			g.Assert(steps[1].DurationP95).Equal(int64(300))
+		})
+	})
+}
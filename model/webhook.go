@@ -0,0 +1,127 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"errors"
+	"net/url"
+)
+
+var (
+	errWebhookURLInvalid   = errors.New("Invalid Webhook URL")
+	errWebhookEventInvalid = errors.New("Invalid Webhook Event")
+)
+
+// WebhookStore persists outbound webhook information to storage.
+type WebhookStore interface {
+	WebhookFind(int64) (*Webhook, error)
+	WebhookList(*Repo) ([]*Webhook, error)
+	WebhookListGlobal() ([]*Webhook, error)
+	WebhookCreate(*Webhook) error
+	WebhookUpdate(*Webhook) error
+	WebhookDelete(*Webhook) error
+	WebhookDeliveryFind(int64) (*WebhookDelivery, error)
+	WebhookDeliveryList(*Webhook) ([]*WebhookDelivery, error)
+	WebhookDeliveryCreate(*WebhookDelivery) error
+	WebhookDeliveryUpdate(*WebhookDelivery) error
+}
+
+// Webhook represents an outbound webhook that receives build events.
+// A webhook with a zero RepoID is global and receives events for all
+// repositories.
+// swagger:model webhook
+type Webhook struct {
+	ID         int64       `json:"id"               meddler:"webhook_id,pk"`
+	RepoID     int64       `json:"repo_id"          meddler:"webhook_repo_id"`
+	URL        string      `json:"url"              meddler:"webhook_url"`
+	Secret     string      `json:"secret,omitempty" meddler:"webhook_secret"`
+	Events     []EventType `json:"events"           meddler:"webhook_events,json"`
+	SkipVerify bool        `json:"skip_verify"      meddler:"webhook_skip_verify"`
+	Disabled   bool        `json:"disabled"         meddler:"webhook_disabled"`
+	Created    int64       `json:"created"          meddler:"webhook_created"`
+	Updated    int64       `json:"updated"          meddler:"webhook_updated"`
+}
+
+// WebhookPatch represents a patch to an outbound webhook.
+type WebhookPatch struct {
+	URL        *string     `json:"url"`
+	Secret     *string     `json:"secret"`
+	Events     []EventType `json:"events"`
+	SkipVerify *bool       `json:"skip_verify"`
+	Disabled   *bool       `json:"disabled"`
+}
+
+// Match returns true if the webhook subscribes to the event type. A
+// webhook without events subscribes to all event types.
+func (w *Webhook) Match(event EventType) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate validates the required fields and formats.
+func (w *Webhook) Validate() error {
+	uri, err := url.Parse(w.URL)
+	if err != nil || len(w.URL) > 2000 {
+		return errWebhookURLInvalid
+	}
+	if uri.Scheme != "http" && uri.Scheme != "https" || uri.Host == "" {
+		return errWebhookURLInvalid
+	}
+	for _, e := range w.Events {
+		switch e {
+		case Enqueued, Started, Finished:
+		default:
+			return errWebhookEventInvalid
+		}
+	}
+	return nil
+}
+
+// Copy makes a copy of the webhook without the secret.
+func (w *Webhook) Copy() *Webhook {
+	return &Webhook{
+		ID:         w.ID,
+		RepoID:     w.RepoID,
+		URL:        w.URL,
+		Events:     w.Events,
+		SkipVerify: w.SkipVerify,
+		Disabled:   w.Disabled,
+		Created:    w.Created,
+		Updated:    w.Updated,
+	}
+}
+
+// WebhookDelivery represents a single delivery of an event to an
+// outbound webhook, including its outcome.
+// swagger:model webhookDelivery
+type WebhookDelivery struct {
+	ID         int64     `json:"id"         meddler:"delivery_id,pk"`
+	WebhookID  int64     `json:"webhook_id" meddler:"delivery_webhook_id"`
+	Event      EventType `json:"event"      meddler:"delivery_event"`
+	Payload    []byte    `json:"payload"    meddler:"delivery_payload"`
+	Status     int       `json:"status"     meddler:"delivery_status"`
+	Error      string    `json:"error"      meddler:"delivery_error"`
+	Attempts   int       `json:"attempts"   meddler:"delivery_attempts"`
+	Redelivery bool      `json:"redelivery" meddler:"delivery_redelivery"`
+	Created    int64     `json:"created"    meddler:"delivery_created"`
+	Updated    int64     `json:"updated"    meddler:"delivery_updated"`
+}
@@ -0,0 +1,51 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/franela/goblin"
+)
+
+func TestWebhook(t *testing.T) {
+	g := goblin.Goblin(t)
+	g.Describe("Webhook", func() {
+		g.It("should match all events when unfiltered", func() {
+			hook := Webhook{}
+			g.Assert(hook.Match(Enqueued)).IsTrue()
+			g.Assert(hook.Match(Finished)).IsTrue()
+		})
+		g.It("should match subscribed events", func() {
+			hook := Webhook{Events: []EventType{Finished}}
+			g.Assert(hook.Match(Finished)).IsTrue()
+			g.Assert(hook.Match(Started)).IsFalse()
+		})
+		g.It("should validate the url", func() {
+			g.Assert((&Webhook{URL: "https://example.com/hook"}).Validate() == nil).IsTrue()
+			g.Assert((&Webhook{URL: "ftp://example.com"}).Validate() != nil).IsTrue()
+			g.Assert((&Webhook{URL: "/hook"}).Validate() != nil).IsTrue()
+		})
+		g.It("should validate the events", func() {
+			hook := Webhook{URL: "https://example.com", Events: []EventType{"pushed"}}
+			g.Assert(hook.Validate() != nil).IsTrue()
+		})
+		g.It("should not copy the secret", func() {
+			hook := Webhook{ID: 1, URL: "https://example.com", Secret: "correct-horse"}
+			g.Assert(hook.Copy().Secret).Equal("")
+			g.Assert(hook.Copy().URL).Equal(hook.URL)
+		})
+	})
+}
@@ -0,0 +1,53 @@
+package environ
+
+import (
+	"path/filepath"
+
+	"github.com/drone/drone/model"
+)
+
+// Variable defines a global environment variable that is optionally
+// limited to repositories matching one or more name patterns.
+type Variable struct {
+	Name  string   `yaml:"name"`
+	Value string   `yaml:"value"`
+	Repos []string `yaml:"repos"`
+}
+
+// Match returns true if the variable applies to the named repository.
+func (v *Variable) Match(repo string) bool {
+	if len(v.Repos) == 0 {
+		return true
+	}
+	for _, pattern := range v.Repos {
+		if match, _ := filepath.Match(pattern, repo); match {
+			return true
+		}
+	}
+	return false
+}
+
+type builtin struct {
+	vars []*Variable
+}
+
+// New returns a new local environment service that provides the given
+// variables. If a variable is defined more than once for a repository,
+// the last definition takes precedence.
+func New(vars []*Variable) model.EnvironService {
+	return &builtin{vars}
+}
+
+func (b *builtin) EnvironList(repo *model.Repo) ([]*model.Environ, error) {
+	var list []*model.Environ
+	for _, v := range b.vars {
+		if !v.Match(repo.FullName) {
+			continue
+		}
+		list = append(list, &model.Environ{
+			Name:  v.Name,
+			Value: v.Value,
+		})
+	}
+	return list, nil
+}
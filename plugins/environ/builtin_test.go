@@ -0,0 +1,44 @@
+package environ
+
+import (
+	"testing"
+
+	"github.com/drone/drone/model"
+)
+
+func TestEnvironList(t *testing.T) {
+	service := New([]*Variable{
+		{Name: "GOPROXY", Value: "https://proxy.golang.org"},
+		{Name: "NPM_REGISTRY", Value: "https://npm.example.com", Repos: []string{"octocat/*"}},
+		{Name: "GOPROXY", Value: "https://athens.example.com", Repos: []string{"spaceghost/*"}},
+	})
+
+	list, err := service.EnvironList(&model.Repo{FullName: "octocat/hello-world"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(list), 2; got != want {
+		t.Errorf("Want %d variables, got %d", want, got)
+		return
+	}
+	if got, want := list[0].Value, "https://proxy.golang.org"; got != want {
+		t.Errorf("Want variable value %s, got %s", want, got)
+	}
+	if got, want := list[1].Name, "NPM_REGISTRY"; got != want {
+		t.Errorf("Want scoped variable %s, got %s", want, got)
+	}
+
+	list, err = service.EnvironList(&model.Repo{FullName: "spaceghost/hello-world"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(list), 2; got != want {
+		t.Errorf("Want %d variables, got %d", want, got)
+		return
+	}
+	if got, want := list[1].Value, "https://athens.example.com"; got != want {
+		t.Errorf("Want overriding variable value %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,68 @@
+package environ
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Prefix is the prefix of server settings that define global variables.
+// For example, DRONE_ENVIRON_GOPROXY defines the GOPROXY variable.
+const Prefix = "DRONE_ENVIRON_"
+
+// reserved settings configure the environment service and
+// therefore are not treated as variables.
+var reserved = map[string]bool{
+	"DRONE_ENVIRON_FILE":     true,
+	"DRONE_ENVIRON_ENDPOINT": true,
+}
+
+// Parse parses a list of variables in yaml format.
+func Parse(data []byte) ([]*Variable, error) {
+	var vars []*Variable
+	if err := yaml.Unmarshal(data, &vars); err != nil {
+		return nil, err
+	}
+	for _, v := range vars {
+		if v.Name == "" {
+			return nil, fmt.Errorf("environ: variable name is required")
+		}
+	}
+	return vars, nil
+}
+
+// ParseFile parses a list of variables from the named yaml file.
+func ParseFile(path string) ([]*Variable, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// ParseEnviron parses the variables defined by the prefixed settings in
+// the key=value list, typically os.Environ. Variables defined in settings
+// apply to all repositories and are sorted by name.
+func ParseEnviron(environ []string) []*Variable {
+	var vars []*Variable
+	for _, env := range environ {
+		parts := strings.SplitN(env, "=", 2)
+		if len(parts) != 2 || reserved[parts[0]] {
+			continue
+		}
+		if !strings.HasPrefix(parts[0], Prefix) || parts[0] == Prefix {
+			continue
+		}
+		vars = append(vars, &Variable{
+			Name:  strings.TrimPrefix(parts[0], Prefix),
+			Value: parts[1],
+		})
+	}
+	sort.Slice(vars, func(i, j int) bool {
+		return vars[i].Name < vars[j].Name
+	})
+	return vars
+}
@@ -0,0 +1,57 @@
+package environ
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	vars, err := Parse([]byte(`
+- name: GOPROXY
+  value: https://proxy.golang.org
+- name: NPM_REGISTRY
+  value: https://npm.example.com
+  repos: [ "octocat/*", "spaceghost/hello-world" ]
+`))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(vars), 2; got != want {
+		t.Errorf("Want %d variables, got %d", want, got)
+		return
+	}
+	if got, want := vars[0].Name, "GOPROXY"; got != want {
+		t.Errorf("Want variable name %s, got %s", want, got)
+	}
+	if got, want := vars[1].Repos[1], "spaceghost/hello-world"; got != want {
+		t.Errorf("Want variable repo pattern %s, got %s", want, got)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	_, err := Parse([]byte(`[ { value: bar } ]`))
+	if err == nil {
+		t.Errorf("Want error when variable name is missing")
+	}
+}
+
+func TestParseEnviron(t *testing.T) {
+	vars := ParseEnviron([]string{
+		"HOME=/root",
+		"DRONE_ENVIRON_NPM_REGISTRY=https://npm.example.com",
+		"DRONE_ENVIRON_GOPROXY=https://proxy.golang.org",
+		"DRONE_ENVIRON_FILE=/etc/drone/environ.yml",
+		"DRONE_ENVIRON_=empty",
+	})
+	if got, want := len(vars), 2; got != want {
+		t.Errorf("Want %d variables, got %d", want, got)
+		return
+	}
+	if got, want := vars[0].Name, "GOPROXY"; got != want {
+		t.Errorf("Want variable name %s, got %s", want, got)
+	}
+	if got, want := vars[0].Value, "https://proxy.golang.org"; got != want {
+		t.Errorf("Want variable value %s, got %s", want, got)
+	}
+	if got, want := vars[1].Name, "NPM_REGISTRY"; got != want {
+		t.Errorf("Want variable name %s, got %s", want, got)
+	}
+}
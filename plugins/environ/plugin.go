@@ -0,0 +1,24 @@
+package environ
+
+import (
+	"fmt"
+
+	"github.com/drone/drone/model"
+	"github.com/drone/drone/plugins/internal"
+)
+
+type plugin struct {
+	endpoint string
+}
+
+// NewRemote returns a new remote environment service.
+func NewRemote(endpoint string) model.EnvironService {
+	return &plugin{endpoint}
+}
+
+func (p *plugin) EnvironList(repo *model.Repo) ([]*model.Environ, error) {
+	path := fmt.Sprintf("%s/environ/%s/%s", p.endpoint, repo.Owner, repo.Name)
+	out := []*model.Environ{}
+	err := internal.Send("GET", path, nil, &out)
+	return out, err
+}
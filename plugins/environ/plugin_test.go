@@ -0,0 +1,33 @@
+package environ
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/drone/model"
+)
+
+func TestRemoteEnvironList(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/environ/octocat/hello-world" {
+			w.WriteHeader(404)
+			return
+		}
+		w.Write([]byte(`[{"name":"GOPROXY","value":"https://proxy.golang.org"}]`))
+	}))
+	defer server.Close()
+
+	list, err := NewRemote(server.URL).EnvironList(&model.Repo{Owner: "octocat", Name: "hello-world"})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(list), 1; got != want {
+		t.Errorf("Want %d variables, got %d", want, got)
+		return
+	}
+	if got, want := list[0].Value, "https://proxy.golang.org"; got != want {
+		t.Errorf("Want variable value %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,69 @@
+package logs
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/drone/drone/model"
+)
+
+type filesystem struct {
+	root string
+}
+
+// NewFilesystem returns a new log store that persists logs to files in
+// the root directory.
+func NewFilesystem(root string) (model.LogStore, error) {
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return nil, err
+	}
+	return &filesystem{root}, nil
+}
+
+func (f *filesystem) LogFind(proc *model.Proc) (io.ReadCloser, error) {
+	return os.Open(f.path(proc))
+}
+
+func (f *filesystem) LogSave(proc *model.Proc, r io.Reader) error {
+	path := f.path(proc)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	// write to a temporary file that is renamed when complete so that
+	// readers never observe a partially written log.
+	tmp := path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (f *filesystem) LogDelete(proc *model.Proc) error {
+	err := os.Remove(f.path(proc))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// path returns the file path of the proc logs. Logs are sharded into
+// directories of at most 1000 files.
+func (f *filesystem) path(proc *model.Proc) string {
+	return filepath.Join(
+		f.root,
+		strconv.FormatInt(proc.ID/1000, 10),
+		strconv.FormatInt(proc.ID, 10),
+	)
+}
@@ -0,0 +1,51 @@
+package logs
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/drone/drone/model"
+)
+
+func TestFilesystem(t *testing.T) {
+	dir, err := ioutil.TempDir("", "drone-logs")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := NewFilesystem(dir)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	proc := &model.Proc{ID: 1042}
+	if err := store.LogSave(proc, bytes.NewBufferString("echo hi")); err != nil {
+		t.Errorf("Unexpected error: log save: %s", err)
+		return
+	}
+
+	rc, err := store.LogFind(proc)
+	if err != nil {
+		t.Errorf("Unexpected error: log find: %s", err)
+		return
+	}
+	out, _ := ioutil.ReadAll(rc)
+	rc.Close()
+	if got, want := string(out), "echo hi"; got != want {
+		t.Errorf("Want log data %s, got %s", want, got)
+	}
+
+	if err := store.LogDelete(proc); err != nil {
+		t.Errorf("Unexpected error: log delete: %s", err)
+	}
+	if _, err := store.LogFind(proc); err == nil {
+		t.Errorf("Want error finding deleted log")
+	}
+	if err := store.LogDelete(proc); err != nil {
+		t.Errorf("Want no error deleting a missing log, got %s", err)
+	}
+}
@@ -0,0 +1,130 @@
+package logs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/drone/drone/model"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/signer/v4"
+)
+
+// S3Config configures the S3 compatible log store.
+type S3Config struct {
+	Endpoint  string // Endpoint of the object storage, eg https://s3.amazonaws.com
+	Bucket    string // Bucket name.
+	Prefix    string // Prefix of the object keys.
+	Region    string // Region used to sign requests.
+	AccessKey string // Access key.
+	SecretKey string // Secret key.
+	PathStyle bool   // Use path style bucket addressing, required for MinIO.
+}
+
+type s3 struct {
+	config S3Config
+	client *http.Client
+	signer *v4.Signer
+}
+
+// NewS3 returns a new log store that persists logs to S3 compatible
+// object storage, such as Amazon S3 or MinIO.
+func NewS3(config S3Config) (model.LogStore, error) {
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("logs: missing s3 bucket")
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = "https://s3.amazonaws.com"
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if _, err := url.Parse(config.Endpoint); err != nil {
+		return nil, err
+	}
+	signer := v4.NewSigner(
+		credentials.NewStaticCredentials(config.AccessKey, config.SecretKey, ""),
+		func(s *v4.Signer) { s.DisableURIPathEscaping = true },
+	)
+	return &s3{
+		config: config,
+		client: &http.Client{Timeout: time.Minute},
+		signer: signer,
+	}, nil
+}
+
+func (s *s3) LogFind(proc *model.Proc) (io.ReadCloser, error) {
+	res, err := s.do("GET", proc, nil)
+	if err != nil {
+		return nil, err
+	}
+	return res.Body, nil
+}
+
+func (s *s3) LogSave(proc *model.Proc, r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	res, err := s.do("PUT", proc, data)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+func (s *s3) LogDelete(proc *model.Proc) error {
+	res, err := s.do("DELETE", proc, nil)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+// do sends the signed request for the proc log object and returns the
+// response, or an error if the response status is not successful.
+func (s *s3) do(method string, proc *model.Proc, data []byte) (*http.Response, error) {
+	req, err := http.NewRequest(method, s.url(proc), nil)
+	if err != nil {
+		return nil, err
+	}
+	var body io.ReadSeeker
+	if data != nil {
+		body = bytes.NewReader(data)
+		req.ContentLength = int64(len(data))
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if _, err := s.signer.Sign(req, body, "s3", s.config.Region, time.Now()); err != nil {
+		return nil, err
+	}
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode > 299 {
+		out, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1024))
+		res.Body.Close()
+		return nil, fmt.Errorf("logs: %s %s: %s: %s", method, req.URL.Path, res.Status, out)
+	}
+	return res, nil
+}
+
+// url returns the url of the proc log object.
+func (s *s3) url(proc *model.Proc) string {
+	key := s.config.Prefix + strconv.FormatInt(proc.ID, 10)
+	uri, _ := url.Parse(s.config.Endpoint)
+	if s.config.PathStyle {
+		uri.Path = strings.TrimSuffix(uri.Path, "/") + "/" + s.config.Bucket + "/" + key
+	} else {
+		uri.Host = s.config.Bucket + "." + uri.Host
+		uri.Path = strings.TrimSuffix(uri.Path, "/") + "/" + key
+	}
+	return uri.String()
+}
@@ -0,0 +1,88 @@
+package logs
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/drone/drone/model"
+)
+
+func TestS3(t *testing.T) {
+	objects := map[string][]byte{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+			w.WriteHeader(403)
+			return
+		}
+		if r.Header.Get("X-Amz-Content-Sha256") == "" {
+			w.WriteHeader(400)
+			return
+		}
+		switch r.Method {
+		case "PUT":
+			objects[r.URL.Path], _ = ioutil.ReadAll(r.Body)
+		case "GET":
+			data, ok := objects[r.URL.Path]
+			if !ok {
+				w.WriteHeader(404)
+				return
+			}
+			w.Write(data)
+		case "DELETE":
+			delete(objects, r.URL.Path)
+			w.WriteHeader(204)
+		}
+	}))
+	defer server.Close()
+
+	store, err := NewS3(S3Config{
+		Endpoint:  server.URL,
+		Bucket:    "drone",
+		Prefix:    "logs/",
+		AccessKey: "AKID",
+		SecretKey: "SECRET",
+		PathStyle: true,
+	})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	proc := &model.Proc{ID: 42}
+	if err := store.LogSave(proc, bytes.NewBufferString("echo hi")); err != nil {
+		t.Errorf("Unexpected error: log save: %s", err)
+		return
+	}
+	if _, ok := objects["/drone/logs/42"]; !ok {
+		t.Errorf("Want log object stored at path style key")
+	}
+
+	rc, err := store.LogFind(proc)
+	if err != nil {
+		t.Errorf("Unexpected error: log find: %s", err)
+		return
+	}
+	out, _ := ioutil.ReadAll(rc)
+	rc.Close()
+	if got, want := string(out), "echo hi"; got != want {
+		t.Errorf("Want log data %s, got %s", want, got)
+	}
+
+	if err := store.LogDelete(proc); err != nil {
+		t.Errorf("Unexpected error: log delete: %s", err)
+	}
+	if _, err := store.LogFind(proc); err == nil {
+		t.Errorf("Want error finding deleted log")
+	}
+}
+
+func TestS3VirtualHostURL(t *testing.T) {
+	store, _ := NewS3(S3Config{Bucket: "drone"})
+	if got, want := store.(*s3).url(&model.Proc{ID: 1}), "https://drone.s3.amazonaws.com/1"; got != want {
+		t.Errorf("Want url %s, got %s", want, got)
+	}
+}
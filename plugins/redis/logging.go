@@ -0,0 +1,186 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/cncd/logging"
+	"github.com/gomodule/redigo/redis"
+)
+
+const (
+	loggingPrefix = "drone:logs:"
+
+	// loggingExpiry is the number of seconds after which an open log is
+	// removed from Redis, in case it is never closed.
+	loggingExpiry = 60 * 60 * 24
+)
+
+// errClosed is returned when the log is closed before the subscription
+// is active.
+var errClosed = errors.New("redis: log closed")
+
+// record is a log entry published to live subscribers. Index is the
+// position of the entry in the log history, used by subscribers to
+// skip entries already replayed from the history. A record without an
+// entry signals the log is closed.
+type record struct {
+	Index int64          `json:"index"`
+	Entry *logging.Entry `json:"entry,omitempty"`
+}
+
+type log struct {
+	pool *redis.Pool
+}
+
+// NewLog returns a new log multiplexer that shares log streams between
+// all servers connected to Redis.
+func NewLog(pool *redis.Pool) logging.Log {
+	return &log{pool}
+}
+
+func (l *log) Open(c context.Context, path string) error {
+	conn := l.pool.Get()
+	defer conn.Close()
+	_, err := conn.Do("SET", l.key(path, "open"), 1, "EX", loggingExpiry)
+	return err
+}
+
+func (l *log) Write(c context.Context, path string, entry *logging.Entry) error {
+	conn := l.pool.Get()
+	defer conn.Close()
+
+	if ok, err := redis.Bool(conn.Do("EXISTS", l.key(path, "open"))); err != nil {
+		return err
+	} else if !ok {
+		return logging.ErrNotFound
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	index, err := redis.Int64(conn.Do("RPUSH", l.key(path, "hist"), data))
+	if err != nil {
+		return err
+	}
+	if index == 1 {
+		conn.Do("EXPIRE", l.key(path, "hist"), loggingExpiry)
+	}
+	data, _ = json.Marshal(&record{Index: index, Entry: entry})
+	_, err = conn.Do("PUBLISH", l.key(path, "live"), data)
+	return err
+}
+
+func (l *log) Tail(c context.Context, path string, handler logging.Handler) error {
+	ok, err := l.exists(path)
+	if err != nil {
+		return err
+	} else if !ok {
+		return logging.ErrNotFound
+	}
+
+	// the history is replayed once subscribed, so that entries written
+	// in between are received live rather than lost. live entries that
+	// are also in the replayed history are skipped by index.
+	var replayed int64
+	ready := func() error {
+		if ok, err := l.exists(path); err != nil {
+			return err
+		} else if !ok {
+			return errClosed
+		}
+		entries, err := l.history(path)
+		if err != nil {
+			return err
+		}
+		replayed = int64(len(entries))
+		if len(entries) != 0 {
+			handler(entries...)
+		}
+		return nil
+	}
+	err = subscribe(c, l.pool, l.key(path, "live"), ready, func(data []byte) bool {
+		rec := new(record)
+		if err := json.Unmarshal(data, rec); err != nil {
+			return true
+		}
+		if rec.Entry == nil {
+			return false
+		}
+		if rec.Index > replayed {
+			handler(rec.Entry)
+		}
+		return true
+	})
+	if err == errClosed {
+		return nil
+	}
+	return err
+}
+
+func (l *log) Close(c context.Context, path string) error {
+	conn := l.pool.Get()
+	defer conn.Close()
+
+	if ok, err := redis.Bool(conn.Do("EXISTS", l.key(path, "open"))); err != nil {
+		return err
+	} else if !ok {
+		return logging.ErrNotFound
+	}
+	if _, err := conn.Do("DEL", l.key(path, "open"), l.key(path, "hist")); err != nil {
+		return err
+	}
+	data, _ := json.Marshal(&record{})
+	_, err := conn.Do("PUBLISH", l.key(path, "live"), data)
+	return err
+}
+
+func (l *log) Snapshot(c context.Context, path string, w io.Writer) error {
+	if ok, err := l.exists(path); err != nil {
+		return err
+	} else if !ok {
+		return logging.ErrNotFound
+	}
+	entries, err := l.history(path)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		w.Write(entry.Data)
+		w.Write(cr)
+	}
+	return nil
+}
+
+func (l *log) history(path string) ([]*logging.Entry, error) {
+	conn := l.pool.Get()
+	defer conn.Close()
+
+	values, err := redis.ByteSlices(conn.Do("LRANGE", l.key(path, "hist"), 0, -1))
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]*logging.Entry, 0, len(values))
+	for _, value := range values {
+		entry := new(logging.Entry)
+		if err := json.Unmarshal(value, entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func (l *log) exists(path string) (bool, error) {
+	conn := l.pool.Get()
+	defer conn.Close()
+	return redis.Bool(conn.Do("EXISTS", l.key(path, "open")))
+}
+
+func (l *log) key(path, kind string) string {
+	return loggingPrefix + path + ":" + kind
+}
+
+var cr = []byte{'\n'}
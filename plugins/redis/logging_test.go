@@ -0,0 +1,98 @@
+package redis
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/cncd/logging"
+)
+
+func TestLog(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mr.Close()
+
+	pool := NewPool("redis://" + mr.Addr())
+	defer pool.Close()
+
+	ctx := context.Background()
+	log := NewLog(pool)
+
+	if err := log.Write(ctx, "1", &logging.Entry{}); err != logging.ErrNotFound {
+		t.Errorf("Want error writing to a log that is not open")
+	}
+	if err := log.Open(ctx, "1"); err != nil {
+		t.Errorf("Unexpected error: open: %s", err)
+		return
+	}
+	log.Write(ctx, "1", &logging.Entry{Data: []byte("line 1")})
+	log.Write(ctx, "1", &logging.Entry{Data: []byte("line 2")})
+
+	var (
+		mu    sync.Mutex
+		lines []string
+		done  = make(chan error, 1)
+	)
+	go func() {
+		done <- log.Tail(ctx, "1", func(entries ...*logging.Entry) {
+			mu.Lock()
+			for _, entry := range entries {
+				lines = append(lines, string(entry.Data))
+			}
+			mu.Unlock()
+		})
+	}()
+
+	// wait for the history to be replayed before writing live entries.
+	for i := 0; ; i++ {
+		mu.Lock()
+		n := len(lines)
+		mu.Unlock()
+		if n == 2 {
+			break
+		}
+		if i == 100 {
+			t.Errorf("Want log history replayed to the subscriber")
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	log.Write(ctx, "1", &logging.Entry{Data: []byte("line 3")})
+
+	var buf bytes.Buffer
+	if err := log.Snapshot(ctx, "1", &buf); err != nil {
+		t.Errorf("Unexpected error: snapshot: %s", err)
+	}
+	if got, want := buf.String(), "line 1\nline 2\nline 3\n"; got != want {
+		t.Errorf("Want snapshot %q, got %q", want, got)
+	}
+
+	if err := log.Close(ctx, "1"); err != nil {
+		t.Errorf("Unexpected error: close: %s", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Unexpected error: tail: %s", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Want tail to return when the log is closed")
+		return
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got, want := len(lines), 3; got != want {
+		t.Errorf("Want %d lines tailed, got %d", want, got)
+	}
+	if err := log.Tail(ctx, "1", func(...*logging.Entry) {}); err != logging.ErrNotFound {
+		t.Errorf("Want error tailing a closed log")
+	}
+}
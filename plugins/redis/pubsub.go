@@ -0,0 +1,67 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/cncd/pubsub"
+	"github.com/gomodule/redigo/redis"
+
+	"github.com/Sirupsen/logrus"
+)
+
+const pubsubPrefix = "drone:pubsub:"
+
+type publisher struct {
+	pool *redis.Pool
+}
+
+// NewPublisher returns a new publisher that delivers messages to the
+// subscribers of all servers connected to Redis.
+func NewPublisher(pool *redis.Pool) pubsub.Publisher {
+	return &publisher{pool}
+}
+
+// Create is a no-op. Topics are created when first published.
+func (p *publisher) Create(c context.Context, topic string) error {
+	return nil
+}
+
+func (p *publisher) Publish(c context.Context, topic string, message pubsub.Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	conn := p.pool.Get()
+	defer conn.Close()
+	_, err = conn.Do("PUBLISH", pubsubPrefix+topic, data)
+	return err
+}
+
+// Subscribe subscribes to the topic until the context is cancelled,
+// reconnecting if the connection to Redis fails.
+func (p *publisher) Subscribe(c context.Context, topic string, receiver pubsub.Receiver) error {
+	for {
+		err := subscribe(c, p.pool, pubsubPrefix+topic, nil, func(data []byte) bool {
+			message := pubsub.Message{}
+			if err := json.Unmarshal(data, &message); err == nil {
+				go receiver(message)
+			}
+			return true
+		})
+		if err != nil {
+			logrus.Errorf("redis: subscription to %s failed. %s", topic, err)
+		}
+		select {
+		case <-c.Done():
+			return nil
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// Remove is a no-op. Topics do not hold state in Redis.
+func (p *publisher) Remove(c context.Context, topic string) error {
+	return nil
+}
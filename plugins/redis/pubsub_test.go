@@ -0,0 +1,62 @@
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/cncd/pubsub"
+)
+
+func TestPublisher(t *testing.T) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	defer mr.Close()
+
+	pool := NewPool("redis://" + mr.Addr())
+	defer pool.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	received := make(chan pubsub.Message, 1)
+	broker := NewPublisher(pool)
+	go broker.Subscribe(ctx, "topic/events", func(message pubsub.Message) {
+		received <- message
+	})
+
+	// wait for the subscription before publishing, since messages are
+	// not buffered for absent subscribers.
+	for i := 0; len(mr.PubSubChannels("")) == 0; i++ {
+		if i == 100 {
+			t.Errorf("Want subscription to topic")
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	message := pubsub.Message{
+		Data:   []byte("hello"),
+		Labels: map[string]string{"repo": "octocat/hello-world"},
+	}
+	if err := broker.Publish(ctx, "topic/events", message); err != nil {
+		t.Errorf("Unexpected error: publish: %s", err)
+		return
+	}
+
+	select {
+	case got := <-received:
+		if string(got.Data) != "hello" {
+			t.Errorf("Want message data hello, got %s", got.Data)
+		}
+		if got.Labels["repo"] != "octocat/hello-world" {
+			t.Errorf("Want message labels to be published")
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Want message received by subscriber")
+	}
+}
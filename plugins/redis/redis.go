@@ -0,0 +1,75 @@
+// Package redis implements the publisher and log multiplexer on top of
+// Redis, so that events and live logs are shared by multiple servers.
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// NewPool returns a new Redis connection pool for the server url, in
+// the format redis://:password@host:port/db.
+func NewPool(url string) *redis.Pool {
+	return &redis.Pool{
+		MaxIdle:     10,
+		IdleTimeout: 4 * time.Minute,
+		Dial: func() (redis.Conn, error) {
+			return redis.DialURL(url)
+		},
+		TestOnBorrow: func(conn redis.Conn, t time.Time) error {
+			if time.Since(t) < time.Minute {
+				return nil
+			}
+			_, err := conn.Do("PING")
+			return err
+		},
+	}
+}
+
+// subscribe subscribes to the channel and calls the receiver for each
+// message until the context is cancelled, the receiver returns false or
+// the connection fails. The optional ready function is called once the
+// subscription is active.
+func subscribe(ctx context.Context, pool *redis.Pool, channel string, ready func() error, receiver func([]byte) bool) error {
+	conn := pool.Get()
+	defer conn.Close()
+
+	psc := redis.PubSubConn{Conn: conn}
+	if err := psc.Subscribe(channel); err != nil {
+		return err
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		for {
+			switch v := psc.Receive().(type) {
+			case redis.Message:
+				if !receiver(v.Data) {
+					errc <- nil
+					return
+				}
+			case redis.Subscription:
+				if v.Kind == "subscribe" && ready != nil {
+					if err := ready(); err != nil {
+						errc <- err
+						return
+					}
+				}
+			case error:
+				errc <- v
+				return
+			}
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		psc.Unsubscribe()
+		return nil
+	case err := <-errc:
+		psc.Unsubscribe()
+		return err
+	}
+}
@@ -21,8 +21,38 @@ func (b *builtin) SecretList(repo *model.Repo) ([]*model.Secret, error) {
 	return b.store.SecretList(repo)
 }
 
+// SecretListBuild returns the repository secrets merged with the secrets
+// shared by the repository owner and the secrets shared globally. Secrets
+// are matched by name, repository secrets take precedence over owner
+// secrets, and owner secrets take precedence over global secrets. A secret
+// that is overridden is excluded entirely, even if the overriding secret
+// is restricted to different images or events.
 func (b *builtin) SecretListBuild(repo *model.Repo, build *model.Build) ([]*model.Secret, error) {
-	return b.store.SecretList(repo)
+	repos, err := b.store.SecretList(repo)
+	if err != nil {
+		return nil, err
+	}
+	orgs, err := b.store.OrgSecretList(repo.Owner)
+	if err != nil {
+		return nil, err
+	}
+	globals, err := b.store.OrgSecretList(model.GlobalOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	var secrets []*model.Secret
+	var names = map[string]struct{}{}
+	for _, list := range [][]*model.Secret{repos, orgs, globals} {
+		for _, secret := range list {
+			if _, ok := names[secret.Name]; ok {
+				continue
+			}
+			names[secret.Name] = struct{}{}
+			secrets = append(secrets, secret)
+		}
+	}
+	return secrets, nil
 }
 
 func (b *builtin) SecretCreate(repo *model.Repo, in *model.Secret) error {
@@ -40,3 +70,27 @@ func (b *builtin) SecretDelete(repo *model.Repo, name string) error {
 	}
 	return b.store.SecretDelete(secret)
 }
+
+func (b *builtin) OrgSecretFind(owner, name string) (*model.Secret, error) {
+	return b.store.OrgSecretFind(owner, name)
+}
+
+func (b *builtin) OrgSecretList(owner string) ([]*model.Secret, error) {
+	return b.store.OrgSecretList(owner)
+}
+
+func (b *builtin) OrgSecretCreate(owner string, in *model.Secret) error {
+	return b.store.OrgSecretCreate(in)
+}
+
+func (b *builtin) OrgSecretUpdate(owner string, in *model.Secret) error {
+	return b.store.OrgSecretUpdate(in)
+}
+
+func (b *builtin) OrgSecretDelete(owner, name string) error {
+	secret, err := b.store.OrgSecretFind(owner, name)
+	if err != nil {
+		return err
+	}
+	return b.store.OrgSecretDelete(secret)
+}
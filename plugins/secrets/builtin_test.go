@@ -1 +1,99 @@
 package secrets
+
+import (
+	"testing"
+
+	"github.com/drone/drone/model"
+)
+
+func TestSecretListBuild(t *testing.T) {
+	store := &storer{
+		repo: []*model.Secret{
+			{Name: "password", Value: "repo", Events: []string{"push"}},
+		},
+		orgs: map[string][]*model.Secret{
+			"octocat": {
+				{Name: "password", Value: "org"},
+				{Name: "docker_username", Value: "org"},
+			},
+			"spaceghost": {
+				{Name: "slack_webhook", Value: "other"},
+			},
+			model.GlobalOwner: {
+				{Name: "docker_username", Value: "global"},
+				{Name: "slack_webhook", Value: "global", Images: []string{"plugins/slack"}},
+			},
+		},
+	}
+
+	list, err := New(store).SecretListBuild(&model.Repo{Owner: "octocat"}, nil)
+	if err != nil {
+		t.Errorf("Expected merged secret list, got error %q", err)
+		return
+	}
+	if got, want := len(list), 3; got != want {
+		t.Errorf("Want %d secrets, got %d", want, got)
+		return
+	}
+
+	want := []struct {
+		name  string
+		value string
+	}{
+		{"password", "repo"},
+		{"docker_username", "org"},
+		{"slack_webhook", "global"},
+	}
+	for i, secret := range list {
+		if got, want := secret.Name, want[i].name; got != want {
+			t.Errorf("Want secret name %s, got %s", want, got)
+		}
+		if got, want := secret.Value, want[i].value; got != want {
+			t.Errorf("Want secret %s value %s, got %s", secret.Name, want, got)
+		}
+	}
+
+	// the restrictions are preserved at every level
+	if got, want := len(list[0].Events), 1; got != want {
+		t.Errorf("Want repo secret event restrictions preserved")
+	}
+	if got, want := len(list[2].Images), 1; got != want {
+		t.Errorf("Want global secret image restrictions preserved")
+	}
+}
+
+type storer struct {
+	repo []*model.Secret
+	orgs map[string][]*model.Secret
+}
+
+func (s *storer) SecretFind(*model.Repo, string) (*model.Secret, error) {
+	return nil, nil
+}
+func (s *storer) SecretList(*model.Repo) ([]*model.Secret, error) {
+	return s.repo, nil
+}
+func (s *storer) SecretCreate(*model.Secret) error {
+	return nil
+}
+func (s *storer) SecretUpdate(*model.Secret) error {
+	return nil
+}
+func (s *storer) SecretDelete(*model.Secret) error {
+	return nil
+}
+func (s *storer) OrgSecretFind(string, string) (*model.Secret, error) {
+	return nil, nil
+}
+func (s *storer) OrgSecretList(owner string) ([]*model.Secret, error) {
+	return s.orgs[owner], nil
+}
+func (s *storer) OrgSecretCreate(*model.Secret) error {
+	return nil
+}
+func (s *storer) OrgSecretUpdate(*model.Secret) error {
+	return nil
+}
+func (s *storer) OrgSecretDelete(*model.Secret) error {
+	return nil
+}
@@ -66,3 +66,18 @@ func (m *mocker) SecretUpdate(*model.Repo, *model.Secret) error {
 func (m *mocker) SecretDelete(*model.Repo, string) error {
 	return nil
 }
+func (m *mocker) OrgSecretFind(string, string) (*model.Secret, error) {
+	return nil, nil
+}
+func (m *mocker) OrgSecretList(string) ([]*model.Secret, error) {
+	return nil, nil
+}
+func (m *mocker) OrgSecretCreate(string, *model.Secret) error {
+	return nil
+}
+func (m *mocker) OrgSecretUpdate(string, *model.Secret) error {
+	return nil
+}
+func (m *mocker) OrgSecretDelete(string, string) error {
+	return nil
+}
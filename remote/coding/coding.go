@@ -248,10 +248,30 @@ func (c *Coding) FileRef(u *model.User, r *model.Repo, ref, f string) ([]byte, e
 	return data, nil
 }
 
-// Status sends the commit status to the remote system.
+// Status sends the commit status to the remote system. Coding versions
+// without the commit status api receive a comment on the pull request or
+// merge request once the build is complete instead.
 func (c *Coding) Status(u *model.User, r *model.Repo, b *model.Build, link string) error {
-	// EMPTY: not implemented in Coding OAuth API
-	return nil
+	client := c.newClient(u)
+	err := client.CreateCommitStatus(r.Owner, r.Name, b.Commit, &internal.CommitStatus{
+		State:       getStatus(b.Status),
+		TargetURL:   link,
+		Description: getDesc(b.Status),
+		Context:     statusContext,
+	})
+	if !internal.IsUnsupported(err) {
+		return err
+	}
+
+	kind, number, ok := requestNumber(b.Ref)
+	if !ok || b.Event != model.EventPull || !isComplete(b.Status) {
+		return nil
+	}
+	content := fmt.Sprintf("Build #%d: %s. %s", b.Number, getDesc(b.Status), link)
+	if kind == "merge" {
+		return client.CreateMergeComment(r.Owner, r.Name, number, content)
+	}
+	return client.CreatePullComment(r.Owner, r.Name, number, content)
 }
 
 // Netrc returns a .netrc file that can be used to clone
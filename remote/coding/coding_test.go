@@ -238,6 +238,36 @@ func Test_coding(t *testing.T) {
 			})
 		})
 
+		g.Describe("When sending the build status", func() {
+			g.It("Should create the commit status", func() {
+				err := c.Status(fakeUser, fakeRepo, fakeBuildSuccess, "http://127.0.0.1/demo1/test1/1")
+				g.Assert(err == nil).IsTrue()
+			})
+			g.It("Should comment on the merge request without a status api", func() {
+				build := *fakeBuildPull
+				build.Ref = "refs/merge/1/MERGE"
+				err := c.Status(fakeUser, fakeRepoNoStatus, &build, "http://127.0.0.1/demo1/test_no_status/1")
+				g.Assert(err == nil).IsTrue()
+			})
+			g.It("Should comment on the pull request without a status api", func() {
+				err := c.Status(fakeUser, fakeRepoNoStatus, fakeBuildPull, "http://127.0.0.1/demo1/test_no_status/1")
+				g.Assert(err == nil).IsTrue()
+			})
+			g.It("Should handle a comment error", func() {
+				build := *fakeBuildPull
+				build.Ref = "refs/pull/2/MERGE"
+				err := c.Status(fakeUser, fakeRepoNoStatus, &build, "")
+				g.Assert(err != nil).IsTrue()
+			})
+			g.It("Should not comment on running builds", func() {
+				build := *fakeBuildPull
+				build.Ref = "refs/pull/2/MERGE"
+				build.Status = model.StatusRunning
+				err := c.Status(fakeUser, fakeRepoNoStatus, &build, "")
+				g.Assert(err == nil).IsTrue()
+			})
+		})
+
 		g.Describe("When parsing post-commit hook body", func() {
 			g.It("Should parse the hook", func() {
 				buf := bytes.NewBufferString(fixtures.PushHook)
@@ -306,4 +336,24 @@ var (
 	fakeBuild = &model.Build{
 		Commit: "4504a072cc",
 	}
+
+	fakeBuildSuccess = &model.Build{
+		Number: 1,
+		Commit: "4504a072cc",
+		Status: model.StatusSuccess,
+	}
+
+	fakeBuildPull = &model.Build{
+		Number: 1,
+		Event:  model.EventPull,
+		Commit: "4504a072cc",
+		Ref:    "refs/pull/1/MERGE",
+		Status: model.StatusFailure,
+	}
+
+	fakeRepoNoStatus = &model.Repo{
+		Owner:    "demo1",
+		Name:     "test_no_status",
+		FullName: "demo1/test_no_status",
+	}
 )
@@ -36,6 +36,9 @@ func Handler() http.Handler {
 	e.POST("/api/user/:gk/project/:prj/git/hook", postHook)
 	e.PUT("/api/user/:gk/project/:prj/git/hook/:id", putHook)
 	e.DELETE("/api/user/:gk/project/:prj/git/hook/:id", deleteHook)
+	e.POST("/api/user/:gk/project/:prj/git/commit/:sha/statuses", postStatus)
+	e.POST("/api/user/:gk/project/:prj/git/pull/:iid/comment", postComment)
+	e.POST("/api/user/:gk/project/:prj/git/merge/:iid/comment", postComment)
 
 	return e
 }
@@ -130,6 +133,30 @@ func postHook(c *gin.Context) {
 	}
 }
 
+func postStatus(c *gin.Context) {
+	c.Header("Content-Type", "application/json;charset=UTF-8")
+	if c.Param("prj") == "test_no_status" {
+		c.String(404, "")
+		return
+	}
+	if c.Param("sha") != "4504a072cc" ||
+		c.PostForm("state") != "success" ||
+		c.PostForm("context") != "continuous-integration/drone" {
+		c.String(200, `{"code":1}`)
+		return
+	}
+	c.String(200, `{"code":0}`)
+}
+
+func postComment(c *gin.Context) {
+	c.Header("Content-Type", "application/json;charset=UTF-8")
+	if c.Param("iid") != "1" || c.PostForm("content") == "" {
+		c.String(200, `{"code":1}`)
+		return
+	}
+	c.String(200, `{"code":0}`)
+}
+
 func putHook(c *gin.Context) {
 	c.Header("Content-Type", "application/json;charset=UTF-8")
 	switch c.Param("id") {
@@ -77,10 +77,10 @@ func (c *Client) Do(method, u string, params url.Values) ([]byte, error) {
 	if err != nil {
 		return nil, fmt.Errorf("fail to request %s %s: %v", req.Method, req.URL, err)
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("%s %s respond %d", req.Method, req.URL, resp.StatusCode)
+		return nil, APIStatusErr{req.Method, req.URL.String(), resp.StatusCode}
 	}
-	defer resp.Body.Close()
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("fail to read response from %s %s: %v", req.Method, req.URL.String(), err)
@@ -27,3 +27,13 @@ type APIClientErr struct {
 func (e APIClientErr) Error() string {
 	return fmt.Sprintf("%s (Requested %s): %v", e.Message, e.URL, e.Cause)
 }
+
+type APIStatusErr struct {
+	Method string
+	URL    string
+	Status int
+}
+
+func (e APIStatusErr) Error() string {
+	return fmt.Sprintf("%s %s respond %d", e.Method, e.URL, e.Status)
+}
@@ -0,0 +1,70 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+type CommitStatus struct {
+	State       string
+	TargetURL   string
+	Description string
+	Context     string
+}
+
+func (c *Client) CreateCommitStatus(globalKey, projectName, sha string, status *CommitStatus) error {
+	u := fmt.Sprintf("/user/%s/project/%s/git/commit/%s/statuses", globalKey, projectName, sha)
+	params := url.Values{}
+	params.Set("state", status.State)
+	params.Set("target_url", status.TargetURL)
+	params.Set("description", status.Description)
+	params.Set("context", status.Context)
+
+	_, err := c.Do("POST", u, params)
+	return err
+}
+
+func (c *Client) CreatePullComment(globalKey, projectName string, number int, content string) error {
+	u := fmt.Sprintf("/user/%s/project/%s/git/pull/%d/comment", globalKey, projectName, number)
+	return c.createComment(u, content)
+}
+
+func (c *Client) CreateMergeComment(globalKey, projectName string, number int, content string) error {
+	u := fmt.Sprintf("/user/%s/project/%s/git/merge/%d/comment", globalKey, projectName, number)
+	return c.createComment(u, content)
+}
+
+func (c *Client) createComment(u, content string) error {
+	params := url.Values{}
+	params.Set("content", content)
+
+	_, err := c.Do("POST", u, params)
+	if err != nil {
+		return APIClientErr{"fail to create comment", u, err}
+	}
+	return nil
+}
+
+// IsUnsupported returns true if the error indicates the API endpoint is
+// not available on the Coding server.
+func IsUnsupported(err error) bool {
+	if err, ok := err.(APIStatusErr); ok {
+		return err.Status == http.StatusNotFound || err.Status == http.StatusMethodNotAllowed
+	}
+	return false
+}
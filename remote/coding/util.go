@@ -16,8 +16,83 @@ package coding
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/drone/drone/model"
+)
+
+const statusContext = "continuous-integration/drone"
+
+const (
+	descPending  = "the build is pending"
+	descRunning  = "the build is running"
+	descSuccess  = "the build was successful"
+	descFailure  = "the build failed"
+	descCanceled = "the build canceled"
+	descBlocked  = "the build is pending approval"
+	descDeclined = "the build was rejected"
 )
 
 func projectFullName(owner, name string) string {
 	return fmt.Sprintf("%s/%s", owner, name)
 }
+
+// helper function that returns the kind and number of the pull request
+// or merge request ref, eg refs/pull/1/MERGE or refs/merge/1/MERGE.
+func requestNumber(ref string) (kind string, number int, ok bool) {
+	parts := strings.Split(ref, "/")
+	if len(parts) != 4 || (parts[1] != "pull" && parts[1] != "merge") {
+		return "", 0, false
+	}
+	number, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return "", 0, false
+	}
+	return parts[1], number, true
+}
+
+// helper function that returns true if the build status is final.
+func isComplete(status string) bool {
+	switch status {
+	case model.StatusSuccess, model.StatusFailure, model.StatusError, model.StatusKilled:
+		return true
+	default:
+		return false
+	}
+}
+
+// helper function that converts a Drone status to a Coding status.
+func getStatus(status string) string {
+	switch status {
+	case model.StatusPending, model.StatusBlocked, model.StatusRunning:
+		return "pending"
+	case model.StatusSuccess:
+		return "success"
+	case model.StatusFailure:
+		return "failure"
+	default:
+		return "error"
+	}
+}
+
+// helper function that generates a description message for the build
+// based on the status.
+func getDesc(status string) string {
+	switch status {
+	case model.StatusPending:
+		return descPending
+	case model.StatusRunning:
+		return descRunning
+	case model.StatusSuccess:
+		return descSuccess
+	case model.StatusKilled:
+		return descCanceled
+	case model.StatusBlocked:
+		return descBlocked
+	case model.StatusDeclined:
+		return descDeclined
+	default:
+		return descFailure
+	}
+}
@@ -28,5 +28,20 @@ func Test_util(t *testing.T) {
 		g.It("Should form project full name", func() {
 			g.Assert(projectFullName("gk", "prj")).Equal("gk/prj")
 		})
+
+		g.It("Should parse pull request and merge request refs", func() {
+			kind, number, ok := requestNumber("refs/merge/12/MERGE")
+			g.Assert(ok).IsTrue()
+			g.Assert(kind).Equal("merge")
+			g.Assert(number).Equal(12)
+
+			kind, number, ok = requestNumber("refs/pull/3/MERGE")
+			g.Assert(ok).IsTrue()
+			g.Assert(kind).Equal("pull")
+			g.Assert(number).Equal(3)
+
+			_, _, ok = requestNumber("refs/heads/master")
+			g.Assert(ok).IsFalse()
+		})
 	})
 }
@@ -0,0 +1,193 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fixtures
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler returns an http.Handler that is capable of handling a variety of mock
+// Gerrit requests and returning mock responses.
+func Handler() http.Handler {
+	gin.SetMode(gin.TestMode)
+
+	e := gin.New()
+	e.Use(authorize)
+	e.GET("/a/accounts/self", getAccount)
+	e.GET("/a/projects/*path", getProject)
+	e.POST("/a/changes/:change/revisions/:revision/review", createReview)
+	e.PUT("/a/config/server/webhooks~projects/*path", createWebhook)
+	e.DELETE("/a/config/server/webhooks~projects/*path", deleteWebhook)
+
+	return e
+}
+
+// authorize accepts the HTTP password of the octocat account, or the
+// OAuth access token of the octocat account.
+func authorize(c *gin.Context) {
+	if c.Request.Header.Get("Authorization") == "Bearer d7e1c3a2" {
+		return
+	}
+	username, password, ok := c.Request.BasicAuth()
+	if !ok || username != "octocat" || password != "cfcd2084" {
+		c.String(401, "Unauthorized")
+		c.Abort()
+	}
+}
+
+func getAccount(c *gin.Context) {
+	c.String(200, accountPayload)
+}
+
+// getProject handles the project endpoints. Gerrit expects the project
+// name url-encoded, therefore the routes are matched by the escaped path.
+func getProject(c *gin.Context) {
+	path := strings.TrimPrefix(c.Request.URL.EscapedPath(), "/a/projects/")
+	switch {
+	case path == "":
+		c.String(200, projectsPayload)
+	case path == "test_name%2Frepo_name":
+		c.String(200, projectPayload)
+	case path == "test_name%2Frepo_name/HEAD":
+		c.String(200, headPayload)
+	case path == "test_name%2Frepo_name/access":
+		c.String(200, accessPayload)
+	case path == "test_name%2Frepo_name/commits/9ecad50/files/.drone.yml/content",
+		path == "test_name%2Frepo_name/branches/release%2F1.0/files/.drone.yml/content":
+		c.String(200, filePayload)
+	default:
+		c.String(404, "Not found: "+path)
+	}
+}
+
+func createReview(c *gin.Context) {
+	in := struct {
+		Tag    string         `json:"tag"`
+		Labels map[string]int `json:"labels"`
+	}{}
+	c.BindJSON(&in)
+	if c.Param("change") != "12345" ||
+		c.Param("revision") != "3" ||
+		in.Tag != "autogenerated:drone" ||
+		in.Labels["Verified"] != 1 {
+		c.String(400, "")
+		return
+	}
+	c.String(200, reviewPayload)
+}
+
+func createWebhook(c *gin.Context) {
+	in := struct {
+		URL    string   `json:"url"`
+		Events []string `json:"events"`
+	}{}
+	c.BindJSON(&in)
+	if c.Request.URL.EscapedPath() != "/a/config/server/webhooks~projects/test_name%2Frepo_name/remotes/drone" ||
+		in.URL != "http://localhost" ||
+		len(in.Events) != 2 {
+		c.String(400, "")
+		return
+	}
+	c.String(200, "")
+}
+
+func deleteWebhook(c *gin.Context) {
+	c.Status(204)
+}
+
+const accountPayload = `)]}'
+{
+  "_account_id": 1000096,
+  "name": "The Octocat",
+  "email": "octocat@github.com",
+  "username": "octocat",
+  "avatars": [
+    {
+      "url": "https://gerrit.example.com/avatars/octocat-26.png",
+      "height": 26
+    }
+  ]
+}
+`
+
+const projectsPayload = `)]}'
+{
+  "All-Projects": {
+    "id": "All-Projects",
+    "state": "ACTIVE"
+  },
+  "All-Users": {
+    "id": "All-Users",
+    "state": "ACTIVE"
+  },
+  "test_name/repo_name": {
+    "id": "test_name%2Frepo_name",
+    "state": "ACTIVE"
+  },
+  "test_name/hidden": {
+    "id": "test_name%2Fhidden",
+    "state": "HIDDEN"
+  },
+  "test_name/nested/repo_name": {
+    "id": "test_name%2Fnested%2Frepo_name",
+    "state": "ACTIVE"
+  },
+  "tools": {
+    "id": "tools",
+    "state": "ACTIVE"
+  }
+}
+`
+
+const projectPayload = `)]}'
+{
+  "id": "test_name%2Frepo_name",
+  "name": "test_name/repo_name",
+  "parent": "All-Projects",
+  "description": "Hello World",
+  "state": "ACTIVE"
+}
+`
+
+const headPayload = `)]}'
+"refs/heads/develop"
+`
+
+const accessPayload = `)]}'
+{
+  "revision": "61157ed63e14d261b6dca40650472a9b0bd88474",
+  "inherits_from": {
+    "id": "All-Projects",
+    "name": "All-Projects"
+  },
+  "is_owner": true,
+  "can_upload": true,
+  "can_add": false,
+  "config_visible": true
+}
+`
+
+const filePayload = `eyBwbGF0Zm9ybTogbGludXgvYW1kNjQgfQ==`
+
+const reviewPayload = `)]}'
+{
+  "labels": {
+    "Verified": 1
+  }
+}
+`
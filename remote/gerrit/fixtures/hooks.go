@@ -0,0 +1,153 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fixtures
+
+// HookPatchSetCreated is a patchset-created event.
+const HookPatchSetCreated = `
+{
+  "uploader": {
+    "name": "The Octocat",
+    "email": "octocat@github.com",
+    "username": "octocat"
+  },
+  "patchSet": {
+    "number": 3,
+    "revision": "d2a0b5d1ec14e2f2b5e2b4e1cb8a3e4dfa4fcf2c",
+    "parents": [
+      "c2f6e5cd4a2c0b7f0d3ec7e1fa1b1b4bd4e3e8e1"
+    ],
+    "ref": "refs/changes/45/12345/3",
+    "uploader": {
+      "name": "The Octocat",
+      "email": "octocat@github.com",
+      "username": "octocat"
+    },
+    "createdOn": 1520000000,
+    "author": {
+      "name": "The Octocat",
+      "email": "octocat@github.com",
+      "username": "octocat"
+    },
+    "kind": "REWORK",
+    "sizeInsertions": 12,
+    "sizeDeletions": -3
+  },
+  "change": {
+    "project": "test_name/repo_name",
+    "branch": "master",
+    "id": "I8473b95934b5732ac55d26311a706c9c2bde9940",
+    "number": 12345,
+    "subject": "Update the README",
+    "owner": {
+      "name": "The Octocat",
+      "email": "octocat@github.com",
+      "username": "octocat"
+    },
+    "url": "https://gerrit.example.com/c/test_name/repo_name/+/12345",
+    "commitMessage": "Update the README\n\nChange-Id: I8473b95934b5732ac55d26311a706c9c2bde9940\n",
+    "createdOn": 1519990000,
+    "status": "NEW"
+  },
+  "project": "test_name/repo_name",
+  "refName": "refs/heads/master",
+  "changeKey": {
+    "id": "I8473b95934b5732ac55d26311a706c9c2bde9940"
+  },
+  "type": "patchset-created",
+  "eventCreatedOn": 1520000001
+}
+`
+
+// HookPatchSetNoCode is a patchset-created event for a patch set that
+// only changes the commit message.
+const HookPatchSetNoCode = `
+{
+  "uploader": {
+    "name": "The Octocat",
+    "email": "octocat@github.com",
+    "username": "octocat"
+  },
+  "patchSet": {
+    "number": "4",
+    "revision": "e2a0b5d1ec14e2f2b5e2b4e1cb8a3e4dfa4fcf2c",
+    "ref": "refs/changes/45/12345/4",
+    "kind": "NO_CODE_CHANGE"
+  },
+  "change": {
+    "project": "test_name/repo_name",
+    "branch": "master",
+    "number": "12345",
+    "subject": "Update the README"
+  },
+  "project": "test_name/repo_name",
+  "type": "patchset-created",
+  "eventCreatedOn": 1520000002
+}
+`
+
+// HookChangeMerged is a change-merged event.
+const HookChangeMerged = `
+{
+  "submitter": {
+    "name": "Monalisa",
+    "email": "monalisa@github.com",
+    "username": "monalisa"
+  },
+  "newRev": "f1d7e7fba5ad1e3e2cb8a3e4dfa4fcf2cd2a0b5d",
+  "patchSet": {
+    "number": 3,
+    "revision": "d2a0b5d1ec14e2f2b5e2b4e1cb8a3e4dfa4fcf2c",
+    "ref": "refs/changes/45/12345/3",
+    "author": {
+      "name": "The Octocat",
+      "email": "octocat@github.com",
+      "username": "octocat"
+    },
+    "kind": "REWORK"
+  },
+  "change": {
+    "project": "test_name/repo_name",
+    "branch": "master",
+    "id": "I8473b95934b5732ac55d26311a706c9c2bde9940",
+    "number": 12345,
+    "subject": "Update the README",
+    "url": "https://gerrit.example.com/c/test_name/repo_name/+/12345",
+    "commitMessage": "Update the README\n\nChange-Id: I8473b95934b5732ac55d26311a706c9c2bde9940\n",
+    "status": "MERGED"
+  },
+  "project": "test_name/repo_name",
+  "refName": "refs/heads/master",
+  "type": "change-merged",
+  "eventCreatedOn": 1520000100
+}
+`
+
+// HookCommentAdded is an unsupported comment-added event.
+const HookCommentAdded = `
+{
+  "author": {
+    "name": "Monalisa",
+    "username": "monalisa"
+  },
+  "change": {
+    "project": "test_name/repo_name",
+    "branch": "master"
+  },
+  "project": "test_name/repo_name",
+  "comment": "Looks good to me",
+  "type": "comment-added",
+  "eventCreatedOn": 1520000050
+}
+`
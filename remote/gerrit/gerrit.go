@@ -15,23 +15,34 @@
 package gerrit
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net"
 	"net/http"
 	"net/url"
+	"regexp"
+	"sort"
+	"strings"
 
 	"github.com/drone/drone/model"
 	"github.com/drone/drone/remote"
+	"github.com/drone/drone/remote/gerrit/internal"
 )
 
-// IMPORTANT Gerrit support is not yet implemented. This file is a placeholder
-// for future implementation.
+// name of the remote registered with the Gerrit webhooks plugin.
+const webhookName = "drone"
+
+// tag of the review messages, which Gerrit uses to separate automated
+// messages from human comments.
+const reviewTag = "autogenerated:drone"
 
 // Opts defines configuration options.
 type Opts struct {
 	URL         string // Gerrit server url.
 	Username    string // Optional machine account username.
 	Password    string // Optional machine account password.
+	Label       string // Label voted on with the build status.
+	OAuth       bool   // Authenticate with OAuth access tokens.
 	PrivateMode bool   // Gerrit is running in private mode.
 	SkipVerify  bool   // Skip ssl verification.
 }
@@ -41,11 +52,13 @@ type client struct {
 	Machine     string
 	Username    string
 	Password    string
+	Label       string
+	OAuth       bool
 	PrivateMode bool
 	SkipVerify  bool
 }
 
-// New returns a Remote implementation that integrates with Getter, an open
+// New returns a Remote implementation that integrates with Gerrit, an open
 // source Git hosting service and code review system.
 func New(opts Opts) (remote.Remote, error) {
 	url, err := url.Parse(opts.URL)
@@ -56,25 +69,61 @@ func New(opts Opts) (remote.Remote, error) {
 	if err == nil {
 		url.Host = host
 	}
+	label := opts.Label
+	if label == "" {
+		label = "Verified"
+	}
 	return &client{
-		URL:         opts.URL,
+		URL:         strings.TrimSuffix(opts.URL, "/"),
 		Machine:     url.Host,
 		Username:    opts.Username,
 		Password:    opts.Password,
+		Label:       label,
+		OAuth:       opts.OAuth,
 		PrivateMode: opts.PrivateMode,
 		SkipVerify:  opts.SkipVerify,
 	}, nil
 }
 
-// Login authenticates an account with Gerrit using oauth authentication. The
-// Gerrit account details are returned when the user is successfully authenticated.
+// Login authenticates an account with Gerrit using the HTTP password of
+// the account, or an OAuth access token when OAuth is enabled. The Gerrit
+// account details are returned when the user is successfully authenticated.
 func (c *client) Login(res http.ResponseWriter, req *http.Request) (*model.User, error) {
-	return nil, nil
+	var (
+		username = req.FormValue("username")
+		password = req.FormValue("password")
+	)
+
+	// if the username or password is empty we re-direct to the login screen.
+	if (len(username) == 0 && !c.OAuth) || len(password) == 0 {
+		http.Redirect(res, req, "/login/form", http.StatusSeeOther)
+		return nil, nil
+	}
+
+	account, err := c.newClient(username, password).FindCurrent()
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.User{
+		Login:  account.Username,
+		Token:  password,
+		Email:  account.Email,
+		Avatar: toAvatar(account),
+	}, nil
 }
 
-// Auth is not supported by the Gerrit driver.
+// Auth authenticates the OAuth access token and returns the Gerrit
+// username. It is not supported with HTTP password authentication.
 func (c *client) Auth(token, secret string) (string, error) {
-	return "", fmt.Errorf("Not Implemented")
+	if !c.OAuth {
+		return "", fmt.Errorf("Not Implemented")
+	}
+	account, err := c.newClient("", token).FindCurrent()
+	if err != nil {
+		return "", err
+	}
+	return account.Username, nil
 }
 
 // Teams is not supported by the Gerrit driver.
@@ -83,52 +132,172 @@ func (c *client) Teams(u *model.User) ([]*model.Team, error) {
 	return empty, nil
 }
 
-// Repo is not supported by the Gerrit driver.
+// Repo returns the named Gerrit project.
 func (c *client) Repo(u *model.User, owner, name string) (*model.Repo, error) {
-	return nil, nil
+	client := c.newClientUser(u)
+	project := projectName(owner, name)
+	if _, err := client.FindProject(project); err != nil {
+		return nil, err
+	}
+	repo := toRepo(project, c.URL, c.PrivateMode)
+	if head, err := client.FindHead(project); err == nil {
+		repo.Branch = strings.TrimPrefix(head, "refs/heads/")
+	}
+	return repo, nil
 }
 
-// Repos is not supported by the Gerrit driver.
+// Repos returns a list of all Gerrit projects visible to the account,
+// excluding the projects that hold the Gerrit configuration and projects
+// nested more than one folder deep.
 func (c *client) Repos(u *model.User) ([]*model.Repo, error) {
-	return nil, nil
+	repos := []*model.Repo{}
+
+	projects, err := c.newClientUser(u).ListProjects()
+	if err != nil {
+		return repos, err
+	}
+
+	var names []string
+	for name, project := range projects {
+		switch {
+		case name == "All-Projects", name == "All-Users":
+		case project.State == "HIDDEN":
+		case strings.Count(name, "/") > 1:
+		default:
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		repos = append(repos, toRepo(name, c.URL, c.PrivateMode))
+	}
+	return repos, nil
 }
 
-// Perm is not supported by the Gerrit driver.
+// Perm returns the user permissions for the named Gerrit project.
 func (c *client) Perm(u *model.User, owner, name string) (*model.Perm, error) {
-	return nil, nil
+	access, err := c.newClientUser(u).FindAccess(projectName(owner, name))
+	if err != nil {
+		return nil, err
+	}
+	return toPerm(access), nil
 }
 
-// File is not supported by the Gerrit driver.
+// File fetches the file from the Gerrit project at the build revision and
+// returns its contents.
 func (c *client) File(u *model.User, r *model.Repo, b *model.Build, f string) ([]byte, error) {
-	return nil, nil
+	if b.Commit == "" {
+		return c.FileRef(u, r, b.Ref, f)
+	}
+	return c.newClientUser(u).FindFileCommit(projectName(r.Owner, r.Name), b.Commit, f)
 }
 
-// File is not supported by the Gerrit driver.
+// FileRef fetches the file from the Gerrit project at the commit or branch
+// and returns its contents.
 func (c *client) FileRef(u *model.User, r *model.Repo, ref, f string) ([]byte, error) {
-	return nil, nil
+	client := c.newClientUser(u)
+	project := projectName(r.Owner, r.Name)
+	if isCommit(ref) {
+		return client.FindFileCommit(project, ref, f)
+	}
+	return client.FindFileBranch(project, strings.TrimPrefix(ref, "refs/heads/"), f)
 }
 
-// Status is not supported by the Gogs driver.
+// Status votes on the label of the change with the build status once the
+// build is complete. The vote is cast by the machine account when
+// configured. Builds that are not triggered by a change are ignored.
 func (c *client) Status(u *model.User, r *model.Repo, b *model.Build, link string) error {
-	return nil
+	if b.Event != model.EventPull {
+		return nil
+	}
+	change, patchSet, ok := changeFromRef(b.Ref)
+	if !ok {
+		return nil
+	}
+	vote, ok := toVote(b.Status)
+	if !ok {
+		return nil
+	}
+
+	client := c.newClientUser(u)
+	if c.Password != "" {
+		client = c.newClient(c.Username, c.Password)
+	}
+	return client.CreateReview(change, patchSet, &internal.ReviewInput{
+		Message: toMessage(b, link),
+		Tag:     reviewTag,
+		Labels:  map[string]int{c.Label: vote},
+	})
 }
 
-// Netrc is not supported by the Gerrit driver.
+// Netrc returns a netrc file capable of authenticating Gerrit requests and
+// cloning Gerrit projects. The netrc will use the global machine account
+// when configured.
 func (c *client) Netrc(u *model.User, r *model.Repo) (*model.Netrc, error) {
-	return nil, nil
+	if c.Password != "" {
+		return &model.Netrc{
+			Login:    c.Username,
+			Password: c.Password,
+			Machine:  c.Machine,
+		}, nil
+	}
+	return &model.Netrc{
+		Login:    u.Login,
+		Password: u.Token,
+		Machine:  c.Machine,
+	}, nil
 }
 
-// Activate is not supported by the Gerrit driver.
+// Activate activates the project by registering a remote with the Gerrit
+// webhooks plugin.
 func (c *client) Activate(u *model.User, r *model.Repo, link string) error {
-	return nil
+	return c.newClientUser(u).CreateWebhook(
+		projectName(r.Owner, r.Name),
+		webhookName,
+		&internal.Webhook{
+			URL:       link,
+			Events:    []string{hookPatchSetCreated, hookChangeMerged},
+			SSLVerify: !c.SkipVerify,
+		},
+	)
 }
 
-// Deactivate is not supported by the Gogs driver.
+// Deactivate removes the remote from the Gerrit webhooks plugin.
 func (c *client) Deactivate(u *model.User, r *model.Repo, link string) error {
-	return nil
+	return c.newClientUser(u).DeleteWebhook(projectName(r.Owner, r.Name), webhookName)
 }
 
-// Hook is not supported by the Gerrit driver.
+// Hook parses the incoming Gerrit event and returns the Repository and
+// Build details. Events are posted by the Gerrit webhooks plugin, or by any
+// relay of the stream-events command. If the event is unsupported nil
+// values are returned.
 func (c *client) Hook(r *http.Request) (*model.Repo, *model.Build, error) {
-	return nil, nil, nil
+	return parseHook(r)
+}
+
+// helper function to return the Gerrit client for the user.
+func (c *client) newClientUser(u *model.User) *internal.Client {
+	return c.newClient(u.Login, u.Token)
+}
+
+// helper function to return the Gerrit client
+func (c *client) newClient(username, password string) *internal.Client {
+	httpClient := &http.Client{}
+	if c.SkipVerify {
+		httpClient.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}
+	}
+	if c.OAuth {
+		return internal.NewClientToken(c.URL, password, httpClient)
+	}
+	return internal.NewClient(c.URL, username, password, httpClient)
+}
+
+var commitRegexp = regexp.MustCompile("^[0-9a-f]{40}$")
+
+// helper function that returns true if the ref is a commit sha.
+func isCommit(ref string) bool {
+	return commitRegexp.MatchString(ref)
 }
@@ -0,0 +1,266 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gerrit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/drone/drone/model"
+	"github.com/drone/drone/remote/gerrit/fixtures"
+
+	"github.com/franela/goblin"
+	"github.com/gin-gonic/gin"
+)
+
+func Test_gerrit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(fixtures.Handler())
+	c, _ := New(Opts{
+		URL:        s.URL,
+		SkipVerify: true,
+	})
+
+	g := goblin.Goblin(t)
+	g.Describe("Gerrit", func() {
+
+		g.After(func() {
+			s.Close()
+		})
+
+		g.Describe("Creating a remote", func() {
+			g.It("Should return client with specified options", func() {
+				remote, _ := New(Opts{
+					URL:         "http://localhost:8080/",
+					Username:    "someuser",
+					Password:    "password",
+					SkipVerify:  true,
+					PrivateMode: true,
+				})
+				g.Assert(remote.(*client).URL).Equal("http://localhost:8080")
+				g.Assert(remote.(*client).Machine).Equal("localhost")
+				g.Assert(remote.(*client).Username).Equal("someuser")
+				g.Assert(remote.(*client).Password).Equal("password")
+				g.Assert(remote.(*client).Label).Equal("Verified")
+				g.Assert(remote.(*client).SkipVerify).Equal(true)
+				g.Assert(remote.(*client).PrivateMode).Equal(true)
+			})
+			g.It("Should handle malformed url", func() {
+				_, err := New(Opts{URL: "%gh&%ij"})
+				g.Assert(err != nil).IsTrue()
+			})
+		})
+
+		g.Describe("Generating a netrc file", func() {
+			g.It("Should return a netrc with the user password", func() {
+				remote, _ := New(Opts{
+					URL: "http://gerrit.example.com",
+				})
+				netrc, _ := remote.Netrc(fakeUser, nil)
+				g.Assert(netrc.Machine).Equal("gerrit.example.com")
+				g.Assert(netrc.Login).Equal(fakeUser.Login)
+				g.Assert(netrc.Password).Equal(fakeUser.Token)
+			})
+			g.It("Should return a netrc with the machine account", func() {
+				remote, _ := New(Opts{
+					URL:      "http://gerrit.example.com",
+					Username: "someuser",
+					Password: "password",
+				})
+				netrc, _ := remote.Netrc(nil, nil)
+				g.Assert(netrc.Machine).Equal("gerrit.example.com")
+				g.Assert(netrc.Login).Equal("someuser")
+				g.Assert(netrc.Password).Equal("password")
+			})
+		})
+
+		g.Describe("Given an authentication request", func() {
+			g.It("Should redirect to login form", func() {
+				w := httptest.NewRecorder()
+				r, _ := http.NewRequest("GET", "/login", nil)
+				u, err := c.Login(w, r)
+				g.Assert(u == nil).IsTrue()
+				g.Assert(err == nil).IsTrue()
+				g.Assert(w.Code).Equal(http.StatusSeeOther)
+			})
+			g.It("Should return the authenticated user", func() {
+				u, err := c.Login(httptest.NewRecorder(), loginRequest("octocat", "cfcd2084"))
+				g.Assert(err == nil).IsTrue()
+				g.Assert(u.Login).Equal("octocat")
+				g.Assert(u.Token).Equal("cfcd2084")
+				g.Assert(u.Email).Equal("octocat@github.com")
+				g.Assert(u.Avatar).Equal("https://gerrit.example.com/avatars/octocat-26.png")
+			})
+			g.It("Should handle invalid credentials", func() {
+				_, err := c.Login(httptest.NewRecorder(), loginRequest("octocat", "invalid"))
+				g.Assert(err != nil).IsTrue()
+			})
+			g.It("Should return the user authenticated with an access token", func() {
+				remote, _ := New(Opts{URL: s.URL, OAuth: true})
+				u, err := remote.Login(httptest.NewRecorder(), loginRequest("", "d7e1c3a2"))
+				g.Assert(err == nil).IsTrue()
+				g.Assert(u.Login).Equal("octocat")
+
+				login, err := remote.Auth("d7e1c3a2", "")
+				g.Assert(err == nil).IsTrue()
+				g.Assert(login).Equal("octocat")
+			})
+		})
+
+		g.Describe("Requesting a repository", func() {
+			g.It("Should return the repository details", func() {
+				repo, err := c.Repo(fakeUser, fakeRepo.Owner, fakeRepo.Name)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(repo.Owner).Equal(fakeRepo.Owner)
+				g.Assert(repo.Name).Equal(fakeRepo.Name)
+				g.Assert(repo.FullName).Equal(fakeRepo.FullName)
+				g.Assert(repo.Branch).Equal("develop")
+				g.Assert(repo.Clone).Equal(s.URL + "/test_name/repo_name")
+			})
+			g.It("Should handle a not found error", func() {
+				_, err := c.Repo(fakeUser, fakeRepoNotFound.Owner, fakeRepoNotFound.Name)
+				g.Assert(err != nil).IsTrue()
+			})
+		})
+
+		g.Describe("Requesting repository permissions", func() {
+			g.It("Should return the permission details", func() {
+				perm, err := c.Perm(fakeUser, fakeRepo.Owner, fakeRepo.Name)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(perm.Admin).IsTrue()
+				g.Assert(perm.Push).IsTrue()
+				g.Assert(perm.Pull).IsTrue()
+			})
+			g.It("Should handle a not found error", func() {
+				_, err := c.Perm(fakeUser, fakeRepoNotFound.Owner, fakeRepoNotFound.Name)
+				g.Assert(err != nil).IsTrue()
+			})
+		})
+
+		g.Describe("Requesting a repository list", func() {
+			g.It("Should return the repository list", func() {
+				repos, err := c.Repos(fakeUser)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(len(repos)).Equal(2)
+				g.Assert(repos[0].FullName).Equal("test_name/repo_name")
+				g.Assert(repos[1].FullName).Equal("$gerrit/tools")
+			})
+			g.It("Should handle an authentication error", func() {
+				_, err := c.Repos(fakeUserInvalid)
+				g.Assert(err != nil).IsTrue()
+			})
+		})
+
+		g.It("Should register repository hooks", func() {
+			err := c.Activate(fakeUser, fakeRepo, "http://localhost")
+			g.Assert(err == nil).IsTrue()
+		})
+
+		g.It("Should remove repository hooks", func() {
+			err := c.Deactivate(fakeUser, fakeRepo, "http://localhost")
+			g.Assert(err == nil).IsTrue()
+		})
+
+		g.It("Should return a repository file", func() {
+			raw, err := c.File(fakeUser, fakeRepo, fakeBuild, ".drone.yml")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(string(raw)).Equal("{ platform: linux/amd64 }")
+		})
+
+		g.It("Should return a repository file from a branch", func() {
+			raw, err := c.FileRef(fakeUser, fakeRepo, "refs/heads/release/1.0", ".drone.yml")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(string(raw)).Equal("{ platform: linux/amd64 }")
+		})
+
+		g.Describe("Voting the build status", func() {
+			g.It("Should vote on the change", func() {
+				build := &model.Build{
+					Number: 1,
+					Event:  model.EventPull,
+					Ref:    "refs/changes/45/12345/3",
+					Status: model.StatusSuccess,
+				}
+				err := c.Status(fakeUser, fakeRepo, build, "http://localhost/test_name/repo_name/1")
+				g.Assert(err == nil).IsTrue()
+			})
+			g.It("Should not vote on running builds", func() {
+				build := &model.Build{
+					Event:  model.EventPull,
+					Ref:    "refs/changes/45/12345/3",
+					Status: model.StatusRunning,
+				}
+				err := c.Status(fakeUser, fakeRepo, build, "")
+				g.Assert(err == nil).IsTrue()
+			})
+			g.It("Should ignore builds without a change", func() {
+				build := &model.Build{
+					Event:  model.EventPush,
+					Ref:    "refs/heads/master",
+					Status: model.StatusFailure,
+				}
+				err := c.Status(fakeUser, fakeRepo, build, "")
+				g.Assert(err == nil).IsTrue()
+			})
+		})
+
+		g.It("Should return no teams", func() {
+			teams, err := c.Teams(fakeUser)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(teams)).Equal(0)
+		})
+	})
+}
+
+func loginRequest(username, password string) *http.Request {
+	form := url.Values{}
+	form.Set("username", username)
+	form.Set("password", password)
+	r, _ := http.NewRequest("POST", "/login", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+var (
+	fakeUser = &model.User{
+		Login: "octocat",
+		Token: "cfcd2084",
+	}
+
+	fakeUserInvalid = &model.User{
+		Login: "octocat",
+		Token: "invalid",
+	}
+
+	fakeRepo = &model.Repo{
+		Owner:    "test_name",
+		Name:     "repo_name",
+		FullName: "test_name/repo_name",
+	}
+
+	fakeRepoNotFound = &model.Repo{
+		Owner:    "test_name",
+		Name:     "repo_not_found",
+		FullName: "test_name/repo_not_found",
+	}
+
+	fakeBuild = &model.Build{
+		Commit: "9ecad50",
+	}
+)
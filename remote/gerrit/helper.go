@@ -0,0 +1,212 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gerrit
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/drone/drone/model"
+	"github.com/drone/drone/remote/gerrit/internal"
+)
+
+// rootOwner is the repository owner of top-level Gerrit projects. Gerrit
+// project names are hierarchical, the first path segment of a nested
+// project is used as the repository owner. Gerrit does not allow $ in
+// project names, so the root owner cannot collide with a project folder.
+const rootOwner = "$gerrit"
+
+// helper function that splits a Gerrit project name into the repository
+// owner and name. Projects nested more than one folder deep cannot be
+// represented as a repository and are reported as not ok.
+func splitProject(project string) (owner, name string, ok bool) {
+	parts := strings.Split(project, "/")
+	switch len(parts) {
+	case 1:
+		return rootOwner, project, true
+	case 2:
+		return parts[0], parts[1], true
+	default:
+		return "", "", false
+	}
+}
+
+// helper function that returns the Gerrit project name of the repository
+// owner and name.
+func projectName(owner, name string) string {
+	if owner == rootOwner {
+		return name
+	}
+	return owner + "/" + name
+}
+
+// helper function that converts a Gerrit project to a Drone repository.
+func toRepo(project, link string, privateMode bool) *model.Repo {
+	owner, name, _ := splitProject(project)
+	clone := fmt.Sprintf("%s/%s", link, project)
+	if privateMode {
+		clone = fmt.Sprintf("%s/a/%s", link, project)
+	}
+	return &model.Repo{
+		Kind:      model.RepoGit,
+		Owner:     owner,
+		Name:      name,
+		FullName:  owner + "/" + name,
+		Link:      fmt.Sprintf("%s/admin/repos/%s", link, project),
+		Clone:     clone,
+		IsPrivate: privateMode,
+		Branch:    "master",
+	}
+}
+
+// helper function that converts Gerrit project access rights to a Drone
+// permission.
+func toPerm(from *internal.ProjectAccess) *model.Perm {
+	return &model.Perm{
+		Pull:  true,
+		Push:  from.CanUpload,
+		Admin: from.IsOwner,
+	}
+}
+
+// helper function that returns the avatar url of the Gerrit account, or
+// an empty string if no avatar plugin is installed.
+func toAvatar(from *internal.Account) string {
+	if len(from.Avatars) == 0 {
+		return ""
+	}
+	return from.Avatars[len(from.Avatars)-1].URL
+}
+
+// helper function that extracts the Repo data from a Gerrit event, or
+// returns nil if the project cannot be represented as a repository.
+func repoFromEvent(from *event) *model.Repo {
+	project := from.Project
+	if project == "" {
+		project = from.Change.Project
+	}
+	owner, name, ok := splitProject(project)
+	if !ok {
+		return nil
+	}
+	return &model.Repo{
+		Kind:     model.RepoGit,
+		Owner:    owner,
+		Name:     name,
+		FullName: owner + "/" + name,
+	}
+}
+
+// helper function that extracts the Build data from a Gerrit
+// patchset-created event.
+func buildFromPatchSet(from *event) *model.Build {
+	author := from.PatchSet.Uploader
+	if author.Username == "" {
+		author = from.Uploader
+	}
+	return &model.Build{
+		Event:     model.EventPull,
+		Commit:    from.PatchSet.Revision,
+		Ref:       from.PatchSet.Ref,
+		Refspec:   fmt.Sprintf("%s:%s", from.PatchSet.Ref, from.Change.Branch),
+		Branch:    from.Change.Branch,
+		Link:      from.Change.URL,
+		Title:     from.Change.Subject,
+		Message:   changeMessage(from),
+		Author:    author.Username,
+		Email:     author.Email,
+		Sender:    from.Uploader.Username,
+		Timestamp: eventTime(from),
+	}
+}
+
+// helper function that extracts the Build data from a Gerrit
+// change-merged event.
+func buildFromMerged(from *event) *model.Build {
+	commit := from.NewRev
+	if commit == "" {
+		commit = from.PatchSet.Revision
+	}
+	return &model.Build{
+		Event:     model.EventPush,
+		Commit:    commit,
+		Ref:       fmt.Sprintf("refs/heads/%s", from.Change.Branch),
+		Branch:    from.Change.Branch,
+		Link:      from.Change.URL,
+		Message:   changeMessage(from),
+		Author:    from.PatchSet.Author.Username,
+		Email:     from.PatchSet.Author.Email,
+		Sender:    from.Submitter.Username,
+		Timestamp: eventTime(from),
+	}
+}
+
+// helper function that returns the commit message of the change, or the
+// change subject if the event omits the commit message.
+func changeMessage(from *event) string {
+	if from.Change.CommitMessage != "" {
+		return from.Change.CommitMessage
+	}
+	return from.Change.Subject
+}
+
+// helper function that returns the event time, or the current time if
+// the event omits it.
+func eventTime(from *event) int64 {
+	if from.EventCreatedOn != 0 {
+		return from.EventCreatedOn
+	}
+	return time.Now().UTC().Unix()
+}
+
+// helper function that returns the change and patch set numbers of a
+// Gerrit change ref, eg refs/changes/45/12345/3.
+func changeFromRef(ref string) (change, patchSet string, ok bool) {
+	parts := strings.Split(ref, "/")
+	if len(parts) != 5 || parts[0] != "refs" || parts[1] != "changes" {
+		return "", "", false
+	}
+	return parts[3], parts[4], true
+}
+
+// helper function that returns the Verified label vote for the build
+// status. Builds that are not complete do not vote.
+func toVote(status string) (int, bool) {
+	switch status {
+	case model.StatusSuccess:
+		return 1, true
+	case model.StatusFailure, model.StatusError, model.StatusKilled:
+		return -1, true
+	default:
+		return 0, false
+	}
+}
+
+// helper function that returns the review message for the build status.
+func toMessage(build *model.Build, link string) string {
+	var desc string
+	switch build.Status {
+	case model.StatusSuccess:
+		desc = "succeeded"
+	case model.StatusFailure:
+		desc = "failed"
+	case model.StatusKilled:
+		desc = "was killed"
+	default:
+		desc = "errored"
+	}
+	return fmt.Sprintf("Build #%d %s. %s", build.Number, desc, link)
+}
@@ -0,0 +1,95 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gerrit
+
+import (
+	"testing"
+
+	"github.com/drone/drone/model"
+	"github.com/drone/drone/remote/gerrit/internal"
+
+	"github.com/franela/goblin"
+)
+
+func Test_helper(t *testing.T) {
+
+	g := goblin.Goblin(t)
+	g.Describe("Gerrit", func() {
+
+		g.It("Should split project names", func() {
+			owner, name, ok := splitProject("platform/tools")
+			g.Assert(ok).IsTrue()
+			g.Assert(owner).Equal("platform")
+			g.Assert(name).Equal("tools")
+			g.Assert(projectName(owner, name)).Equal("platform/tools")
+
+			owner, name, ok = splitProject("tools")
+			g.Assert(ok).IsTrue()
+			g.Assert(owner).Equal(rootOwner)
+			g.Assert(name).Equal("tools")
+			g.Assert(projectName(owner, name)).Equal("tools")
+
+			owner, name, _ = splitProject("gerrit/tools")
+			g.Assert(projectName(owner, name)).Equal("gerrit/tools")
+
+			_, _, ok = splitProject("platform/tools/cli")
+			g.Assert(ok).IsFalse()
+		})
+
+		g.It("Should return a Repo struct from a Gerrit project", func() {
+			repo := toRepo("platform/tools", "https://gerrit.example.com", true)
+			g.Assert(repo.Kind).Equal(model.RepoGit)
+			g.Assert(repo.Owner).Equal("platform")
+			g.Assert(repo.Name).Equal("tools")
+			g.Assert(repo.FullName).Equal("platform/tools")
+			g.Assert(repo.Link).Equal("https://gerrit.example.com/admin/repos/platform/tools")
+			g.Assert(repo.Clone).Equal("https://gerrit.example.com/a/platform/tools")
+			g.Assert(repo.IsPrivate).IsTrue()
+		})
+
+		g.It("Should return a Perm struct from Gerrit access rights", func() {
+			perm := toPerm(&internal.ProjectAccess{IsOwner: false, CanUpload: true})
+			g.Assert(perm.Pull).IsTrue()
+			g.Assert(perm.Push).IsTrue()
+			g.Assert(perm.Admin).IsFalse()
+		})
+
+		g.It("Should parse change refs", func() {
+			change, patchSet, ok := changeFromRef("refs/changes/45/12345/3")
+			g.Assert(ok).IsTrue()
+			g.Assert(change).Equal("12345")
+			g.Assert(patchSet).Equal("3")
+
+			_, _, ok = changeFromRef("refs/heads/master")
+			g.Assert(ok).IsFalse()
+		})
+
+		g.It("Should vote the build status", func() {
+			vote, ok := toVote(model.StatusSuccess)
+			g.Assert(ok).IsTrue()
+			g.Assert(vote).Equal(1)
+			vote, ok = toVote(model.StatusFailure)
+			g.Assert(ok).IsTrue()
+			g.Assert(vote).Equal(-1)
+			_, ok = toVote(model.StatusPending)
+			g.Assert(ok).IsFalse()
+		})
+
+		g.It("Should return the review message", func() {
+			build := &model.Build{Number: 7, Status: model.StatusFailure}
+			g.Assert(toMessage(build, "http://drone/7")).Equal("Build #7 failed. http://drone/7")
+		})
+	})
+}
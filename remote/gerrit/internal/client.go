@@ -0,0 +1,202 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+)
+
+const (
+	get  = "GET"
+	put  = "PUT"
+	post = "POST"
+	del  = "DELETE"
+)
+
+const (
+	pathSelf        = "%s/a/accounts/self"
+	pathProjects    = "%s/a/projects/?d"
+	pathProject     = "%s/a/projects/%s"
+	pathProjectHead = "%s/a/projects/%s/HEAD"
+	pathAccess      = "%s/a/projects/%s/access"
+	pathFileCommit  = "%s/a/projects/%s/commits/%s/files/%s/content"
+	pathFileBranch  = "%s/a/projects/%s/branches/%s/files/%s/content"
+	pathReview      = "%s/a/changes/%s/revisions/%s/review"
+	pathWebhook     = "%s/a/config/server/webhooks~projects/%s/remotes/%s"
+)
+
+// magic prefix that Gerrit prepends to JSON responses to prevent
+// cross-site script inclusion.
+var magicPrefix = []byte(")]}'")
+
+type Client struct {
+	*http.Client
+	base     string
+	username string
+	password string
+	bearer   bool
+}
+
+// NewClient returns a client that authenticates with the Gerrit HTTP
+// password of the account.
+func NewClient(url, username, password string, client *http.Client) *Client {
+	return &Client{Client: client, base: url, username: username, password: password}
+}
+
+// NewClientToken returns a client that authenticates with an OAuth
+// access token.
+func NewClientToken(url, token string, client *http.Client) *Client {
+	return &Client{Client: client, base: url, password: token, bearer: true}
+}
+
+func (c *Client) FindCurrent() (*Account, error) {
+	out := new(Account)
+	uri := fmt.Sprintf(pathSelf, c.base)
+	err := c.do(uri, get, nil, out)
+	return out, err
+}
+
+func (c *Client) ListProjects() (map[string]*Project, error) {
+	out := map[string]*Project{}
+	uri := fmt.Sprintf(pathProjects, c.base)
+	err := c.do(uri, get, nil, &out)
+	return out, err
+}
+
+func (c *Client) FindProject(name string) (*Project, error) {
+	out := new(Project)
+	uri := fmt.Sprintf(pathProject, c.base, url.PathEscape(name))
+	err := c.do(uri, get, nil, out)
+	return out, err
+}
+
+func (c *Client) FindHead(name string) (string, error) {
+	var out string
+	uri := fmt.Sprintf(pathProjectHead, c.base, url.PathEscape(name))
+	err := c.do(uri, get, nil, &out)
+	return out, err
+}
+
+func (c *Client) FindAccess(name string) (*ProjectAccess, error) {
+	out := new(ProjectAccess)
+	uri := fmt.Sprintf(pathAccess, c.base, url.PathEscape(name))
+	err := c.do(uri, get, nil, out)
+	return out, err
+}
+
+func (c *Client) FindFileCommit(project, commit, path string) ([]byte, error) {
+	uri := fmt.Sprintf(pathFileCommit, c.base, url.PathEscape(project), commit, url.PathEscape(path))
+	return c.content(uri)
+}
+
+func (c *Client) FindFileBranch(project, branch, path string) ([]byte, error) {
+	uri := fmt.Sprintf(pathFileBranch, c.base, url.PathEscape(project), url.PathEscape(branch), url.PathEscape(path))
+	return c.content(uri)
+}
+
+func (c *Client) CreateReview(change, revision string, review *ReviewInput) error {
+	uri := fmt.Sprintf(pathReview, c.base, change, revision)
+	return c.do(uri, post, review, nil)
+}
+
+func (c *Client) CreateWebhook(project, name string, hook *Webhook) error {
+	uri := fmt.Sprintf(pathWebhook, c.base, url.PathEscape(project), name)
+	return c.do(uri, put, hook, nil)
+}
+
+func (c *Client) DeleteWebhook(project, name string) error {
+	uri := fmt.Sprintf(pathWebhook, c.base, url.PathEscape(project), name)
+	return c.do(uri, del, nil, nil)
+}
+
+// helper function to fetch file content, which Gerrit returns base64
+// encoded.
+func (c *Client) content(rawurl string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := c.do(rawurl, get, nil, &buf); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(buf.String())
+}
+
+func (c *Client) do(rawurl, method string, in, out interface{}) error {
+
+	uri, err := url.Parse(rawurl)
+	if err != nil {
+		return err
+	}
+
+	// if we are posting or putting data, we need to
+	// write it to the body of the request.
+	var buf io.ReadWriter
+	if in != nil {
+		buf = new(bytes.Buffer)
+		err := json.NewEncoder(buf).Encode(in)
+		if err != nil {
+			return err
+		}
+	}
+
+	// creates a new http request to gerrit.
+	req, err := http.NewRequest(method, uri.String(), buf)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.bearer {
+		req.Header.Set("Authorization", "Bearer "+c.password)
+	} else {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	// gerrit returns plain text error messages.
+	if resp.StatusCode > http.StatusNoContent {
+		return Error{
+			Status: resp.StatusCode,
+			Body:   string(bytes.TrimSpace(body)),
+		}
+	}
+
+	switch out := out.(type) {
+	case nil:
+		return nil
+	case *bytes.Buffer:
+		_, err := out.Write(body)
+		return err
+	default:
+		body = bytes.TrimPrefix(body, magicPrefix)
+		return json.Unmarshal(body, out)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package internal
+
+import "fmt"
+
+type Account struct {
+	ID       int64     `json:"_account_id"`
+	Name     string    `json:"name"`
+	Email    string    `json:"email"`
+	Username string    `json:"username"`
+	Avatars  []*Avatar `json:"avatars"`
+}
+
+type Avatar struct {
+	URL    string `json:"url"`
+	Height int    `json:"height"`
+}
+
+type Project struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Parent      string `json:"parent"`
+	Description string `json:"description"`
+	State       string `json:"state"`
+}
+
+type ProjectAccess struct {
+	IsOwner   bool `json:"is_owner"`
+	CanUpload bool `json:"can_upload"`
+	CanAdd    bool `json:"can_add"`
+}
+
+type ReviewInput struct {
+	Message string         `json:"message,omitempty"`
+	Tag     string         `json:"tag,omitempty"`
+	Labels  map[string]int `json:"labels,omitempty"`
+	Notify  string         `json:"notify,omitempty"`
+}
+
+type Webhook struct {
+	URL       string   `json:"url"`
+	Events    []string `json:"events"`
+	SSLVerify bool     `json:"ssl_verify"`
+}
+
+type Error struct {
+	Status int
+	Body   string
+}
+
+func (e Error) Error() string {
+	return fmt.Sprintf("gerrit: %d: %s", e.Status, e.Body)
+}
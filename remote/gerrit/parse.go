@@ -0,0 +1,58 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gerrit
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/drone/drone/model"
+)
+
+const (
+	hookPatchSetCreated = "patchset-created"
+	hookChangeMerged    = "change-merged"
+
+	kindNoChange = "NO_CHANGE"
+	kindNoCode   = "NO_CODE_CHANGE"
+)
+
+// parseHook parses a Gerrit event from an http.Request request and returns
+// Repo and Build detail. If the event type is unsupported nil values are
+// returned.
+func parseHook(r *http.Request) (*model.Repo, *model.Build, error) {
+	from := new(event)
+	if err := json.NewDecoder(r.Body).Decode(from); err != nil {
+		return nil, nil, err
+	}
+
+	repo := repoFromEvent(from)
+	if repo == nil {
+		return nil, nil, nil
+	}
+
+	switch from.Type {
+	case hookPatchSetCreated:
+		// don't trigger builds for patch sets that do not change
+		// the code, such as commit message edits.
+		if from.PatchSet.Kind == kindNoChange || from.PatchSet.Kind == kindNoCode {
+			return nil, nil, nil
+		}
+		return repo, buildFromPatchSet(from), nil
+	case hookChangeMerged:
+		return repo, buildFromMerged(from), nil
+	}
+	return nil, nil, nil
+}
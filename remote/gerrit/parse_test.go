@@ -0,0 +1,102 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gerrit
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/drone/drone/model"
+	"github.com/drone/drone/remote/gerrit/fixtures"
+
+	"github.com/franela/goblin"
+)
+
+func Test_parse(t *testing.T) {
+
+	g := goblin.Goblin(t)
+	g.Describe("Gerrit", func() {
+
+		g.It("Should parse patchset-created events", func() {
+			buf := bytes.NewBufferString(fixtures.HookPatchSetCreated)
+			req, _ := http.NewRequest("POST", "/hook", buf)
+			repo, build, err := parseHook(req)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(repo.Owner).Equal("test_name")
+			g.Assert(repo.Name).Equal("repo_name")
+			g.Assert(repo.FullName).Equal("test_name/repo_name")
+			g.Assert(build.Event).Equal(model.EventPull)
+			g.Assert(build.Commit).Equal("d2a0b5d1ec14e2f2b5e2b4e1cb8a3e4dfa4fcf2c")
+			g.Assert(build.Ref).Equal("refs/changes/45/12345/3")
+			g.Assert(build.Refspec).Equal("refs/changes/45/12345/3:master")
+			g.Assert(build.Branch).Equal("master")
+			g.Assert(build.Title).Equal("Update the README")
+			g.Assert(build.Author).Equal("octocat")
+			g.Assert(build.Email).Equal("octocat@github.com")
+			g.Assert(build.Link).Equal("https://gerrit.example.com/c/test_name/repo_name/+/12345")
+			g.Assert(build.Timestamp).Equal(int64(1520000001))
+		})
+
+		g.It("Should ignore patch sets without code changes", func() {
+			buf := bytes.NewBufferString(fixtures.HookPatchSetNoCode)
+			req, _ := http.NewRequest("POST", "/hook", buf)
+			repo, build, err := parseHook(req)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(repo == nil).IsTrue()
+			g.Assert(build == nil).IsTrue()
+		})
+
+		g.It("Should parse change-merged events", func() {
+			buf := bytes.NewBufferString(fixtures.HookChangeMerged)
+			req, _ := http.NewRequest("POST", "/hook", buf)
+			repo, build, err := parseHook(req)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(repo.FullName).Equal("test_name/repo_name")
+			g.Assert(build.Event).Equal(model.EventPush)
+			g.Assert(build.Commit).Equal("f1d7e7fba5ad1e3e2cb8a3e4dfa4fcf2cd2a0b5d")
+			g.Assert(build.Ref).Equal("refs/heads/master")
+			g.Assert(build.Branch).Equal("master")
+			g.Assert(build.Author).Equal("octocat")
+			g.Assert(build.Sender).Equal("monalisa")
+		})
+
+		g.It("Should ignore events of nested projects", func() {
+			payload := strings.Replace(fixtures.HookChangeMerged, "test_name/repo_name", "test_name/nested/repo_name", -1)
+			req, _ := http.NewRequest("POST", "/hook", bytes.NewBufferString(payload))
+			repo, build, err := parseHook(req)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(repo == nil).IsTrue()
+			g.Assert(build == nil).IsTrue()
+		})
+
+		g.It("Should ignore unsupported events", func() {
+			buf := bytes.NewBufferString(fixtures.HookCommentAdded)
+			req, _ := http.NewRequest("POST", "/hook", buf)
+			repo, build, err := parseHook(req)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(repo == nil).IsTrue()
+			g.Assert(build == nil).IsTrue()
+		})
+
+		g.It("Should handle malformed events", func() {
+			buf := bytes.NewBufferString("[]")
+			req, _ := http.NewRequest("POST", "/hook", buf)
+			_, _, err := parseHook(req)
+			g.Assert(err != nil).IsTrue()
+		})
+	})
+}
@@ -0,0 +1,57 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gerrit
+
+// event is the payload of a Gerrit stream event. The webhooks plugin
+// posts the same payload to the registered remotes. Change and patch set
+// numbers are parsed from the patch set ref, since older Gerrit versions
+// encode them as strings.
+type event struct {
+	Type           string   `json:"type"`
+	Project        string   `json:"project"`
+	RefName        string   `json:"refName"`
+	NewRev         string   `json:"newRev"`
+	EventCreatedOn int64    `json:"eventCreatedOn"`
+	Uploader       account  `json:"uploader"`
+	Submitter      account  `json:"submitter"`
+	Change         change   `json:"change"`
+	PatchSet       patchSet `json:"patchSet"`
+}
+
+type account struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Username string `json:"username"`
+}
+
+type change struct {
+	Project       string  `json:"project"`
+	Branch        string  `json:"branch"`
+	ID            string  `json:"id"`
+	Subject       string  `json:"subject"`
+	Owner         account `json:"owner"`
+	URL           string  `json:"url"`
+	CommitMessage string  `json:"commitMessage"`
+	Status        string  `json:"status"`
+}
+
+type patchSet struct {
+	Revision  string  `json:"revision"`
+	Ref       string  `json:"ref"`
+	Uploader  account `json:"uploader"`
+	Author    account `json:"author"`
+	CreatedOn int64   `json:"createdOn"`
+	Kind      string  `json:"kind"`
+}
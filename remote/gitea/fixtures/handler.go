@@ -28,6 +28,7 @@ func Handler() http.Handler {
 	e := gin.New()
 	e.GET("/api/v1/repos/:owner/:name", getRepo)
 	e.GET("/api/v1/repos/:owner/:name/raw/:commit/:file", getRepoFile)
+	e.GET("/api/v1/repos/:owner/:name/branches/:branch", getRepoBranch)
 	e.POST("/api/v1/repos/:owner/:name/hooks", createRepoHook)
 	e.GET("/api/v1/repos/:owner/:name/hooks", listRepoHooks)
 	e.DELETE("/api/v1/repos/:owner/:name/hooks/:id", deleteRepoHook)
@@ -67,6 +68,15 @@ func getRepoFile(c *gin.Context) {
 	c.String(404, "")
 }
 
+func getRepoBranch(c *gin.Context) {
+	switch c.Param("branch") {
+	case "master":
+		c.String(200, repoBranchPayload)
+	default:
+		c.String(404, "")
+	}
+}
+
 func createRepoHook(c *gin.Context) {
 	in := struct {
 		Type string `json:"type"`
@@ -153,3 +163,19 @@ const userRepoPayload = `
   }
 ]
 `
+
+const repoBranchPayload = `
+{
+  "name": "master",
+  "commit": {
+    "id": "9ecad50",
+    "message": "Update README.md",
+    "url": "http://localhost/test_name/repo_name/commit/9ecad50",
+    "author": {
+      "name": "Jane Doe",
+      "email": "jane@example.com",
+      "username": "jane"
+    }
+  }
+}
+`
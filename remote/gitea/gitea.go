@@ -249,6 +249,15 @@ func (c *client) File(u *model.User, r *model.Repo, b *model.Build, f string) ([
 	return cfg, err
 }
 
+// BranchHead returns the head commit of the Gitea repository branch.
+func (c *client) BranchHead(u *model.User, r *model.Repo, branch string) (*model.Commit, error) {
+	b, err := c.newClientToken(u.Token).GetRepoBranch(r.Owner, r.Name, branch)
+	if err != nil {
+		return nil, err
+	}
+	return commitFromBranch(b), nil
+}
+
 // FileRef fetches the file from the Gitea repository and returns its contents.
 func (c *client) FileRef(u *model.User, r *model.Repo, ref, f string) ([]byte, error) {
 	return c.newClientToken(u.Token).GetFile(r.Owner, r.Name, ref, f)
@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	"github.com/drone/drone/model"
+	"github.com/drone/drone/remote"
 	"github.com/drone/drone/remote/gitea/fixtures"
 	"github.com/franela/goblin"
 	"github.com/gin-gonic/gin"
@@ -142,6 +143,22 @@ func Test_gitea(t *testing.T) {
 			g.Assert(err == nil).IsTrue()
 		})
 
+		g.Describe("Requesting the head of a branch", func() {
+			g.It("Should return the head commit", func() {
+				commit, err := c.(remote.Brancher).BranchHead(fakeUser, fakeRepo, "master")
+				g.Assert(err == nil).IsTrue()
+				g.Assert(commit.Sha).Equal("9ecad50")
+				g.Assert(commit.Message).Equal("Update README.md")
+				g.Assert(commit.Author).Equal("jane")
+				g.Assert(commit.Email).Equal("jane@example.com")
+				g.Assert(commit.Link).Equal("http://localhost/test_name/repo_name/commit/9ecad50")
+			})
+			g.It("Should handle a not found error", func() {
+				_, err := c.(remote.Brancher).BranchHead(fakeUser, fakeRepo, "branch_not_found")
+				g.Assert(err != nil).IsTrue()
+			})
+		})
+
 		g.It("Should return a repository file", func() {
 			raw, err := c.File(fakeUser, fakeRepo, fakeBuild, ".drone.yml")
 			g.Assert(err == nil).IsTrue()
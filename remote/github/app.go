@@ -0,0 +1,199 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"crypto/rsa"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/drone/drone/model"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/google/go-github/github"
+)
+
+const (
+	pathInstallation = "repos/%s/%s/installation"
+	pathAccessTokens = "app/installations/%d/access_tokens"
+)
+
+// appAccept is the media type required by the github app api.
+const appAccept = "application/vnd.github.machine-man-preview+json"
+
+// appTokenExpiry is the minimum remaining lifetime of a cached
+// installation token before a new token is requested.
+const appTokenExpiry = time.Minute
+
+// appTimeout is the timeout of requests authenticated as the GitHub App.
+const appTimeout = time.Second * 30
+
+// app authenticates as a GitHub App installation, decoupling repository
+// access from the oauth token of the user that activated the repository.
+type app struct {
+	sync.Mutex
+
+	id  int64
+	key *rsa.PrivateKey
+
+	installs map[string]int64    // installation ids by repository
+	tokens   map[int64]*appToken // installation tokens by installation id
+}
+
+type appToken struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type appInstallation struct {
+	ID int64 `json:"id"`
+}
+
+func newApp(id int64, key *rsa.PrivateKey) *app {
+	return &app{
+		id:       id,
+		key:      key,
+		installs: map[string]int64{},
+		tokens:   map[int64]*appToken{},
+	}
+}
+
+// AppAuth returns true if repositories are accessed as a GitHub App
+// installation.
+func (c *client) AppAuth() bool {
+	return c.app != nil
+}
+
+// newClientRepo returns a GitHub client authorized to access the repository.
+// In GitHub App mode the client uses an installation token, otherwise it uses
+// the oauth token of the user.
+func (c *client) newClientRepo(u *model.User, r *model.Repo) (*github.Client, error) {
+	token, err := c.repoToken(u, r)
+	if err != nil {
+		return nil, err
+	}
+	return c.newClientToken(token), nil
+}
+
+// helper function to return the token used to access the repository.
+func (c *client) repoToken(u *model.User, r *model.Repo) (string, error) {
+	if c.app == nil {
+		return u.Token, nil
+	}
+	return c.installationToken(r)
+}
+
+// helper function to return an installation token for the repository,
+// requesting a new token if the cached token is about to expire. The lock
+// is not held while requesting a token, so concurrent requests for the
+// same installation may each request a token.
+func (c *client) installationToken(r *model.Repo) (string, error) {
+	c.app.Lock()
+	id, ok := c.app.installs[r.FullName]
+	token := c.app.tokens[id]
+	c.app.Unlock()
+
+	if ok && token != nil && time.Until(token.ExpiresAt) > appTokenExpiry {
+		return token.Token, nil
+	}
+
+	client, err := c.newClientApp()
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		in := new(appInstallation)
+		uri := fmt.Sprintf(pathInstallation, r.Owner, r.Name)
+		if err := previewRequest(client, "GET", uri, appAccept, nil, in); err != nil {
+			return "", err
+		}
+		id = in.ID
+	}
+
+	token = new(appToken)
+	uri := fmt.Sprintf(pathAccessTokens, id)
+	if err := previewRequest(client, "POST", uri, appAccept, nil, token); err != nil {
+		// the app was uninstalled or reinstalled with a new installation
+		// id, so the installation is looked up again on the next request.
+		if isStatus(err, http.StatusUnauthorized, http.StatusNotFound) {
+			c.app.Lock()
+			delete(c.app.installs, r.FullName)
+			delete(c.app.tokens, id)
+			c.app.Unlock()
+		}
+		return "", err
+	}
+
+	c.app.Lock()
+	c.app.installs[r.FullName] = id
+	c.app.tokens[id] = token
+	c.app.Unlock()
+	return token.Token, nil
+}
+
+// helper function returns true if the error is an api error response with
+// one of the given status codes.
+func isStatus(err error, codes ...int) bool {
+	res, ok := err.(*github.ErrorResponse)
+	if !ok || res.Response == nil {
+		return false
+	}
+	for _, code := range codes {
+		if res.Response.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// helper function to return a GitHub client authenticated as the GitHub App.
+func (c *client) newClientApp() (*github.Client, error) {
+	token, err := c.app.sign()
+	if err != nil {
+		return nil, err
+	}
+	return c.newClientTimeout(token, appTimeout), nil
+}
+
+// helper function to load the GitHub App private key from the file, or from
+// the key string if no file is provided.
+func loadAppKey(file, key string) (*rsa.PrivateKey, error) {
+	data := []byte(key)
+	if file != "" {
+		var err error
+		data, err = ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("github app private key is required")
+	}
+	return jwt.ParseRSAPrivateKeyFromPEM(data)
+}
+
+// sign returns a json web token, signed with the private key of the GitHub
+// App, that authenticates requests for installation tokens.
+func (a *app) sign() (string, error) {
+	now := time.Now()
+	token := jwt.New(jwt.SigningMethodRS256)
+	token.Claims["iat"] = now.Add(-time.Minute).Unix() // allow for clock drift
+	token.Claims["exp"] = now.Add(time.Minute * 9).Unix()
+	token.Claims["iss"] = a.id
+	return token.SignedString(a.key)
+}
@@ -0,0 +1,140 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/drone/model"
+	"github.com/drone/drone/remote"
+	"github.com/drone/drone/remote/github/fixtures"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/franela/goblin"
+	"github.com/gin-gonic/gin"
+)
+
+func Test_app(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	key, _ := rsa.GenerateKey(rand.Reader, 1024)
+	pemKey := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	})
+
+	s := httptest.NewServer(fixtures.Handler())
+	c, _ := New(Opts{
+		URL:          s.URL,
+		Context:      "continuous-integration/drone",
+		AppID:        1,
+		AppKeyString: string(pemKey),
+	})
+
+	g := goblin.Goblin(t)
+	g.Describe("GitHub App", func() {
+
+		g.After(func() {
+			s.Close()
+		})
+
+		g.Describe("Creating a remote", func() {
+			g.It("Should require a private key", func() {
+				_, err := New(Opts{URL: s.URL, AppID: 1})
+				g.Assert(err != nil).IsTrue()
+			})
+			g.It("Should handle a malformed private key", func() {
+				_, err := New(Opts{URL: s.URL, AppID: 1, AppKeyString: "malformed"})
+				g.Assert(err != nil).IsTrue()
+			})
+			g.It("Should handle a missing private key file", func() {
+				_, err := New(Opts{URL: s.URL, AppID: 1, AppKey: "/does/not/exist.pem"})
+				g.Assert(err != nil).IsTrue()
+			})
+		})
+
+		g.It("Should sign a json web token", func() {
+			raw, err := c.(*client).app.sign()
+			g.Assert(err == nil).IsTrue()
+
+			token, err := jwt.Parse(raw, func(*jwt.Token) (interface{}, error) {
+				return &key.PublicKey, nil
+			})
+			g.Assert(err == nil).IsTrue()
+			g.Assert(token.Valid).IsTrue()
+			g.Assert(token.Claims["iss"]).Equal(float64(1))
+		})
+
+		g.Describe("Generating a netrc file", func() {
+			g.It("Should return a netrc with the installation token", func() {
+				netrc, err := c.Netrc(fakeUserRevoked, fakeRepo)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(netrc.Login).Equal("x-access-token")
+				g.Assert(netrc.Password).Equal("v1.1f699f1069f60xxx")
+				g.Assert(netrc.Machine).Equal("127.0.0.1")
+			})
+			g.It("Should cache the installation token", func() {
+				app := c.(*client).app
+				g.Assert(app.installs[fakeRepo.FullName]).Equal(int64(1))
+				g.Assert(app.tokens[1].Token).Equal("v1.1f699f1069f60xxx")
+			})
+			g.It("Should drop a stale installation", func() {
+				app := c.(*client).app
+				app.installs[fakeRepo.FullName] = 2
+				_, err := c.Netrc(fakeUserRevoked, fakeRepo)
+				g.Assert(err != nil).IsTrue()
+				_, ok := app.installs[fakeRepo.FullName]
+				g.Assert(ok).IsFalse()
+
+				netrc, err := c.Netrc(fakeUserRevoked, fakeRepo)
+				g.Assert(err == nil).IsTrue()
+				g.Assert(netrc.Password).Equal("v1.1f699f1069f60xxx")
+				g.Assert(app.installs[fakeRepo.FullName]).Equal(int64(1))
+			})
+			g.It("Should handle a missing installation", func() {
+				_, err := c.Netrc(fakeUserRevoked, fakeRepoNotFound)
+				g.Assert(err != nil).IsTrue()
+			})
+		})
+
+		g.It("Should report app authentication", func() {
+			g.Assert(c.(remote.AppAuther).AppAuth()).IsTrue()
+			r, _ := New(Opts{URL: s.URL})
+			g.Assert(r.(remote.AppAuther).AppAuth()).IsFalse()
+		})
+
+		g.Describe("Sending the build status", func() {
+			g.It("Should use the installation token", func() {
+				err := c.Status(fakeUserRevoked, fakeRepo, fakeBuildPush, "http://127.0.0.1/octocat/Hello-World/1")
+				g.Assert(err == nil).IsTrue()
+			})
+			g.It("Should use the user token without an app", func() {
+				r, _ := New(Opts{URL: s.URL, Context: "continuous-integration/drone"})
+				err := r.Status(fakeUserRevoked, fakeRepo, fakeBuildPush, "http://127.0.0.1/octocat/Hello-World/1")
+				g.Assert(err != nil).IsTrue()
+			})
+		})
+	})
+}
+
+var fakeUserRevoked = &model.User{
+	Login: "octocat",
+	Token: "revoked",
+}
@@ -0,0 +1,297 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/drone/drone/model"
+
+	"github.com/google/go-github/github"
+)
+
+const (
+	pathCheckRuns   = "repos/%s/%s/check-runs"
+	pathCheckRun    = "repos/%s/%s/check-runs/%d"
+	pathCheckRunRef = "repos/%s/%s/commits/%s/check-runs?check_name=%s"
+)
+
+// checksAccept is the media type required by the checks api.
+const checksAccept = "application/vnd.github.antiope-preview+json"
+
+// maxAnnotations is the maximum number of annotations accepted by the
+// checks api per request.
+const maxAnnotations = 50
+
+const (
+	checkQueued     = "queued"
+	checkInProgress = "in_progress"
+	checkCompleted  = "completed"
+)
+
+const (
+	conclusionSuccess   = "success"
+	conclusionFailure   = "failure"
+	conclusionCancelled = "cancelled"
+	conclusionNeutral   = "neutral"
+)
+
+type checkRun struct {
+	ID          int64        `json:"id,omitempty"`
+	Name        string       `json:"name,omitempty"`
+	HeadSHA     string       `json:"head_sha,omitempty"`
+	ExternalID  string       `json:"external_id,omitempty"`
+	DetailsURL  string       `json:"details_url,omitempty"`
+	Status      string       `json:"status,omitempty"`
+	Conclusion  string       `json:"conclusion,omitempty"`
+	StartedAt   string       `json:"started_at,omitempty"`
+	CompletedAt string       `json:"completed_at,omitempty"`
+	Output      *checkOutput `json:"output,omitempty"`
+}
+
+type checkOutput struct {
+	Title       string             `json:"title"`
+	Summary     string             `json:"summary"`
+	Annotations []*checkAnnotation `json:"annotations,omitempty"`
+}
+
+type checkAnnotation struct {
+	Path            string `json:"path"`
+	StartLine       int    `json:"start_line"`
+	EndLine         int    `json:"end_line"`
+	AnnotationLevel string `json:"annotation_level"`
+	Title           string `json:"title,omitempty"`
+	Message         string `json:"message"`
+}
+
+type checkRunList struct {
+	CheckRuns []*checkRun `json:"check_runs"`
+}
+
+// checkClient extends the GitHub client to report pipelines as check runs.
+// Check runs can only be created by a GitHub App, so the client accesses
+// repositories with an installation token.
+type checkClient struct {
+	*client
+
+	sync.Mutex
+	runs map[string]int64 // check run ids of running pipelines by proc id
+}
+
+func newCheckClient(client *client) *checkClient {
+	return &checkClient{
+		client: client,
+		runs:   map[string]int64{},
+	}
+}
+
+// Check creates or updates the check run of the pipeline, attaching the
+// pipeline steps and annotations.
+func (c *checkClient) Check(u *model.User, r *model.Repo, b *model.Build, p *model.Proc, annotations []*model.Annotation, link string) error {
+	if b.Event == model.EventDeploy {
+		return nil
+	}
+	client, err := c.newClientRepo(u, r)
+	if err != nil {
+		return err
+	}
+
+	run := convertCheckRun(b, p, link)
+	run.Name = statusContext(c.Context, b) + "/" + p.Axis()
+
+	// the check run id is cached while the pipeline is running, so the
+	// check run is only looked up once per server.
+	c.Lock()
+	id, ok := c.runs[run.ExternalID]
+	c.Unlock()
+
+	var prev *checkRun
+	if ok {
+		prev = &checkRun{ID: id}
+	} else {
+		prev, err = findCheckRun(client, r, b.Commit, run.Name, run.ExternalID)
+		if err != nil {
+			return err
+		}
+	}
+
+	// the checks api limits the number of annotations per request,
+	// so additional annotations are sent in subsequent updates.
+	pending := convertAnnotations(annotations)
+	for {
+		n := len(pending)
+		if n > maxAnnotations {
+			n = maxAnnotations
+		}
+		run.Output.Annotations, pending = pending[:n], pending[n:]
+
+		if prev == nil {
+			prev, err = createCheckRun(client, r, run)
+		} else {
+			prev, err = updateCheckRun(client, r, prev.ID, run)
+		}
+		if err != nil {
+			c.forget(run.ExternalID)
+			return err
+		}
+		if len(pending) == 0 {
+			break
+		}
+	}
+
+	if run.Status == checkCompleted {
+		c.forget(run.ExternalID)
+	} else {
+		c.Lock()
+		c.runs[run.ExternalID] = prev.ID
+		c.Unlock()
+	}
+	return nil
+}
+
+// helper function to remove the cached check run id of the pipeline.
+func (c *checkClient) forget(id string) {
+	c.Lock()
+	delete(c.runs, id)
+	c.Unlock()
+}
+
+// helper function to find the check run of the pipeline.
+func findCheckRun(client *github.Client, r *model.Repo, sha, name, id string) (*checkRun, error) {
+	uri := fmt.Sprintf(pathCheckRunRef, r.Owner, r.Name, sha, url.QueryEscape(name))
+	out := new(checkRunList)
+	if err := previewRequest(client, "GET", uri, checksAccept, nil, out); err != nil {
+		return nil, err
+	}
+	for _, run := range out.CheckRuns {
+		if run.ExternalID == id {
+			return run, nil
+		}
+	}
+	return nil, nil
+}
+
+// helper function to create the check run.
+func createCheckRun(client *github.Client, r *model.Repo, run *checkRun) (*checkRun, error) {
+	uri := fmt.Sprintf(pathCheckRuns, r.Owner, r.Name)
+	out := new(checkRun)
+	err := previewRequest(client, "POST", uri, checksAccept, run, out)
+	return out, err
+}
+
+// helper function to update the check run.
+func updateCheckRun(client *github.Client, r *model.Repo, id int64, run *checkRun) (*checkRun, error) {
+	uri := fmt.Sprintf(pathCheckRun, r.Owner, r.Name, id)
+	out := new(checkRun)
+	err := previewRequest(client, "PATCH", uri, checksAccept, run, out)
+	return out, err
+}
+
+// convertCheckRun is a helper function used to convert a Drone pipeline
+// to a GitHub check run.
+func convertCheckRun(b *model.Build, p *model.Proc, link string) *checkRun {
+	run := &checkRun{
+		HeadSHA:    b.Commit,
+		ExternalID: strconv.FormatInt(p.ID, 10),
+		DetailsURL: link,
+		Status:     convertCheckStatus(p.State),
+		Output: &checkOutput{
+			Title:   convertDesc(p.State),
+			Summary: convertSummary(p),
+		},
+	}
+	if p.Started != 0 {
+		run.StartedAt = convertTime(p.Started)
+	}
+	if run.Status == checkCompleted {
+		run.Conclusion = convertConclusion(p.State)
+		if p.Stopped != 0 {
+			run.CompletedAt = convertTime(p.Stopped)
+		}
+	}
+	return run
+}
+
+// convertCheckStatus is a helper function used to convert a Drone status
+// to a GitHub check run status.
+func convertCheckStatus(status string) string {
+	switch status {
+	case model.StatusPending, model.StatusBlocked:
+		return checkQueued
+	case model.StatusRunning:
+		return checkInProgress
+	default:
+		return checkCompleted
+	}
+}
+
+// convertConclusion is a helper function used to convert a Drone status
+// to a GitHub check run conclusion.
+func convertConclusion(status string) string {
+	switch status {
+	case model.StatusSuccess:
+		return conclusionSuccess
+	case model.StatusKilled:
+		return conclusionCancelled
+	case model.StatusSkipped:
+		return conclusionNeutral
+	default:
+		return conclusionFailure
+	}
+}
+
+// convertSummary is a helper function used to convert the pipeline steps
+// to a markdown summary of the check run.
+func convertSummary(p *model.Proc) string {
+	var buf bytes.Buffer
+	buf.WriteString("| Step | Status | Duration |\n")
+	buf.WriteString("| --- | --- | --- |\n")
+	for _, step := range p.Children {
+		var duration string
+		if step.Started != 0 && step.Stopped != 0 {
+			duration = (time.Duration(step.Stopped-step.Started) * time.Second).String()
+		}
+		fmt.Fprintf(&buf, "| %s | %s | %s |\n", step.Name, step.State, duration)
+	}
+	return buf.String()
+}
+
+// convertAnnotations is a helper function used to convert Drone annotations
+// to GitHub check run annotations.
+func convertAnnotations(from []*model.Annotation) []*checkAnnotation {
+	var to []*checkAnnotation
+	for _, a := range from {
+		to = append(to, &checkAnnotation{
+			Path:            a.Path,
+			StartLine:       a.Line,
+			EndLine:         a.EndLine,
+			AnnotationLevel: a.Level,
+			Title:           a.Title,
+			Message:         a.Message,
+		})
+	}
+	return to
+}
+
+// convertTime is a helper function used to convert a unix timestamp to
+// the ISO 8601 format used by the checks api.
+func convertTime(t int64) string {
+	return time.Unix(t, 0).UTC().Format(time.RFC3339)
+}
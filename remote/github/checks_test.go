@@ -0,0 +1,177 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package github
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/drone/drone/model"
+	"github.com/drone/drone/remote"
+	"github.com/drone/drone/remote/github/fixtures"
+
+	"github.com/franela/goblin"
+	"github.com/gin-gonic/gin"
+)
+
+func Test_checks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	key, _ := rsa.GenerateKey(rand.Reader, 1024)
+	pemKey := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	})
+
+	s := httptest.NewServer(fixtures.Handler())
+	c, _ := New(Opts{
+		URL:          s.URL,
+		Context:      "continuous-integration/drone",
+		Checks:       true,
+		AppID:        1,
+		AppKeyString: string(pemKey),
+	})
+
+	g := goblin.Goblin(t)
+	g.Describe("GitHub checks", func() {
+
+		g.After(func() {
+			s.Close()
+		})
+
+		g.It("Should only report check runs in checks mode", func() {
+			_, ok := c.(remote.Checker)
+			g.Assert(ok).IsTrue()
+
+			r, _ := New(Opts{URL: s.URL})
+			_, ok = r.(remote.Checker)
+			g.Assert(ok).IsFalse()
+		})
+
+		g.It("Should require a github app", func() {
+			_, err := New(Opts{URL: s.URL, Checks: true})
+			g.Assert(err != nil).IsTrue()
+		})
+
+		g.It("Should cache the check run of a running pipeline", func() {
+			proc := *fakeProc
+			proc.State = model.StatusRunning
+			err := c.(remote.Checker).Check(fakeUser, fakeRepo, fakeBuildPush, &proc, nil, "")
+			g.Assert(err == nil).IsTrue()
+			g.Assert(c.(*checkClient).runs["1"]).Equal(int64(4))
+
+			// the cached check run is updated without a lookup, which
+			// fails for an unknown commit.
+			build := *fakeBuildPush
+			build.Commit = "unknown"
+			err = c.(remote.Checker).Check(fakeUser, fakeRepo, &build, &proc, nil, "")
+			g.Assert(err == nil).IsTrue()
+
+			err = c.(remote.Checker).Check(fakeUser, fakeRepo, fakeBuildPush, fakeProc, nil, "")
+			g.Assert(err == nil).IsTrue()
+			_, ok := c.(*checkClient).runs["1"]
+			g.Assert(ok).IsFalse()
+		})
+
+		g.It("Should update the existing check run", func() {
+			err := c.(remote.Checker).Check(fakeUser, fakeRepo, fakeBuildPush, fakeProc, nil, "http://127.0.0.1/octocat/Hello-World/1/1")
+			g.Assert(err == nil).IsTrue()
+		})
+
+		g.It("Should create the check run with annotations in batches", func() {
+			var annotations []*model.Annotation
+			for i := 0; i < 60; i++ {
+				annotations = append(annotations, &model.Annotation{
+					Path:    "main.go",
+					Line:    i + 1,
+					EndLine: i + 1,
+					Level:   model.AnnotationWarning,
+					Message: "exported function should have comment",
+				})
+			}
+			err := c.(remote.Checker).Check(fakeUser, fakeRepo, fakeBuildPush, fakeProcDone, annotations, "http://127.0.0.1/octocat/Hello-World/1/2")
+			g.Assert(err == nil).IsTrue()
+		})
+
+		g.It("Should handle a check run error", func() {
+			build := *fakeBuildPush
+			build.Commit = "unknown"
+			err := c.(remote.Checker).Check(fakeUser, fakeRepo, &build, fakeProc, nil, "")
+			g.Assert(err != nil).IsTrue()
+		})
+
+		g.It("Should ignore deployments", func() {
+			build := *fakeBuildPush
+			build.Event = model.EventDeploy
+			build.Commit = "unknown"
+			err := c.(remote.Checker).Check(fakeUser, fakeRepo, &build, fakeProc, nil, "")
+			g.Assert(err == nil).IsTrue()
+		})
+
+		g.Describe("Converting a pipeline", func() {
+			g.It("Should convert a running pipeline", func() {
+				run := convertCheckRun(fakeBuildPush, fakeProcRunning, "http://127.0.0.1/octocat/Hello-World/1/1")
+				g.Assert(run.Status).Equal("in_progress")
+				g.Assert(run.Conclusion).Equal("")
+				g.Assert(run.ExternalID).Equal("3")
+				g.Assert(run.HeadSHA).Equal("9ecad50")
+				g.Assert(run.DetailsURL).Equal("http://127.0.0.1/octocat/Hello-World/1/1")
+				g.Assert(run.StartedAt).Equal("2017-09-01T00:00:00Z")
+				g.Assert(run.CompletedAt).Equal("")
+			})
+			g.It("Should convert a completed pipeline", func() {
+				run := convertCheckRun(fakeBuildPush, fakeProcDone, "")
+				g.Assert(run.Status).Equal("completed")
+				g.Assert(run.Conclusion).Equal("failure")
+				g.Assert(run.CompletedAt).Equal("2017-09-01T00:01:05Z")
+				g.Assert(run.Output.Title).Equal(descFailure)
+				g.Assert(strings.Contains(run.Output.Summary, "| test | failure | 1m0s |")).IsTrue()
+			})
+			g.It("Should convert the check run conclusion", func() {
+				g.Assert(convertConclusion(model.StatusSuccess)).Equal("success")
+				g.Assert(convertConclusion(model.StatusFailure)).Equal("failure")
+				g.Assert(convertConclusion(model.StatusError)).Equal("failure")
+				g.Assert(convertConclusion(model.StatusKilled)).Equal("cancelled")
+				g.Assert(convertConclusion(model.StatusSkipped)).Equal("neutral")
+			})
+		})
+	})
+}
+
+var (
+	fakeProcRunning = &model.Proc{
+		ID:      3,
+		PID:     1,
+		State:   model.StatusRunning,
+		Started: 1504224000,
+	}
+
+	fakeProcDone = &model.Proc{
+		ID:      2,
+		PID:     1,
+		State:   model.StatusFailure,
+		Started: 1504224000,
+		Stopped: 1504224065,
+		Children: []*model.Proc{
+			{Name: "clone", State: model.StatusSuccess, Started: 1504224000, Stopped: 1504224005},
+			{Name: "test", State: model.StatusFailure, Started: 1504224005, Stopped: 1504224065},
+		},
+	}
+)
@@ -167,6 +167,40 @@ func convertTeam(from github.Organization) *model.Team {
 	}
 }
 
+// convertCommit is a helper function used to convert a GitHub commit to the
+// common Drone commit structure.
+func convertCommit(from *github.RepositoryCommit) *model.Commit {
+	commit := new(model.Commit)
+	if from.SHA != nil {
+		commit.Sha = *from.SHA
+	}
+	if from.HTMLURL != nil {
+		commit.Link = *from.HTMLURL
+	}
+	if from.Commit != nil {
+		if from.Commit.Message != nil {
+			commit.Message = *from.Commit.Message
+		}
+		if from.Commit.Author != nil {
+			if from.Commit.Author.Name != nil {
+				commit.Author = *from.Commit.Author.Name
+			}
+			if from.Commit.Author.Email != nil {
+				commit.Email = *from.Commit.Author.Email
+			}
+		}
+	}
+	if from.Author != nil {
+		if from.Author.Login != nil {
+			commit.Author = *from.Author.Login
+		}
+		if from.Author.AvatarURL != nil {
+			commit.Avatar = *from.Author.AvatarURL
+		}
+	}
+	return commit
+}
+
 // convertRepoHook is a helper function used to extract the Repository details
 // from a webhook and convert to the common Drone repository structure.
 func convertRepoHook(from *webhook) *model.Repo {
@@ -15,7 +15,9 @@
 package fixtures
 
 import (
+	"encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -29,6 +31,13 @@ func Handler() http.Handler {
 	e.GET("/api/v3/repos/:owner/:name", getRepo)
 	e.GET("/api/v3/orgs/:org/memberships/:user", getMembership)
 	e.GET("/api/v3/user/memberships/orgs/:org", getMembership)
+	e.POST("/api/v3/repos/:owner/:name/statuses/:sha", createStatus)
+	e.GET("/api/v3/repos/:owner/:name/commits/:sha", getCommit)
+	e.GET("/api/v3/repos/:owner/:name/commits/:sha/check-runs", getCheckRuns)
+	e.POST("/api/v3/repos/:owner/:name/check-runs", createCheckRun)
+	e.PATCH("/api/v3/repos/:owner/:name/check-runs/:id", updateCheckRun)
+	e.GET("/api/v3/repos/:owner/:name/installation", getInstallation)
+	e.POST("/api/v3/app/installations/:id/access_tokens", createAccessToken)
 
 	return e
 }
@@ -53,6 +62,185 @@ func getMembership(c *gin.Context) {
 	}
 }
 
+func createStatus(c *gin.Context) {
+	in := struct {
+		State   string `json:"state"`
+		Context string `json:"context"`
+	}{}
+	if err := json.NewDecoder(c.Request.Body).Decode(&in); err != nil {
+		c.String(400, "")
+		return
+	}
+	switch {
+	case c.Request.Header.Get("Authorization") == "Bearer revoked":
+		c.String(401, "")
+	case c.Param("sha") != "9ecad50":
+		c.String(404, "")
+	case in.Context != "continuous-integration/drone/push" &&
+		in.Context != "continuous-integration/drone/push/GO_VERSION=1.9":
+		c.String(422, "")
+	default:
+		c.String(201, statusPayload)
+	}
+}
+
+func getCommit(c *gin.Context) {
+	switch c.Param("sha") {
+	case "master":
+		c.String(200, commitPayload)
+	default:
+		c.String(404, "")
+	}
+}
+
+func getCheckRuns(c *gin.Context) {
+	switch {
+	case c.Param("sha") != "9ecad50":
+		c.String(404, "")
+	case c.Query("check_name") == "continuous-integration/drone/push/GO_VERSION=1.9":
+		c.String(200, checkRunsPayload)
+	default:
+		c.String(200, checkRunsEmptyPayload)
+	}
+}
+
+func createCheckRun(c *gin.Context) {
+	in := checkRunInput{}
+	if err := json.NewDecoder(c.Request.Body).Decode(&in); err != nil ||
+		c.Request.Header.Get("Accept") != "application/vnd.github.antiope-preview+json" {
+		c.String(400, "")
+		return
+	}
+	if in.Name == "" || in.HeadSHA != "9ecad50" || len(in.Output.Annotations) > 50 {
+		c.String(422, "")
+		return
+	}
+	c.String(201, checkRunPayload)
+}
+
+func updateCheckRun(c *gin.Context) {
+	in := checkRunInput{}
+	if err := json.NewDecoder(c.Request.Body).Decode(&in); err != nil {
+		c.String(400, "")
+		return
+	}
+	switch {
+	case c.Param("id") != "4":
+		c.String(404, "")
+	case len(in.Output.Annotations) > 50:
+		c.String(422, "")
+	default:
+		c.String(200, checkRunPayload)
+	}
+}
+
+func getInstallation(c *gin.Context) {
+	switch {
+	case !isAppToken(c.Request.Header.Get("Authorization")):
+		c.String(401, "")
+	case c.Param("name") == "repo_not_found":
+		c.String(404, "")
+	default:
+		c.String(200, installationPayload)
+	}
+}
+
+func createAccessToken(c *gin.Context) {
+	switch {
+	case !isAppToken(c.Request.Header.Get("Authorization")):
+		c.String(401, "")
+	case c.Param("id") != "1":
+		c.String(404, "")
+	default:
+		c.String(201, accessTokenPayload)
+	}
+}
+
+// helper function returns true if the authorization header
+// contains a json web token.
+func isAppToken(header string) bool {
+	return strings.HasPrefix(header, "Bearer ") &&
+		strings.Count(header, ".") == 2
+}
+
+type checkRunInput struct {
+	Name    string `json:"name"`
+	HeadSHA string `json:"head_sha"`
+	Output  struct {
+		Annotations []interface{} `json:"annotations"`
+	} `json:"output"`
+}
+
+var commitPayload = `
+{
+  "sha": "9ecad50",
+  "html_url": "https://github.com/octocat/Hello-World/commit/9ecad50",
+  "commit": {
+    "message": "Update README.md",
+    "author": {
+      "name": "The Octocat",
+      "email": "octocat@github.com"
+    }
+  },
+  "author": {
+    "login": "octocat",
+    "avatar_url": "https://github.com/images/error/octocat_happy.gif"
+  }
+}
+`
+
+var checkRunPayload = `
+{
+  "id": 4,
+  "name": "continuous-integration/drone/push/1",
+  "external_id": "2",
+  "status": "completed"
+}
+`
+
+var checkRunsPayload = `
+{
+  "total_count": 1,
+  "check_runs": [
+    {
+      "id": 4,
+      "name": "continuous-integration/drone/push/GO_VERSION=1.9",
+      "external_id": "1",
+      "status": "in_progress"
+    }
+  ]
+}
+`
+
+var checkRunsEmptyPayload = `
+{
+  "total_count": 0,
+  "check_runs": []
+}
+`
+
+var installationPayload = `
+{
+  "id": 1,
+  "app_id": 1
+}
+`
+
+var accessTokenPayload = `
+{
+  "token": "v1.1f699f1069f60xxx",
+  "expires_at": "2099-01-01T00:00:00Z"
+}
+`
+
+var statusPayload = `
+{
+  "id": 1,
+  "state": "success",
+  "context": "continuous-integration/drone"
+}
+`
+
 var repoPayload = `
 {
   "owner": {
@@ -23,6 +23,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/drone/drone/model"
 	"github.com/drone/drone/remote"
@@ -50,6 +51,11 @@ type Opts struct {
 	PrivateMode bool     // GitHub is running in private mode.
 	SkipVerify  bool     // Skip ssl verification.
 	MergeRef    bool     // Clone pull requests using the merge ref.
+	Checks      bool     // Report pipelines as check runs, requires a GitHub App.
+
+	AppID        int64  // Optional GitHub App id.
+	AppKey       string // GitHub App private key file.
+	AppKeyString string // GitHub App private key.
 }
 
 // New returns a Remote implementation that integrates with a GitHub Cloud or
@@ -82,8 +88,22 @@ func New(opts Opts) (remote.Remote, error) {
 		remote.API = remote.URL + "/api/v3/"
 	}
 
+	if opts.Checks && opts.AppID == 0 {
+		return nil, fmt.Errorf("github checks require a github app id and private key")
+	}
+	if opts.AppID != 0 {
+		key, err := loadAppKey(opts.AppKey, opts.AppKeyString)
+		if err != nil {
+			return nil, err
+		}
+		remote.app = newApp(opts.AppID, key)
+	}
+
 	// Hack to enable oauth2 access in older GHE
 	oauth2.RegisterBrokenAuthHeaderProvider(remote.URL)
+	if opts.Checks {
+		return newCheckClient(remote), nil
+	}
 	return remote, nil
 }
 
@@ -100,6 +120,10 @@ type client struct {
 	PrivateMode bool
 	SkipVerify  bool
 	MergeRef    bool
+
+	// app authenticates repository access as a GitHub
+	// App installation, if configured.
+	app *app
 }
 
 // Login authenticates the session and returns the remote user details.
@@ -182,6 +206,16 @@ func (c *client) Teams(u *model.User) ([]*model.Team, error) {
 	return teams, nil
 }
 
+// TeamPerm returns the user permissions for the named GitHub organization.
+func (c *client) TeamPerm(u *model.User, org string) (*model.Perm, error) {
+	client := c.newClientToken(u.Token)
+	membership, _, err := client.Organizations.GetOrgMembership("", org)
+	if err != nil {
+		return nil, err
+	}
+	return convertTeamPerm(membership), nil
+}
+
 // Repo returns the named GitHub repository.
 func (c *client) Repo(u *model.User, owner, name string) (*model.Repo, error) {
 	client := c.newClientToken(u.Token)
@@ -230,7 +264,10 @@ func (c *client) File(u *model.User, r *model.Repo, b *model.Build, f string) ([
 
 // FileRef fetches the file from the GitHub repository and returns its contents.
 func (c *client) FileRef(u *model.User, r *model.Repo, ref, f string) ([]byte, error) {
-	client := c.newClientToken(u.Token)
+	client, err := c.newClientRepo(u, r)
+	if err != nil {
+		return nil, err
+	}
 
 	opts := new(github.RepositoryContentGetOptions)
 	opts.Ref = ref
@@ -241,9 +278,22 @@ func (c *client) FileRef(u *model.User, r *model.Repo, ref, f string) ([]byte, e
 	return data.Decode()
 }
 
+// BranchHead returns the head commit of the GitHub repository branch.
+func (c *client) BranchHead(u *model.User, r *model.Repo, branch string) (*model.Commit, error) {
+	client, err := c.newClientRepo(u, r)
+	if err != nil {
+		return nil, err
+	}
+	commit, _, err := client.Repositories.GetCommit(r.Owner, r.Name, branch)
+	if err != nil {
+		return nil, err
+	}
+	return convertCommit(commit), nil
+}
+
 // Netrc returns a netrc file capable of authenticating GitHub requests and
 // cloning GitHub repositories. The netrc will use the global machine account
-// when configured.
+// when configured, or the installation token in GitHub App mode.
 func (c *client) Netrc(u *model.User, r *model.Repo) (*model.Netrc, error) {
 	if c.Password != "" {
 		return &model.Netrc{
@@ -252,6 +302,17 @@ func (c *client) Netrc(u *model.User, r *model.Repo) (*model.Netrc, error) {
 			Machine:  c.Machine,
 		}, nil
 	}
+	if c.app != nil {
+		token, err := c.installationToken(r)
+		if err != nil {
+			return nil, err
+		}
+		return &model.Netrc{
+			Login:    "x-access-token",
+			Password: token,
+			Machine:  c.Machine,
+		}, nil
+	}
 	return &model.Netrc{
 		Login:    u.Token,
 		Password: "x-oauth-basic",
@@ -262,7 +323,10 @@ func (c *client) Netrc(u *model.User, r *model.Repo) (*model.Netrc, error) {
 // Deactivate deactives the repository be removing registered push hooks from
 // the GitHub repository.
 func (c *client) Deactivate(u *model.User, r *model.Repo, link string) error {
-	client := c.newClientToken(u.Token)
+	client, err := c.newClientRepo(u, r)
+	if err != nil {
+		return err
+	}
 	hooks, _, err := client.Repositories.ListHooks(r.Owner, r.Name, nil)
 	if err != nil {
 		return err
@@ -307,10 +371,17 @@ func (c *client) newConfig(redirect string) *oauth2.Config {
 
 // helper function to return the GitHub oauth2 client
 func (c *client) newClientToken(token string) *github.Client {
+	return c.newClientTimeout(token, 0)
+}
+
+// helper function to return the GitHub oauth2 client with the request
+// timeout. A zero timeout means no timeout.
+func (c *client) newClientTimeout(token string, timeout time.Duration) *github.Client {
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
 	tc := oauth2.NewClient(oauth2.NoContext, ts)
+	tc.Timeout = timeout
 	if c.SkipVerify {
 		tc.Transport.(*oauth2.Transport).Base = &http.Transport{
 			Proxy: http.ProxyFromEnvironment,
@@ -324,6 +395,18 @@ func (c *client) newClientToken(token string) *github.Client {
 	return github
 }
 
+// helper function to send a request to an api that is not supported by the
+// vendored client, using the preview media type of the api.
+func previewRequest(client *github.Client, method, uri, accept string, in, out interface{}) error {
+	req, err := client.NewRequest(method, uri, in)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", accept)
+	_, err = client.Do(req, out)
+	return err
+}
+
 // helper function to return matching user email.
 func matchingEmail(emails []github.UserEmail, rawurl string) *github.UserEmail {
 	for _, email := range emails {
@@ -375,7 +458,10 @@ func matchingHooks(hooks []github.Hook, rawurl string) *github.Hook {
 // Status sends the commit status to the remote system.
 // An example would be the GitHub pull request status.
 func (c *client) Status(u *model.User, r *model.Repo, b *model.Build, link string) error {
-	client := c.newClientToken(u.Token)
+	client, err := c.newClientRepo(u, r)
+	if err != nil {
+		return err
+	}
 	switch b.Event {
 	case "deployment":
 		return deploymentStatus(client, r, b, link)
@@ -384,19 +470,41 @@ func (c *client) Status(u *model.User, r *model.Repo, b *model.Build, link strin
 	}
 }
 
-func repoStatus(client *github.Client, r *model.Repo, b *model.Build, link, ctx string) error {
-	context := ctx
+// ProcStatus sends the commit status of an individual pipeline to the
+// remote system. The status context is qualified with the matrix axis.
+func (c *client) ProcStatus(u *model.User, r *model.Repo, b *model.Build, p *model.Proc, link string) error {
+	if b.Event == model.EventDeploy {
+		return nil
+	}
+	client, err := c.newClientRepo(u, r)
+	if err != nil {
+		return err
+	}
+	data := github.RepoStatus{
+		Context:     github.String(statusContext(c.Context, b) + "/" + p.Axis()),
+		State:       github.String(convertStatus(p.State)),
+		Description: github.String(convertDesc(p.State)),
+		TargetURL:   github.String(link),
+	}
+	_, _, err = client.Repositories.CreateStatus(r.Owner, r.Name, b.Commit, &data)
+	return err
+}
+
+func statusContext(ctx string, b *model.Build) string {
 	switch b.Event {
 	case model.EventPull:
-		context += "/pr"
+		return ctx + "/pr"
 	default:
 		if len(b.Event) > 0 {
-			context += "/" + b.Event
+			return ctx + "/" + b.Event
 		}
 	}
+	return ctx
+}
 
+func repoStatus(client *github.Client, r *model.Repo, b *model.Build, link, ctx string) error {
 	data := github.RepoStatus{
-		Context:     github.String(context),
+		Context:     github.String(statusContext(ctx, b)),
 		State:       github.String(convertStatus(b.Status)),
 		Description: github.String(convertDesc(b.Status)),
 		TargetURL:   github.String(link),
@@ -429,7 +537,10 @@ func (c *client) Activate(u *model.User, r *model.Repo, link string) error {
 	if err := c.Deactivate(u, r, link); err != nil {
 		return err
 	}
-	client := c.newClientToken(u.Token)
+	client, err := c.newClientRepo(u, r)
+	if err != nil {
+		return err
+	}
 	hook := &github.Hook{
 		Name: github.String("web"),
 		Events: []string{
@@ -442,7 +553,7 @@ func (c *client) Activate(u *model.User, r *model.Repo, link string) error {
 			"content_type": "form",
 		},
 	}
-	_, _, err := client.Repositories.CreateHook(r.Owner, r.Name, hook)
+	_, _, err = client.Repositories.CreateHook(r.Owner, r.Name, hook)
 	return err
 }
 
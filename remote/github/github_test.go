@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	"github.com/drone/drone/model"
+	"github.com/drone/drone/remote"
 	"github.com/drone/drone/remote/github/fixtures"
 
 	"github.com/franela/goblin"
@@ -32,6 +33,7 @@ func Test_github(t *testing.T) {
 	c, _ := New(Opts{
 		URL:        s.URL,
 		SkipVerify: true,
+		Context:    "continuous-integration/drone",
 	})
 
 	g := goblin.Goblin(t)
@@ -124,6 +126,57 @@ func Test_github(t *testing.T) {
 			})
 		})
 
+		g.Describe("Requesting organization permissions", func() {
+			g.It("Should return the permission details of an admin", func() {
+				perm, err := c.(remote.TeamPermer).TeamPerm(fakeUser, "octocat")
+				g.Assert(err == nil).IsTrue()
+				g.Assert(perm.Admin).IsTrue()
+			})
+			g.It("Should return the permission details of a member", func() {
+				perm, err := c.(remote.TeamPermer).TeamPerm(fakeUser, "github")
+				g.Assert(err == nil).IsTrue()
+				g.Assert(perm.Admin).IsFalse()
+			})
+			g.It("Should handle a not found error", func() {
+				_, err := c.(remote.TeamPermer).TeamPerm(fakeUser, "org_not_found")
+				g.Assert(err != nil).IsTrue()
+			})
+		})
+
+		g.Describe("Requesting the head of a branch", func() {
+			g.It("Should return the head commit", func() {
+				commit, err := c.(remote.Brancher).BranchHead(fakeUser, fakeRepo, "master")
+				g.Assert(err == nil).IsTrue()
+				g.Assert(commit.Sha).Equal("9ecad50")
+				g.Assert(commit.Message).Equal("Update README.md")
+				g.Assert(commit.Author).Equal("octocat")
+				g.Assert(commit.Email).Equal("octocat@github.com")
+				g.Assert(commit.Avatar).Equal("https://github.com/images/error/octocat_happy.gif")
+				g.Assert(commit.Link).Equal("https://github.com/octocat/Hello-World/commit/9ecad50")
+			})
+			g.It("Should handle a not found error", func() {
+				_, err := c.(remote.Brancher).BranchHead(fakeUser, fakeRepo, "branch_not_found")
+				g.Assert(err != nil).IsTrue()
+			})
+		})
+
+		g.Describe("Sending the build status", func() {
+			g.It("Should create the commit status", func() {
+				err := c.Status(fakeUser, fakeRepo, fakeBuildPush, "http://127.0.0.1/octocat/Hello-World/1")
+				g.Assert(err == nil).IsTrue()
+			})
+			g.It("Should create the pipeline status with the matrix axis", func() {
+				err := c.(remote.ProcStatuser).ProcStatus(fakeUser, fakeRepo, fakeBuildPush, fakeProc, "http://127.0.0.1/octocat/Hello-World/1/1")
+				g.Assert(err == nil).IsTrue()
+			})
+			g.It("Should handle a status error", func() {
+				build := *fakeBuildPush
+				build.Commit = "unknown"
+				err := c.(remote.ProcStatuser).ProcStatus(fakeUser, fakeRepo, &build, fakeProc, "")
+				g.Assert(err != nil).IsTrue()
+			})
+		})
+
 		g.It("Should return a user repository list")
 
 		g.It("Should return a user team list")
@@ -172,4 +225,17 @@ var (
 	fakeBuild = &model.Build{
 		Commit: "9ecad50",
 	}
+
+	fakeBuildPush = &model.Build{
+		Event:  model.EventPush,
+		Commit: "9ecad50",
+		Status: model.StatusSuccess,
+	}
+
+	fakeProc = &model.Proc{
+		ID:      1,
+		PID:     1,
+		State:   model.StatusFailure,
+		Environ: map[string]string{"GO_VERSION": "1.9"},
+	}
 )
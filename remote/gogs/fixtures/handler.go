@@ -28,7 +28,10 @@ func Handler() http.Handler {
 	e := gin.New()
 	e.GET("/api/v1/repos/:owner/:name", getRepo)
 	e.GET("/api/v1/repos/:owner/:name/raw/:commit/:file", getRepoFile)
+	e.GET("/api/v1/repos/:owner/:name/branches/:branch", getRepoBranch)
 	e.POST("/api/v1/repos/:owner/:name/hooks", createRepoHook)
+	e.POST("/api/v1/repos/:owner/:name/statuses/:commit", createRepoCommitStatus)
+	e.POST("/api/v1/repos/:owner/:name/issues/:index/comments", createIssueComment)
 	e.GET("/api/v1/user/repos", getUserRepos)
 
 	return e
@@ -53,6 +56,15 @@ func getRepoFile(c *gin.Context) {
 	c.String(404, "")
 }
 
+func getRepoBranch(c *gin.Context) {
+	switch c.Param("branch") {
+	case "master":
+		c.String(200, repoBranchPayload)
+	default:
+		c.String(404, "")
+	}
+}
+
 func createRepoHook(c *gin.Context) {
 	in := struct {
 		Type string `json:"type"`
@@ -72,6 +84,37 @@ func createRepoHook(c *gin.Context) {
 	c.String(200, "{}")
 }
 
+func createRepoCommitStatus(c *gin.Context) {
+	if c.Param("name") == "repo_no_status" {
+		c.String(404, "")
+		return
+	}
+	in := struct {
+		State   string `json:"state"`
+		Context string `json:"context"`
+	}{}
+	c.BindJSON(&in)
+	if c.Param("commit") != "9ecad50" ||
+		in.State != "success" ||
+		in.Context != "continuous-integration/drone" {
+		c.String(500, "")
+		return
+	}
+	c.String(201, "{}")
+}
+
+func createIssueComment(c *gin.Context) {
+	in := struct {
+		Body string `json:"body"`
+	}{}
+	c.BindJSON(&in)
+	if c.Param("index") != "1" || in.Body == "" {
+		c.String(500, "")
+		return
+	}
+	c.String(201, "{}")
+}
+
 func getUserRepos(c *gin.Context) {
 	switch c.Request.Header.Get("Authorization") {
 	case "token repos_not_found":
@@ -122,3 +165,19 @@ const userRepoPayload = `
   }
 ]
 `
+
+const repoBranchPayload = `
+{
+  "name": "master",
+  "commit": {
+    "id": "9ecad50",
+    "message": "Update README.md",
+    "url": "http://localhost/test_name/repo_name/commit/9ecad50",
+    "author": {
+      "name": "Jane Doe",
+      "email": "jane@example.com",
+      "username": "jane"
+    }
+  }
+}
+`
@@ -202,14 +202,31 @@ func (c *client) File(u *model.User, r *model.Repo, b *model.Build, f string) ([
 	return cfg, err
 }
 
+// BranchHead returns the head commit of the Gogs repository branch.
+func (c *client) BranchHead(u *model.User, r *model.Repo, branch string) (*model.Commit, error) {
+	b, err := c.newClientToken(u.Token).GetRepoBranch(r.Owner, r.Name, branch)
+	if err != nil {
+		return nil, err
+	}
+	return commitFromBranch(b), nil
+}
+
 // FileRef fetches the file from the Gogs repository and returns its contents.
 func (c *client) FileRef(u *model.User, r *model.Repo, ref, f string) ([]byte, error) {
 	return c.newClientToken(u.Token).GetFile(r.Owner, r.Name, ref, f)
 }
 
-// Status is not supported by the Gogs driver.
+// Status creates the commit status. Gogs versions without the commit
+// status api receive a comment on the pull request once the build is
+// complete instead.
 func (c *client) Status(u *model.User, r *model.Repo, b *model.Build, link string) error {
-	return nil
+	if b.Commit != "" {
+		err := c.createStatus(u, r, b, link)
+		if !isUnsupported(err) {
+			return err
+		}
+	}
+	return c.createComment(u, r, b, link)
 }
 
 // Netrc returns a netrc file capable of authenticating Gogs requests and
@@ -270,11 +287,18 @@ func (c *client) newClient() *gogs.Client {
 func (c *client) newClientToken(token string) *gogs.Client {
 	client := gogs.NewClient(c.URL, token)
 	if c.SkipVerify {
-		httpClient := &http.Client{}
+		client.SetHTTPClient(c.newHTTPClient())
+	}
+	return client
+}
+
+// helper function to return the http client
+func (c *client) newHTTPClient() *http.Client {
+	httpClient := &http.Client{}
+	if c.SkipVerify {
 		httpClient.Transport = &http.Transport{
 			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
 		}
-		client.SetHTTPClient(httpClient)
 	}
-	return client
+	return httpClient
 }
@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	"github.com/drone/drone/model"
+	"github.com/drone/drone/remote"
 	"github.com/drone/drone/remote/gogs/fixtures"
 
 	"github.com/franela/goblin"
@@ -136,6 +137,22 @@ func Test_gogs(t *testing.T) {
 			g.Assert(err == nil).IsTrue()
 		})
 
+		g.Describe("Requesting the head of a branch", func() {
+			g.It("Should return the head commit", func() {
+				commit, err := c.(remote.Brancher).BranchHead(fakeUser, fakeRepo, "master")
+				g.Assert(err == nil).IsTrue()
+				g.Assert(commit.Sha).Equal("9ecad50")
+				g.Assert(commit.Message).Equal("Update README.md")
+				g.Assert(commit.Author).Equal("jane")
+				g.Assert(commit.Email).Equal("jane@example.com")
+				g.Assert(commit.Link).Equal("http://localhost/test_name/repo_name/commit/9ecad50")
+			})
+			g.It("Should handle a not found error", func() {
+				_, err := c.(remote.Brancher).BranchHead(fakeUser, fakeRepo, "branch_not_found")
+				g.Assert(err != nil).IsTrue()
+			})
+		})
+
 		g.It("Should return a repository file", func() {
 			raw, err := c.File(fakeUser, fakeRepo, fakeBuild, ".drone.yml")
 			g.Assert(err == nil).IsTrue()
@@ -161,13 +178,35 @@ func Test_gogs(t *testing.T) {
 			g.It("Should handle a parsing error")
 		})
 
+		g.Describe("Sending the build status", func() {
+			g.It("Should create a commit status", func() {
+				err := c.Status(fakeUser, fakeRepo, fakeBuildSuccess, "http://localhost/test_name/repo_name/1")
+				g.Assert(err == nil).IsTrue()
+			})
+			g.It("Should comment on the pull request without a status api", func() {
+				err := c.Status(fakeUser, fakeRepoNoStatus, fakeBuildPull, "http://localhost/test_name/repo_no_status/1")
+				g.Assert(err == nil).IsTrue()
+			})
+			g.It("Should handle a comment error", func() {
+				build := *fakeBuildPull
+				build.Ref = "refs/pull/2/head"
+				err := c.Status(fakeUser, fakeRepoNoStatus, &build, "")
+				g.Assert(err != nil).IsTrue()
+			})
+			g.It("Should not comment on running builds", func() {
+				build := *fakeBuildPull
+				build.Ref = "refs/pull/2/head"
+				build.Status = model.StatusRunning
+				err := c.Status(fakeUser, fakeRepoNoStatus, &build, "")
+				g.Assert(err == nil).IsTrue()
+			})
+		})
+
 		g.It("Should return no-op for usupporeted features", func() {
 			_, err1 := c.Auth("octocat", "4vyW6b49Z")
-			err2 := c.Status(nil, nil, nil, "")
-			err3 := c.Deactivate(nil, nil, "")
+			err2 := c.Deactivate(nil, nil, "")
 			g.Assert(err1 != nil).IsTrue()
 			g.Assert(err2 == nil).IsTrue()
-			g.Assert(err3 == nil).IsTrue()
 		})
 	})
 }
@@ -199,6 +238,26 @@ var (
 		Commit: "9ecad50",
 	}
 
+	fakeBuildSuccess = &model.Build{
+		Number: 1,
+		Commit: "9ecad50",
+		Status: model.StatusSuccess,
+	}
+
+	fakeBuildPull = &model.Build{
+		Number: 1,
+		Event:  model.EventPull,
+		Commit: "9ecad50",
+		Ref:    "refs/pull/1/head",
+		Status: model.StatusFailure,
+	}
+
+	fakeRepoNoStatus = &model.Repo{
+		Owner:    "test_name",
+		Name:     "repo_no_status",
+		FullName: "test_name/repo_no_status",
+	}
+
 	fakeBuildWithRef = &model.Build{
 		Ref: "refs/tags/v1.0.0",
 	}
@@ -97,6 +97,24 @@ func buildFromPush(hook *pushHook) *model.Build {
 	}
 }
 
+// helper function that extracts the head commit from a Gogs branch
+func commitFromBranch(from *gogs.Branch) *model.Commit {
+	commit := new(model.Commit)
+	if from.Commit != nil {
+		commit.Sha = from.Commit.ID
+		commit.Message = from.Commit.Message
+		commit.Link = from.Commit.URL
+		if from.Commit.Author != nil {
+			commit.Author = from.Commit.Author.UserName
+			commit.Email = from.Commit.Author.Email
+			if commit.Author == "" {
+				commit.Author = from.Commit.Author.Name
+			}
+		}
+	}
+	return commit
+}
+
 // helper function that extracts the Build data from a Gogs tag hook
 func buildFromTag(hook *pushHook) *model.Build {
 	avatar := expandAvatar(
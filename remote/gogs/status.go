@@ -0,0 +1,182 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gogs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/drone/drone/model"
+)
+
+const (
+	pathStatus  = "%s/api/v1/repos/%s/%s/statuses/%s"
+	pathComment = "%s/api/v1/repos/%s/%s/issues/%d/comments"
+)
+
+const statusContext = "continuous-integration/drone"
+
+const (
+	descPending  = "the build is pending"
+	descRunning  = "the build is running"
+	descSuccess  = "the build was successful"
+	descFailure  = "the build failed"
+	descCanceled = "the build canceled"
+	descBlocked  = "the build is pending approval"
+	descDeclined = "the build was rejected"
+)
+
+type statusOption struct {
+	State       string `json:"state"`
+	TargetURL   string `json:"target_url"`
+	Description string `json:"description"`
+	Context     string `json:"context"`
+}
+
+type commentOption struct {
+	Body string `json:"body"`
+}
+
+// statusError is returned when the Gogs API rejects a request.
+type statusError struct {
+	Status int
+}
+
+func (e statusError) Error() string {
+	return fmt.Sprintf("gogs: unexpected status code %d", e.Status)
+}
+
+// createStatus creates the commit status. Gogs versions that do not
+// support commit statuses respond with a not found error, see
+// isUnsupported.
+func (c *client) createStatus(u *model.User, r *model.Repo, b *model.Build, link string) error {
+	uri := fmt.Sprintf(pathStatus, c.URL, r.Owner, r.Name, b.Commit)
+	return c.post(u.Token, uri, &statusOption{
+		State:       getStatus(b.Status),
+		TargetURL:   link,
+		Description: getDesc(b.Status),
+		Context:     statusContext,
+	})
+}
+
+// createComment comments the build result on the pull request. Only
+// completed builds are commented, since comments cannot be updated.
+func (c *client) createComment(u *model.User, r *model.Repo, b *model.Build, link string) error {
+	if b.Event != model.EventPull || !isComplete(b.Status) {
+		return nil
+	}
+	number, ok := pullNumber(b.Ref)
+	if !ok {
+		return nil
+	}
+	uri := fmt.Sprintf(pathComment, c.URL, r.Owner, r.Name, number)
+	return c.post(u.Token, uri, &commentOption{
+		Body: fmt.Sprintf("Build #%d: %s. %s", b.Number, getDesc(b.Status), link),
+	})
+}
+
+// helper function that posts the json encoded payload to the Gogs api.
+func (c *client) post(token, uri string, in interface{}) error {
+	buf := new(bytes.Buffer)
+	if err := json.NewEncoder(buf).Encode(in); err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", uri, buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "token "+token)
+
+	resp, err := c.newHTTPClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode > http.StatusNoContent {
+		return statusError{resp.StatusCode}
+	}
+	return nil
+}
+
+// helper function that returns true if the error indicates the api
+// endpoint is not supported by the Gogs version.
+func isUnsupported(err error) bool {
+	if err, ok := err.(statusError); ok {
+		return err.Status == http.StatusNotFound || err.Status == http.StatusMethodNotAllowed
+	}
+	return false
+}
+
+// helper function that returns the pull request number from the pull
+// request ref, eg refs/pull/1/head.
+func pullNumber(ref string) (int, bool) {
+	parts := strings.Split(ref, "/")
+	if len(parts) != 4 || parts[1] != "pull" {
+		return 0, false
+	}
+	number, err := strconv.Atoi(parts[2])
+	return number, err == nil
+}
+
+// helper function that returns true if the build status is final.
+func isComplete(status string) bool {
+	switch status {
+	case model.StatusSuccess, model.StatusFailure, model.StatusError, model.StatusKilled:
+		return true
+	default:
+		return false
+	}
+}
+
+// getStatus is a helper function that converts a Drone
+// status to a Gogs status.
+func getStatus(status string) string {
+	switch status {
+	case model.StatusPending, model.StatusBlocked, model.StatusRunning:
+		return "pending"
+	case model.StatusSuccess:
+		return "success"
+	case model.StatusDeclined:
+		return "warning"
+	default:
+		return "failure"
+	}
+}
+
+// getDesc is a helper function that generates a description
+// message for the build based on the status.
+func getDesc(status string) string {
+	switch status {
+	case model.StatusPending:
+		return descPending
+	case model.StatusRunning:
+		return descRunning
+	case model.StatusSuccess:
+		return descSuccess
+	case model.StatusKilled:
+		return descCanceled
+	case model.StatusBlocked:
+		return descBlocked
+	case model.StatusDeclined:
+		return descDeclined
+	default:
+		return descFailure
+	}
+}
@@ -82,6 +82,49 @@ type Refresher interface {
 	Refresh(*model.User) (bool, error)
 }
 
+// TeamPermer fetches the named organization permissions of the user from
+// the remote system. It is an optional extension of the Remote interface.
+// Implementations may return a nil permission if organization permissions
+// are not supported.
+type TeamPermer interface {
+	TeamPerm(u *model.User, org string) (*model.Perm, error)
+}
+
+// Brancher resolves the head commit of a repository branch. It is an optional
+// extension of the Remote interface, used to create builds that are not
+// triggered by a hook, such as cron jobs.
+type Brancher interface {
+	BranchHead(u *model.User, r *model.Repo, branch string) (*model.Commit, error)
+}
+
+// ProcStatuser sends the commit status of an individual pipeline, such as
+// a matrix axis, to the remote system. It is an optional extension of the
+// Remote interface, used in addition to the aggregate build status.
+type ProcStatuser interface {
+	ProcStatus(u *model.User, r *model.Repo, b *model.Build, p *model.Proc, link string) error
+}
+
+// Checker reports the progress of an individual pipeline to the remote
+// system as a check run, including the pipeline steps and any line-level
+// annotations. It is an optional extension of the Remote interface.
+type Checker interface {
+	Check(u *model.User, r *model.Repo, b *model.Build, p *model.Proc, annotations []*model.Annotation, link string) error
+}
+
+// AppAuther reports whether the remote system accesses repositories as an
+// app installation instead of with the token of the repository owner. It is
+// an optional extension of the Remote interface.
+type AppAuther interface {
+	AppAuth() bool
+}
+
+// AppAuth returns true if the remote system accesses repositories as an
+// app installation.
+func AppAuth(r Remote) bool {
+	auther, ok := r.(AppAuther)
+	return ok && auther.AppAuth()
+}
+
 // Login authenticates the session and returns the
 // remote user details.
 func Login(c context.Context, w http.ResponseWriter, r *http.Request) (*model.User, error) {
@@ -99,6 +142,17 @@ func Teams(c context.Context, u *model.User) ([]*model.Team, error) {
 	return FromContext(c).Teams(u)
 }
 
+// TeamPerm fetches the named organization permissions of the user from the
+// remote system. It returns a nil permission if the remote system does not
+// support organization permissions.
+func TeamPerm(c context.Context, u *model.User, org string) (*model.Perm, error) {
+	permer, ok := FromContext(c).(TeamPermer)
+	if !ok {
+		return nil, nil
+	}
+	return permer.TeamPerm(u, org)
+}
+
 // Repo fetches the named repository from the remote system.
 func Repo(c context.Context, u *model.User, owner, repo string) (*model.Repo, error) {
 	return FromContext(c).Repo(u, owner, repo)
@@ -18,6 +18,7 @@ import (
 	"net/http"
 
 	"github.com/drone/drone/model"
+	"github.com/drone/drone/remote"
 	"github.com/drone/drone/shared/token"
 	"github.com/drone/drone/store"
 
@@ -116,6 +117,68 @@ func MustRepoAdmin() gin.HandlerFunc {
 	}
 }
 
+// MustOrgMember returns a middleware that only permits system admins,
+// the owner of a personal namespace, and members of the named organization.
+func MustOrgMember() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := User(c)
+		owner := c.Param("owner")
+		switch {
+		case user == nil:
+			c.String(401, "User not authorized")
+			c.Abort()
+		case user.Admin || user.Login == owner:
+			c.Next()
+		default:
+			teams, err := remote.Teams(c, user)
+			if err != nil {
+				c.String(500, "Error getting team memberships. %s", err)
+				c.Abort()
+				return
+			}
+			for _, team := range teams {
+				if team.Login == owner {
+					c.Next()
+					return
+				}
+			}
+			c.String(403, "User not authorized")
+			c.Abort()
+		}
+	}
+}
+
+// MustOrgAdmin returns a middleware that only permits system admins, the
+// owner of a personal namespace, and administrators of the named organization.
+// If the remote system does not report organization permissions, only system
+// admins and the namespace owner are permitted.
+func MustOrgAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := User(c)
+		owner := c.Param("owner")
+		switch {
+		case user == nil:
+			c.String(401, "User not authorized")
+			c.Abort()
+		case user.Admin || user.Login == owner:
+			c.Next()
+		default:
+			perm, err := remote.TeamPerm(c, user, owner)
+			if err != nil {
+				c.String(500, "Error getting organization permissions. %s", err)
+				c.Abort()
+				return
+			}
+			if perm == nil || !perm.Admin {
+				c.String(403, "User not authorized")
+				c.Abort()
+				return
+			}
+			c.Next()
+		}
+	}
+}
+
 func MustUser() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		user := User(c)
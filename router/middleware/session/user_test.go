@@ -0,0 +1,74 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/drone/drone/model"
+	"github.com/drone/drone/remote"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMustOrgAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		user *model.User
+		perm *model.Perm
+		code int
+	}{
+		// system admins are always permitted
+		{&model.User{Login: "jane", Admin: true}, nil, 200},
+		// namespace owners are always permitted
+		{&model.User{Login: "octocat"}, nil, 200},
+		// organization admins are permitted
+		{&model.User{Login: "jane"}, &model.Perm{Admin: true}, 200},
+		// organization members are not permitted
+		{&model.User{Login: "jane"}, &model.Perm{Push: true, Pull: true}, 403},
+		// organization permissions are not supported
+		{&model.User{Login: "jane"}, nil, 403},
+		// anonymous users are not permitted
+		{nil, nil, 401},
+	}
+
+	for i, test := range tests {
+		remote_ := &mockTeamPermer{perm: test.perm}
+
+		c, w, _ := gin.CreateTestContext()
+		c.Params = gin.Params{{Key: "owner", Value: "octocat"}}
+		c.Request, _ = http.NewRequest("POST", "/api/orgs/octocat/secrets", nil)
+		remote.ToContext(c, remote_)
+		if test.user != nil {
+			c.Set("user", test.user)
+		}
+
+		MustOrgAdmin()(c)
+		if got, want := w.Code, test.code; got != want {
+			t.Errorf("Test %d: want status code %d, got %d", i, want, got)
+		}
+	}
+}
+
+type mockTeamPermer struct {
+	remote.Remote
+	perm *model.Perm
+}
+
+func (m *mockTeamPermer) TeamPerm(u *model.User, org string) (*model.Perm, error) {
+	return m.perm, nil
+}
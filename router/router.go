@@ -75,6 +75,53 @@ func Load(mux *httptreemux.ContextMux, middleware ...gin.HandlerFunc) http.Handl
 		users.DELETE("/:login", server.DeleteUser)
 	}
 
+	secrets := e.Group("/api/secrets")
+	{
+		secrets.Use(session.MustAdmin())
+		secrets.GET("", server.GetOrgSecretList)
+		secrets.POST("", server.PostOrgSecret)
+		secrets.GET("/:secret", server.GetOrgSecret)
+		secrets.PATCH("/:secret", server.PatchOrgSecret)
+		secrets.DELETE("/:secret", server.DeleteOrgSecret)
+	}
+
+	retention := e.Group("/api/retention")
+	{
+		retention.Use(session.MustAdmin())
+		retention.GET("", server.GetRetention)
+		retention.POST("", server.PostRetention)
+		retention.DELETE("", server.DeleteRetention)
+		retention.GET("/report", server.GetRetentionReport)
+	}
+
+	audit := e.Group("/api/audit")
+	{
+		audit.Use(session.MustAdmin())
+		audit.GET("", server.GetAuditEvents)
+	}
+
+	webhooks := e.Group("/api/webhooks")
+	{
+		webhooks.Use(session.MustAdmin())
+		webhooks.GET("", server.GetWebhookList)
+		webhooks.POST("", server.PostWebhook)
+		webhooks.GET("/:webhook", server.GetWebhook)
+		webhooks.PATCH("/:webhook", server.PatchWebhook)
+		webhooks.DELETE("/:webhook", server.DeleteWebhook)
+		webhooks.GET("/:webhook/deliveries", server.GetWebhookDeliveries)
+		webhooks.POST("/:webhook/deliveries/:delivery", server.PostWebhookRedelivery)
+	}
+
+	orgs := e.Group("/api/orgs/:owner")
+	{
+		orgs.Use(session.MustOrgMember())
+		orgs.GET("/secrets", server.GetOrgSecretList)
+		orgs.POST("/secrets", session.MustOrgAdmin(), server.PostOrgSecret)
+		orgs.GET("/secrets/:secret", server.GetOrgSecret)
+		orgs.PATCH("/secrets/:secret", session.MustOrgAdmin(), server.PatchOrgSecret)
+		orgs.DELETE("/secrets/:secret", session.MustOrgAdmin(), server.DeleteOrgSecret)
+	}
+
 	repo := e.Group("/api/repos/:owner/:name")
 	{
 		repo.Use(session.SetRepo())
@@ -84,6 +131,7 @@ func Load(mux *httptreemux.ContextMux, middleware ...gin.HandlerFunc) http.Handl
 		repo.POST("", session.MustRepoAdmin(), server.PostRepo)
 		repo.GET("", server.GetRepo)
 		repo.GET("/builds", server.GetBuilds)
+		repo.GET("/stats", server.GetRepoStats)
 		repo.GET("/builds/:number", server.GetBuild)
 		repo.GET("/logs/:number/:pid", server.GetProcLogs)
 		repo.GET("/logs/:number/:pid/:proc", server.GetBuildLogs)
@@ -98,6 +146,24 @@ func Load(mux *httptreemux.ContextMux, middleware ...gin.HandlerFunc) http.Handl
 		repo.PATCH("/secrets/:secret", session.MustPush, server.PatchSecret)
 		repo.DELETE("/secrets/:secret", session.MustPush, server.DeleteSecret)
 
+		repo.GET("/crons", session.MustPush, server.GetCronList)
+		repo.POST("/crons", session.MustPush, server.PostCron)
+		repo.GET("/crons/:cron", session.MustPush, server.GetCron)
+		repo.PATCH("/crons/:cron", session.MustPush, server.PatchCron)
+		repo.DELETE("/crons/:cron", session.MustPush, server.DeleteCron)
+
+		repo.GET("/retention", session.MustRepoAdmin(), server.GetRetention)
+		repo.POST("/retention", session.MustRepoAdmin(), server.PostRetention)
+		repo.DELETE("/retention", session.MustRepoAdmin(), server.DeleteRetention)
+
+		repo.GET("/webhooks", session.MustRepoAdmin(), server.GetWebhookList)
+		repo.POST("/webhooks", session.MustRepoAdmin(), server.PostWebhook)
+		repo.GET("/webhooks/:webhook", session.MustRepoAdmin(), server.GetWebhook)
+		repo.PATCH("/webhooks/:webhook", session.MustRepoAdmin(), server.PatchWebhook)
+		repo.DELETE("/webhooks/:webhook", session.MustRepoAdmin(), server.DeleteWebhook)
+		repo.GET("/webhooks/:webhook/deliveries", session.MustRepoAdmin(), server.GetWebhookDeliveries)
+		repo.POST("/webhooks/:webhook/deliveries/:delivery", session.MustRepoAdmin(), server.PostWebhookRedelivery)
+
 		// requires push permissions
 		repo.GET("/registry", session.MustPush, server.GetRegistryList)
 		repo.POST("/registry", session.MustPush, server.PostRegistry)
@@ -116,7 +182,11 @@ func Load(mux *httptreemux.ContextMux, middleware ...gin.HandlerFunc) http.Handl
 		repo.DELETE("/builds/:number", session.MustRepoAdmin(), server.ZombieKill)
 		repo.POST("/builds/:number/approve", session.MustPush, server.PostApproval)
 		repo.POST("/builds/:number/decline", session.MustPush, server.PostDecline)
+		repo.POST("/builds/:number/promote", session.MustPush, server.PostPromotion)
 		repo.DELETE("/builds/:number/:job", session.MustPush, server.DeleteBuild)
+
+		repo.GET("/deployments", server.GetDeployments)
+		repo.POST("/deployments/:target/rollback", session.MustPush, server.PostRollback)
 		repo.DELETE("/logs/:number", session.MustPush, server.DeleteBuildLogs)
 	}
 
@@ -159,6 +229,24 @@ func Load(mux *httptreemux.ContextMux, middleware ...gin.HandlerFunc) http.Handl
 	{
 		builds.Use(session.MustAdmin())
 		builds.GET("", server.GetBuildQueue)
+		builds.GET("/search", server.GetBuildSearch)
+	}
+
+	agents := e.Group("/api/agents")
+	{
+		agents.Use(session.MustAdmin())
+		agents.GET("", server.GetAgents)
+		agents.GET("/:agent", server.GetAgent)
+		agents.PATCH("/:agent", server.PatchAgent)
+		agents.DELETE("/:agent", server.DeleteAgent)
+	}
+
+	tokens := e.Group("/api/agent-tokens")
+	{
+		tokens.Use(session.MustAdmin())
+		tokens.GET("", server.GetAgentTokens)
+		tokens.POST("", server.PostAgentToken)
+		tokens.DELETE("/:token", server.DeleteAgentToken)
 	}
 
 	debugger := e.Group("/api/debug")
@@ -0,0 +1,92 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net/http"
+
+	"github.com/drone/drone/model"
+	"github.com/drone/drone/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetAgents gets the list of registered agents from the database and
+// writes to the response in json format.
+func GetAgents(c *gin.Context) {
+	agents, err := store.FromContext(c).AgentList()
+	if err != nil {
+		c.String(500, "Error getting agent list. %s", err)
+		return
+	}
+	c.JSON(200, agents)
+}
+
+// GetAgent gets the named agent from the database and writes
+// to the response in json format.
+func GetAgent(c *gin.Context) {
+	name := c.Param("agent")
+	agent, err := store.FromContext(c).AgentFind(name)
+	if err != nil {
+		c.String(404, "Error getting agent %q. %s", name, err)
+		return
+	}
+	c.JSON(200, agent)
+}
+
+// PatchAgent updates the agent in the database. Draining an agent
+// prevents it from receiving new work.
+func PatchAgent(c *gin.Context) {
+	name := c.Param("agent")
+
+	in := new(model.AgentPatch)
+	if err := c.Bind(in); err != nil {
+		c.String(http.StatusBadRequest, "Error parsing agent. %s", err)
+		return
+	}
+
+	agent, err := store.FromContext(c).AgentFind(name)
+	if err != nil {
+		c.String(404, "Error getting agent %q. %s", name, err)
+		return
+	}
+	before := *agent
+	if in.Draining != nil {
+		agent.Draining = *in.Draining
+	}
+	if err := store.FromContext(c).AgentUpdate(agent); err != nil {
+		c.String(500, "Error updating agent %q. %s", name, err)
+		return
+	}
+	audit(c, "agent.update", nil, name, &before, agent)
+	c.JSON(200, agent)
+}
+
+// DeleteAgent deletes the named agent from the database. The agent
+// is registered again the next time it polls for work.
+func DeleteAgent(c *gin.Context) {
+	name := c.Param("agent")
+	agent, err := store.FromContext(c).AgentFind(name)
+	if err != nil {
+		c.String(404, "Error getting agent %q. %s", name, err)
+		return
+	}
+	if err := store.FromContext(c).AgentDelete(agent); err != nil {
+		c.String(500, "Error deleting agent %q. %s", name, err)
+		return
+	}
+	audit(c, "agent.delete", nil, name, agent, nil)
+	c.String(204, "")
+}
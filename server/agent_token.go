@@ -0,0 +1,155 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"encoding/base32"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/drone/drone/model"
+	"github.com/drone/drone/store"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/securecookie"
+)
+
+// GetAgentTokens gets the list of agent tokens from the database and
+// writes to the response in json format. Token values are never listed.
+func GetAgentTokens(c *gin.Context) {
+	tokens, err := store.FromContext(c).AgentTokenList()
+	if err != nil {
+		c.String(500, "Error getting agent token list. %s", err)
+		return
+	}
+	c.JSON(200, tokens)
+}
+
+// PostAgentToken issues a new agent token and writes the token to the
+// response in json format. This is the only time the token value is
+// available, since only its hash is persisted.
+func PostAgentToken(c *gin.Context) {
+	in := new(model.AgentToken)
+	if err := c.Bind(in); err != nil {
+		c.String(http.StatusBadRequest, "Error parsing agent token. %s", err)
+		return
+	}
+	token := &model.AgentToken{
+		Name:      in.Name,
+		Platforms: in.Platforms,
+		Labels:    in.Labels,
+		Created:   time.Now().Unix(),
+		Token: base32.StdEncoding.EncodeToString(
+			securecookie.GenerateRandomKey(32),
+		),
+	}
+	if err := token.Validate(); err != nil {
+		c.String(400, "Error inserting agent token. %s", err)
+		return
+	}
+	token.Hash = model.HashAgentToken(token.Token)
+
+	if err := store.FromContext(c).AgentTokenCreate(token); err != nil {
+		c.String(500, "Error inserting agent token %q. %s", in.Name, err)
+		return
+	}
+	audit(c, "agent.token.create", nil, token.Name, nil, token)
+	c.JSON(200, token)
+}
+
+// DeleteAgentToken revokes the agent token by deleting it from
+// the database and the token cache.
+func DeleteAgentToken(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("token"), 10, 64)
+	if err != nil {
+		c.String(400, "Error parsing agent token id. %s", err)
+		return
+	}
+	token, err := store.FromContext(c).AgentTokenFind(id)
+	if err != nil {
+		c.String(404, "Error getting agent token %d. %s", id, err)
+		return
+	}
+	if err := store.FromContext(c).AgentTokenDelete(token); err != nil {
+		c.String(500, "Error deleting agent token %d. %s", id, err)
+		return
+	}
+	agentTokens.forget(token.Hash)
+	audit(c, "agent.token.delete", nil, token.Name, token, nil)
+	c.String(204, "")
+}
+
+// agentTokenTTL is the duration an agent token lookup is cached. Revoked
+// tokens are removed from the cache immediately, the ttl bounds the time
+// a token revoked on another server continues to be accepted.
+const agentTokenTTL = time.Minute
+
+var agentTokens = new(agentTokenCache)
+
+// FindAgentToken returns the agent token with the given hash. Lookups are
+// cached because every agent rpc, including each log line, is authorized.
+func FindAgentToken(store_ model.AgentTokenStore, hash string) (*model.AgentToken, error) {
+	return agentTokens.find(store_, hash)
+}
+
+type agentTokenCache struct {
+	sync.Mutex
+	tokens map[string]*cachedAgentToken
+}
+
+type cachedAgentToken struct {
+	token   *model.AgentToken
+	expires time.Time
+}
+
+func (a *agentTokenCache) find(store_ model.AgentTokenStore, hash string) (*model.AgentToken, error) {
+	now := time.Now()
+
+	a.Lock()
+	cached, ok := a.tokens[hash]
+	a.Unlock()
+	if ok && now.Before(cached.expires) {
+		return cached.token, nil
+	}
+
+	token, err := store_.AgentTokenFindHash(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	a.Lock()
+	defer a.Unlock()
+	if a.tokens == nil {
+		a.tokens = map[string]*cachedAgentToken{}
+	}
+	for k, v := range a.tokens {
+		if now.After(v.expires) {
+			delete(a.tokens, k)
+		}
+	}
+	a.tokens[hash] = &cachedAgentToken{
+		token:   token,
+		expires: now.Add(agentTokenTTL),
+	}
+	return token, nil
+}
+
+func (a *agentTokenCache) forget(hash string) {
+	a.Lock()
+	delete(a.tokens, hash)
+	a.Unlock()
+}
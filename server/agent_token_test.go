@@ -0,0 +1,54 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"testing"
+
+	"github.com/drone/drone/model"
+)
+
+func TestAgentTokenCache(t *testing.T) {
+	store := &mockAgentTokenStore{token: &model.AgentToken{ID: 1, Hash: "abc"}}
+	cache := new(agentTokenCache)
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.find(store, "abc"); err != nil {
+			t.Error(err)
+		}
+	}
+	if got, want := store.finds, 1; got != want {
+		t.Errorf("Want %d token lookups, got %d", want, got)
+	}
+
+	cache.forget("abc")
+	if _, err := cache.find(store, "abc"); err != nil {
+		t.Error(err)
+	}
+	if got, want := store.finds, 2; got != want {
+		t.Errorf("Want revoked token looked up again, got %d lookups", got)
+	}
+}
+
+type mockAgentTokenStore struct {
+	model.AgentTokenStore
+	token *model.AgentToken
+	finds int
+}
+
+func (m *mockAgentTokenStore) AgentTokenFindHash(string) (*model.AgentToken, error) {
+	m.finds++
+	return m.token, nil
+}
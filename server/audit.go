@@ -0,0 +1,79 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/drone/drone/model"
+	"github.com/drone/drone/router/middleware/session"
+	"github.com/drone/drone/store"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/gin-gonic/gin"
+)
+
+// GetAuditEvents gets the audit events from the database and writes
+// to the response in json format. The events are filtered by actor,
+// repository and action, and paginated with the page and per_page
+// query parameters.
+func GetAuditEvents(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.String(400, "Error parsing page number. %s", err)
+		return
+	}
+	perPage, err := strconv.Atoi(c.DefaultQuery("per_page", "50"))
+	if err != nil || perPage < 1 || perPage > 100 {
+		c.String(400, "Error parsing per page. Expect a number between 1 and 100")
+		return
+	}
+	filter := &model.AuditFilter{
+		Actor:  c.Query("actor"),
+		Repo:   c.Query("repo"),
+		Action: c.Query("action"),
+		Limit:  perPage,
+		Offset: perPage * (page - 1),
+	}
+	list, err := store.FromContext(c).AuditList(filter)
+	if err != nil {
+		c.String(500, "Error getting audit events. %s", err)
+		return
+	}
+	c.JSON(200, list)
+}
+
+// audit records an action performed by the session user, and the diff
+// between the before and after state of the target. Errors are logged
+// and not returned, since the action already succeeded.
+func audit(c *gin.Context, action string, repo *model.Repo, target string, before, after interface{}) {
+	event := &model.AuditEvent{
+		Action:  action,
+		Target:  target,
+		IP:      c.ClientIP(),
+		Diff:    model.AuditDiff(before, after),
+		Created: time.Now().Unix(),
+	}
+	if user := session.User(c); user != nil {
+		event.Actor = user.Login
+	}
+	if repo != nil {
+		event.Repo = repo.FullName
+	}
+	if err := store.FromContext(c).AuditCreate(event); err != nil {
+		logrus.Errorf("failure to record audit event %s for %s. %s", action, target, err)
+	}
+}
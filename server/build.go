@@ -37,8 +37,34 @@ import (
 	"github.com/drone/drone/router/middleware/session"
 )
 
+// GetBuilds gets the repository builds matching the query filters and
+// writes to the response in json format. Builds are paginated by cursor,
+// and the next page is linked in the Link header. Builds are paginated
+// by page number instead when the page parameter is provided.
 func GetBuilds(c *gin.Context) {
 	repo := session.Repo(c)
+	if c.Query("page") != "" {
+		getBuildsPage(c, repo)
+		return
+	}
+
+	filter, err := buildFilterFromQuery(c)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Error parsing build filter. %s", err)
+		return
+	}
+	builds, err := store.GetBuildListFilter(c, repo, filter)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error getting build list. %s", err)
+		return
+	}
+	if len(builds) == filter.Limit {
+		setNextCursor(c, int64(builds[len(builds)-1].Number))
+	}
+	c.JSON(http.StatusOK, builds)
+}
+
+func getBuildsPage(c *gin.Context, repo *model.Repo) {
 	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
 	if err != nil {
 		c.AbortWithError(http.StatusBadRequest, err)
@@ -53,6 +79,63 @@ func GetBuilds(c *gin.Context) {
 	c.JSON(http.StatusOK, builds)
 }
 
+// buildFilterFromQuery returns the build filter from the request query
+// parameters. The created range accepts unix seconds or RFC 3339 times.
+func buildFilterFromQuery(c *gin.Context) (*model.BuildFilter, error) {
+	filter := &model.BuildFilter{
+		Branch: c.Query("branch"),
+		Event:  c.Query("event"),
+		Status: c.Query("status"),
+		Author: c.Query("author"),
+		Commit: c.Query("commit"),
+		Limit:  50,
+	}
+	var err error
+	if v := c.Query("per_page"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil || filter.Limit < 1 || filter.Limit > 100 {
+			return nil, fmt.Errorf("invalid per_page %q, expect a number between 1 and 100", v)
+		}
+	}
+	if v := c.Query("cursor"); v != "" {
+		if filter.Cursor, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid cursor %q", v)
+		}
+	}
+	if filter.After, err = parseQueryTime(c.Query("created_after")); err != nil {
+		return nil, err
+	}
+	if filter.Before, err = parseQueryTime(c.Query("created_before")); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// parseQueryTime parses the time in unix seconds or RFC 3339 format. An
+// empty value returns zero.
+func parseQueryTime(v string) (int64, error) {
+	if v == "" {
+		return 0, nil
+	}
+	if unix, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return unix, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expect unix seconds or RFC 3339", v)
+	}
+	return t.Unix(), nil
+}
+
+// setNextCursor links the next page of results in the Link header, by
+// replacing the cursor in the request query parameters.
+func setNextCursor(c *gin.Context, cursor int64) {
+	next := *c.Request.URL
+	query := next.Query()
+	query.Set("cursor", strconv.FormatInt(cursor, 10))
+	next.RawQuery = query.Encode()
+	c.Header("Link", fmt.Sprintf(`<%s%s>; rel="next"`, httputil.GetURL(c.Request), next.RequestURI()))
+}
+
 func GetBuild(c *gin.Context) {
 	if c.Param("number") == "latest" {
 		GetBuildLast(c)
@@ -115,7 +198,7 @@ func GetBuildLogs(c *gin.Context) {
 		return
 	}
 
-	rc, err := store.FromContext(c).LogFind(proc)
+	rc, err := Config.Storage.Logs.LogFind(proc)
 	if err != nil {
 		c.AbortWithError(404, err)
 		return
@@ -147,7 +230,7 @@ func GetProcLogs(c *gin.Context) {
 		return
 	}
 
-	rc, err := store.FromContext(c).LogFind(proc)
+	rc, err := Config.Storage.Logs.LogFind(proc)
 	if err != nil {
 		c.AbortWithError(404, err)
 		return
@@ -224,25 +307,12 @@ func ZombieKill(c *gin.Context) {
 		return
 	}
 
-	for _, proc := range procs {
-		if proc.Running() {
-			proc.State = model.StatusKilled
-			proc.ExitCode = 137
-			proc.Stopped = time.Now().Unix()
-			if proc.Started == 0 {
-				proc.Started = proc.Stopped
-			}
-		}
-	}
-
-	for _, proc := range procs {
-		store.FromContext(c).ProcUpdate(proc)
-		Config.Services.Queue.Error(context.Background(), fmt.Sprint(proc.ID), queue.ErrCancel)
-	}
+	killProcs(store.FromContext(c), procs)
 
 	build.Status = model.StatusKilled
 	build.Finished = time.Now().Unix()
 	store.FromContext(c).UpdateBuild(build)
+	finishDeployment(store.FromContext(c), build)
 
 	c.String(204, "")
 }
@@ -270,14 +340,6 @@ func PostApproval(c *gin.Context) {
 	build.Reviewed = time.Now().Unix()
 	build.Reviewer = user.Login
 
-	//
-	//
-	// This code is copied pasted until I have a chance
-	// to refactor into a proper function. Lots of changes
-	// and technical debt. No judgement please!
-	//
-	//
-
 	// fetch the build file from the database
 	conf, err := Config.Storage.Config.ConfigLoad(build.ConfigID)
 	if err != nil {
@@ -296,28 +358,10 @@ func PostApproval(c *gin.Context) {
 		c.String(500, "error updating build. %s", uerr)
 		return
 	}
+	audit(c, "build.approve", repo, strconv.Itoa(build.Number), nil, nil)
 
 	c.JSON(200, build)
 
-	// get the previous build so that we can send
-	// on status change notifications
-	last, _ := store.GetBuildLastBefore(c, repo, build.Branch, build.ID)
-	secs, err := Config.Services.Secrets.SecretListBuild(repo, build)
-	if err != nil {
-		logrus.Debugf("Error getting secrets for %s#%d. %s", repo.FullName, build.Number, err)
-	}
-	regs, err := Config.Services.Registries.RegistryList(repo)
-	if err != nil {
-		logrus.Debugf("Error getting registry credentials for %s#%d. %s", repo.FullName, build.Number, err)
-	}
-	envs := map[string]string{}
-	if Config.Services.Environ != nil {
-		globals, _ := Config.Services.Environ.EnvironList(repo)
-		for _, global := range globals {
-			envs[global.Name] = global.Value
-		}
-	}
-
 	defer func() {
 		uri := fmt.Sprintf("%s/%s/%d", httputil.GetURL(c.Request), repo.FullName, build.Number)
 		err = remote_.Status(user, repo, build, uri)
@@ -326,93 +370,9 @@ func PostApproval(c *gin.Context) {
 		}
 	}()
 
-	b := builder{
-		Repo:  repo,
-		Curr:  build,
-		Last:  last,
-		Netrc: netrc,
-		Secs:  secs,
-		Regs:  regs,
-		Link:  httputil.GetURL(c.Request),
-		Yaml:  conf.Data,
-		Envs:  envs,
-	}
-	items, err := b.Build()
-	if err != nil {
-		build.Status = model.StatusError
-		build.Started = time.Now().Unix()
-		build.Finished = build.Started
-		build.Error = err.Error()
-		store.UpdateBuild(c, build)
-		return
-	}
-
-	var pcounter = len(items)
-	for _, item := range items {
-		build.Procs = append(build.Procs, item.Proc)
-		item.Proc.BuildID = build.ID
-
-		for _, stage := range item.Config.Stages {
-			var gid int
-			for _, step := range stage.Steps {
-				pcounter++
-				if gid == 0 {
-					gid = pcounter
-				}
-				proc := &model.Proc{
-					BuildID: build.ID,
-					Name:    step.Alias,
-					PID:     pcounter,
-					PPID:    item.Proc.PID,
-					PGID:    gid,
-					State:   model.StatusPending,
-				}
-				build.Procs = append(build.Procs, proc)
-			}
-		}
-	}
-	store.FromContext(c).ProcCreate(build.Procs)
-
-	//
-	// publish topic
-	//
-	buildCopy := *build
-	buildCopy.Procs = model.Tree(buildCopy.Procs)
-	message := pubsub.Message{
-		Labels: map[string]string{
-			"repo":    repo.FullName,
-			"private": strconv.FormatBool(repo.IsPrivate),
-		},
-	}
-	message.Data, _ = json.Marshal(model.Event{
-		Type:  model.Enqueued,
-		Repo:  *repo,
-		Build: buildCopy,
-	})
-	// TODO remove global reference
-	Config.Services.Pubsub.Publish(c, "topic/events", message)
-
-	//
-	// end publish topic
-	//
-
-	for _, item := range items {
-		task := new(queue.Task)
-		task.ID = fmt.Sprint(item.Proc.ID)
-		task.Labels = map[string]string{}
-		task.Labels["platform"] = item.Platform
-		for k, v := range item.Labels {
-			task.Labels[k] = v
-		}
-
-		task.Data, _ = json.Marshal(rpc.Pipeline{
-			ID:      fmt.Sprint(item.Proc.ID),
-			Config:  item.Config,
-			Timeout: b.Repo.Timeout,
-		})
-
-		Config.Services.Logs.Open(context.Background(), task.ID)
-		Config.Services.Queue.Push(context.Background(), task)
+	envs := map[string]string{}
+	if err := queueBuild(store.FromContext(c), httputil.GetURL(c.Request), repo, build, conf, netrc, envs, nil); err != nil {
+		logrus.Errorf("failure to start build %s/%d. %s", repo.FullName, build.Number, err)
 	}
 }
 
@@ -444,6 +404,8 @@ func PostDecline(c *gin.Context) {
 		c.String(500, "error updating build. %s", err)
 		return
 	}
+	audit(c, "build.decline", repo, strconv.Itoa(build.Number), nil, nil)
+	finishDeployment(store.FromContext(c), build)
 
 	uri := fmt.Sprintf("%s/%s/%d", httputil.GetURL(c.Request), repo.FullName, build.Number)
 	err = remote_.Status(user, repo, build, uri)
@@ -463,6 +425,29 @@ func GetBuildQueue(c *gin.Context) {
 	c.JSON(200, out)
 }
 
+// GetBuildSearch gets the builds of all repositories matching the query
+// filters and writes to the response in json format. The repo parameter
+// filters by repository full name glob. Builds are paginated by cursor,
+// and the next page is linked in the Link header.
+func GetBuildSearch(c *gin.Context) {
+	filter, err := buildFilterFromQuery(c)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Error parsing build filter. %s", err)
+		return
+	}
+	filter.Repo = c.Query("repo")
+
+	feed, err := store.GetBuildSearch(c, filter)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error searching builds. %s", err)
+		return
+	}
+	if len(feed) == filter.Limit {
+		setNextCursor(c, feed[len(feed)-1].ID)
+	}
+	c.JSON(http.StatusOK, feed)
+}
+
 //
 //
 //
@@ -481,7 +466,14 @@ func PostBuild(c *gin.Context) {
 		return
 	}
 
-	user, err := store.GetUser(c, repo.UserID)
+	// a retry re-runs pipelines of the build, which cannot be matched
+	// if the event or deployment target changes the pipelines.
+	if c.Query("retry") != "" && (c.Query("event") != "" || c.Query("deploy_to") != "") {
+		c.String(400, "Error restarting build. Cannot retry pipelines with a different event or deployment target")
+		return
+	}
+
+	user, err := repoOwner(store.FromContext(c), remote_, repo)
 	if err != nil {
 		logrus.Errorf("failure to find repo owner %s. %s", repo.FullName, err)
 		c.AbortWithError(500, err)
@@ -527,6 +519,22 @@ func PostBuild(c *gin.Context) {
 		return
 	}
 
+	var retry *buildRetry
+	if pids := c.Query("retry"); pids != "" {
+		procs, err := store.FromContext(c).ProcList(build)
+		if err != nil {
+			logrus.Errorf("failure to get procs of build %d. %s", num, err)
+			c.AbortWithError(500, err)
+			return
+		}
+		prev := *build
+		retry, err = parseRetry(&prev, procs, pids)
+		if err != nil {
+			c.String(400, "Error restarting build. %s", err)
+			return
+		}
+	}
+
 	build.ID = 0
 	build.Number = 0
 	build.Parent = num
@@ -545,17 +553,11 @@ func PostBuild(c *gin.Context) {
 		build.Event = event
 	}
 
-	err = store.CreateBuild(c, build)
-	if err != nil {
-		c.String(500, err.Error())
-		return
-	}
-
 	// Read query string parameters into buildParams, exclude reserved params
 	var buildParams = map[string]string{}
 	for key, val := range c.Request.URL.Query() {
 		switch key {
-		case "fork", "event", "deploy_to":
+		case "fork", "event", "deploy_to", "retry":
 		default:
 			// We only accept string literals, because build parameters will be
 			// injected as environment variables
@@ -563,9 +565,65 @@ func PostBuild(c *gin.Context) {
 		}
 	}
 
+	// deployments are recorded in the deployment history of the
+	// target, with the restarted build as the deployed source.
+	var deployment *model.Deployment
+	if build.Event == model.EventDeploy && build.Deploy != "" {
+		deployment = &model.Deployment{
+			Source:  num,
+			Creator: session.User(c).Login,
+		}
+	}
+
+	if err := startBuild(c, repo, build, conf, netrc, buildParams, retry, deployment); err != nil {
+		if build.Status == model.StatusError {
+			c.JSON(500, build)
+		} else {
+			c.String(500, err.Error())
+		}
+		return
+	}
+
+	c.JSON(202, build)
+}
+
+// startBuild creates the build and schedules its pipelines for execution.
+// If retry is not nil, only the restarted pipelines are scheduled. If
+// deployment is not nil, it is recorded for the build before the build
+// is scheduled.
+func startBuild(c *gin.Context, repo *model.Repo, build *model.Build, conf *model.Config, netrc *model.Netrc, buildParams map[string]string, retry *buildRetry, deployment *model.Deployment) error {
+	store_ := store.FromContext(c)
+	err := store.CreateBuild(c, build)
+	if err != nil {
+		return err
+	}
+	if deployment != nil {
+		if err := createDeployment(store_, build, deployment); err != nil {
+			logrus.Errorf("failure to record deployment %s#%d. %s", repo.FullName, build.Number, err)
+			build.Status = model.StatusError
+			build.Started = time.Now().Unix()
+			build.Finished = build.Started
+			build.Error = err.Error()
+			store_.UpdateBuild(build)
+			return err
+		}
+	}
+	err = queueBuild(store_, httputil.GetURL(c.Request), repo, build, conf, netrc, buildParams, retry)
+	if err != nil && deployment != nil {
+		updateDeployment(store_, build, deployment)
+	}
+	return err
+}
+
+// queueBuild compiles the pipelines of a persisted build from the
+// configuration, creates its procs and schedules them for execution. If
+// retry is not nil, only the restarted pipelines are scheduled. If the
+// pipelines cannot be compiled or persisted the build is updated with
+// the error.
+func queueBuild(store_ store.Store, link string, repo *model.Repo, build *model.Build, conf *model.Config, netrc *model.Netrc, envs map[string]string, retry *buildRetry) error {
 	// get the previous build so that we can send
 	// on status change notifications
-	last, _ := store.GetBuildLastBefore(c, repo, build.Branch, build.ID)
+	last, _ := store_.GetBuildLastBefore(repo, build.Branch, build.ID)
 	secs, err := Config.Services.Secrets.SecretListBuild(repo, build)
 	if err != nil {
 		logrus.Debugf("Error getting secrets for %s#%d. %s", repo.FullName, build.Number, err)
@@ -577,7 +635,7 @@ func PostBuild(c *gin.Context) {
 	if Config.Services.Environ != nil {
 		globals, _ := Config.Services.Environ.EnvironList(repo)
 		for _, global := range globals {
-			buildParams[global.Name] = global.Value
+			envs[global.Name] = global.Value
 		}
 	}
 
@@ -588,9 +646,9 @@ func PostBuild(c *gin.Context) {
 		Netrc: netrc,
 		Secs:  secs,
 		Regs:  regs,
-		Link:  httputil.GetURL(c.Request),
+		Link:  link,
 		Yaml:  conf.Data,
-		Envs:  buildParams,
+		Envs:  envs,
 	}
 	items, err := b.Build()
 	if err != nil {
@@ -598,8 +656,8 @@ func PostBuild(c *gin.Context) {
 		build.Started = time.Now().Unix()
 		build.Finished = build.Started
 		build.Error = err.Error()
-		c.JSON(500, build)
-		return
+		store_.UpdateBuild(build)
+		return err
 	}
 
 	var pcounter = len(items)
@@ -627,18 +685,24 @@ func PostBuild(c *gin.Context) {
 		}
 	}
 
-	err = store.FromContext(c).ProcCreate(build.Procs)
+	if retry != nil {
+		retry.copyProcs(build.Procs)
+	}
+
+	err = store_.ProcCreate(build.Procs)
 	if err != nil {
-		logrus.Errorf("cannot restart %s#%d: %s", repo.FullName, build.Number, err)
+		logrus.Errorf("error persisting procs %s/%d: %s", repo.FullName, build.Number, err)
 		build.Status = model.StatusError
 		build.Started = time.Now().Unix()
 		build.Finished = build.Started
 		build.Error = err.Error()
-		c.JSON(500, build)
-		return
+		store_.UpdateBuild(build)
+		return err
 	}
 
-	c.JSON(202, build)
+	if retry != nil {
+		retry.copyResults(build, Config.Storage.Logs, Config.Storage.Files)
+	}
 
 	//
 	// publish topic
@@ -657,12 +721,15 @@ func PostBuild(c *gin.Context) {
 		Build: buildCopy,
 	})
 	// TODO remove global reference
-	Config.Services.Pubsub.Publish(c, "topic/events", message)
+	Config.Services.Pubsub.Publish(context.Background(), "topic/events", message)
 	//
 	// end publish topic
 	//
 
 	for _, item := range items {
+		if retry != nil && !retry.restart(item.Proc.PID) {
+			continue
+		}
 		task := new(queue.Task)
 		task.ID = fmt.Sprint(item.Proc.ID)
 		task.Labels = map[string]string{}
@@ -670,6 +737,7 @@ func PostBuild(c *gin.Context) {
 		for k, v := range item.Labels {
 			task.Labels[k] = v
 		}
+		task.Labels["repo"] = b.Repo.FullName
 
 		task.Data, _ = json.Marshal(rpc.Pipeline{
 			ID:      fmt.Sprint(item.Proc.ID),
@@ -680,6 +748,7 @@ func PostBuild(c *gin.Context) {
 		Config.Services.Logs.Open(context.Background(), task.ID)
 		Config.Services.Queue.Push(context.Background(), task)
 	}
+	return nil
 }
 
 //
@@ -712,7 +781,7 @@ func DeleteBuildLogs(c *gin.Context) {
 	for _, proc := range procs {
 		t := time.Now().UTC()
 		buf := bytes.NewBufferString(fmt.Sprintf(deleteStr, proc.Name, user.Login, t.Format(time.UnixDate)))
-		lerr := store.FromContext(c).LogSave(proc, buf)
+		lerr := Config.Storage.Logs.LogSave(proc, buf)
 		if lerr != nil {
 			err = lerr
 		}
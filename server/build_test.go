@@ -0,0 +1,73 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/drone/drone/remote"
+	"github.com/gin-gonic/gin"
+)
+
+func TestBuildFilterFromQuery(t *testing.T) {
+	c, _, _ := gin.CreateTestContext()
+	c.Request = httptest.NewRequest("GET", "/api/repos/octocat/hello-world/builds?branch=release/*&status=failure&author=alice&created_after=2018-01-01T00:00:00Z&cursor=42", nil)
+
+	filter, err := buildFilterFromQuery(c)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := filter.Branch, "release/*"; got != want {
+		t.Errorf("Want branch filter %s, got %s", want, got)
+	}
+	if got, want := filter.After, int64(1514764800); got != want {
+		t.Errorf("Want created after %d, got %d", want, got)
+	}
+	if got, want := filter.Cursor, int64(42); got != want {
+		t.Errorf("Want cursor %d, got %d", want, got)
+	}
+	if got, want := filter.Limit, 50; got != want {
+		t.Errorf("Want default limit %d, got %d", want, got)
+	}
+
+	c.Request = httptest.NewRequest("GET", "/api/repos/octocat/hello-world/builds?per_page=1000", nil)
+	if _, err := buildFilterFromQuery(c); err == nil {
+		t.Errorf("Want error for per_page out of range")
+	}
+}
+
+func TestSetNextCursor(t *testing.T) {
+	c, w, _ := gin.CreateTestContext()
+	c.Request = httptest.NewRequest("GET", "http://drone.example.com/api/repos/octocat/hello-world/builds?status=failure&cursor=42", nil)
+
+	setNextCursor(c, 12)
+	if got, want := w.Header().Get("Link"), `<http://drone.example.com/api/repos/octocat/hello-world/builds?cursor=12&status=failure>; rel="next"`; got != want {
+		t.Errorf("Want link header %s, got %s", want, got)
+	}
+}
+
+func TestPostBuildRetryEvent(t *testing.T) {
+	c, w, _ := gin.CreateTestContext()
+	c.Request = httptest.NewRequest("POST", "/api/repos/octocat/hello-world/builds/1?retry=2&event=deployment&deploy_to=production", nil)
+	c.Params = gin.Params{{Key: "number", Value: "1"}}
+	remote.ToContext(c, new(mockAppRemote))
+
+	PostBuild(c)
+	if got, want := w.Code, 400; got != want {
+		t.Errorf("Want status %d, got %d", want, got)
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/cncd/pubsub"
+	"github.com/cncd/queue"
+	"github.com/drone/drone/model"
+	"github.com/drone/drone/remote"
+	"github.com/drone/drone/shared/httputil"
+	"github.com/drone/drone/store"
+	"github.com/gin-gonic/gin"
+)
+
+// cancelSuperseded cancels the pending and running builds of the same ref
+// that are superseded by the build, if the repository enables automatic
+// cancellation for the build event.
+func cancelSuperseded(c *gin.Context, user *model.User, repo *model.Repo, build *model.Build) {
+	if !repo.CancelPrevious(build.Event) {
+		return
+	}
+	builds, err := store.GetBuildSuperseded(c, build)
+	if err != nil {
+		logrus.Errorf("failure to get superseded builds for %s#%d. %s", repo.FullName, build.Number, err)
+		return
+	}
+	reason := fmt.Sprintf("Cancelled, superseded by build #%d", build.Number)
+	for _, prev := range builds {
+		if err := cancelBuild(c, repo, prev, reason); err != nil {
+			logrus.Errorf("failure to cancel %s#%d. %s", repo.FullName, prev.Number, err)
+			continue
+		}
+		uri := fmt.Sprintf("%s/%s/%d", httputil.GetURL(c.Request), repo.FullName, prev.Number)
+		if err := remote.Status(c, user, repo, prev, uri); err != nil {
+			logrus.Errorf("error setting commit status for %s/%d: %v", repo.FullName, prev.Number, err)
+		}
+	}
+}
+
+// cancelBuild kills the pending and running procs of the build and
+// publishes the cancelled build.
+func cancelBuild(c *gin.Context, repo *model.Repo, build *model.Build, reason string) error {
+	procs, err := store.FromContext(c).ProcList(build)
+	if err != nil {
+		return err
+	}
+
+	killProcs(store.FromContext(c), procs)
+
+	build.Status = model.StatusKilled
+	build.Error = reason
+	build.Finished = time.Now().Unix()
+	if build.Started == 0 {
+		build.Started = build.Finished
+	}
+	if err := store.UpdateBuild(c, build); err != nil {
+		return err
+	}
+	finishDeployment(store.FromContext(c), build)
+
+	buildCopy := *build
+	buildCopy.Procs = model.Tree(procs)
+	message := pubsub.Message{
+		Labels: map[string]string{
+			"repo":    repo.FullName,
+			"private": strconv.FormatBool(repo.IsPrivate),
+		},
+	}
+	message.Data, _ = json.Marshal(model.Event{
+		Type:  model.Cancelled,
+		Repo:  *repo,
+		Build: buildCopy,
+	})
+	Config.Services.Pubsub.Publish(c, "topic/events", message)
+	return nil
+}
+
+// killProcs kills the pending and running procs. Pending pipelines are
+// evicted from the queue and running pipelines are cancelled on the
+// agent.
+func killProcs(store_ store.Store, procs []*model.Proc) {
+	now := time.Now().Unix()
+	for _, proc := range procs {
+		if !proc.Running() {
+			continue
+		}
+		proc.State = model.StatusKilled
+		proc.ExitCode = 137
+		proc.Stopped = now
+		if proc.Started == 0 {
+			proc.Started = proc.Stopped
+		}
+		store_.ProcUpdate(proc)
+
+		if proc.PPID != 0 {
+			continue
+		}
+		id := fmt.Sprint(proc.ID)
+		if err := Config.Services.Queue.Evict(context.Background(), id); err == nil {
+			Config.Services.Logs.Close(context.Background(), id)
+		} else {
+			Config.Services.Queue.Error(context.Background(), id, queue.ErrCancel)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/drone/drone/model"
+	"github.com/drone/drone/remote"
+)
+
+// checkWorkers is the number of workers sending check run updates.
+const checkWorkers = 4
+
+// checks sends the check run updates of all agent rpc calls.
+var checks = new(checkQueue)
+
+// checkQueue sends check run updates in the background, so that agent rpc
+// calls do not wait for the remote system. The updates of a pipeline are
+// sent in order by the same worker. Updates of a pipeline that are not yet
+// sent are replaced by newer updates, so that the queue never blocks and
+// the final update of the pipeline is always sent.
+type checkQueue struct {
+	sync.Mutex
+	once    sync.Once
+	pending []map[int64]func()
+	signals []chan struct{}
+}
+
+// push schedules the update of the pipeline with the given id.
+func (q *checkQueue) push(id int64, update func()) {
+	q.once.Do(q.start)
+
+	i := id % checkWorkers
+	q.Lock()
+	q.pending[i][id] = update
+	q.Unlock()
+
+	select {
+	case q.signals[i] <- struct{}{}:
+	default:
+	}
+}
+
+// helper function that starts the workers.
+func (q *checkQueue) start() {
+	q.pending = make([]map[int64]func(), checkWorkers)
+	q.signals = make([]chan struct{}, checkWorkers)
+	for i := range q.pending {
+		q.pending[i] = map[int64]func(){}
+		q.signals[i] = make(chan struct{}, 1)
+	}
+	for i := range q.signals {
+		go q.work(i)
+	}
+}
+
+// helper function that sends the pending updates of the worker until
+// none are left, each time it is signalled.
+func (q *checkQueue) work(i int) {
+	for range q.signals[i] {
+		for update := q.next(i); update != nil; update = q.next(i) {
+			update()
+		}
+	}
+}
+
+// helper function that removes and returns a pending update of the
+// worker, or nil if there are none.
+func (q *checkQueue) next(i int) func() {
+	q.Lock()
+	defer q.Unlock()
+	for id, update := range q.pending[i] {
+		delete(q.pending[i], id)
+		return update
+	}
+	return nil
+}
+
+// updateCheck reports the progress of the pipeline to the remote system as
+// a check run, if supported by the remote. Once the pipeline is complete the
+// annotations uploaded by the pipeline steps are attached. The check run is
+// updated in the background.
+func (s *RPC) updateCheck(repo *model.Repo, build *model.Build, pipeline *model.Proc, procs []*model.Proc) {
+	checker, ok := s.remote.(remote.Checker)
+	if !ok {
+		return
+	}
+
+	proc := *pipeline
+	proc.Children = nil
+	for _, child := range procs {
+		if child.PPID == proc.PID {
+			step := *child
+			proc.Children = append(proc.Children, &step)
+		}
+	}
+	repoCopy, buildCopy := *repo, *build
+	buildCopy.Procs = nil
+
+	checks.push(proc.ID, func() {
+		s.sendCheck(checker, &repoCopy, &buildCopy, &proc)
+	})
+}
+
+// helper function to send the check run of the pipeline.
+func (s *RPC) sendCheck(checker remote.Checker, repo *model.Repo, build *model.Build, proc *model.Proc) {
+	user, err := s.statusUser(repo)
+	if err != nil {
+		logrus.Errorf("error setting check run for %s/%d: %v", repo.FullName, build.Number, err)
+		return
+	}
+
+	var annotations []*model.Annotation
+	if !proc.Running() {
+		annotations = loadAnnotations(s.store, build, proc)
+	}
+
+	uri := fmt.Sprintf("%s/%s/%d/%d", s.host, repo.FullName, build.Number, proc.PID)
+	err = checker.Check(user, repo, build, proc, annotations, uri)
+	if err != nil {
+		logrus.Errorf("error setting check run for %s/%d/%d: %v", repo.FullName, build.Number, proc.PID, err)
+	}
+}
+
+// loadAnnotations loads the annotations uploaded by the steps of the
+// pipeline. Files that cannot be read or parsed are skipped.
+func loadAnnotations(files model.FileStore, build *model.Build, pipeline *model.Proc) []*model.Annotation {
+	list, err := files.FileList(build)
+	if err != nil {
+		logrus.Errorf("error listing files for build %d: %v", build.ID, err)
+		return nil
+	}
+	var annotations []*model.Annotation
+	for _, file := range list {
+		if file.Mime != model.AnnotationMime {
+			continue
+		}
+		for _, step := range pipeline.Children {
+			if step.ID != file.ProcID {
+				continue
+			}
+			rc, err := files.FileRead(step, file.Name)
+			if err != nil {
+				logrus.Errorf("error reading annotations %s: %v", file.Name, err)
+				break
+			}
+			parsed, err := model.ParseAnnotations(rc)
+			rc.Close()
+			if err != nil {
+				logrus.Errorf("error parsing annotations %s: %v", file.Name, err)
+				break
+			}
+			annotations = append(annotations, parsed...)
+		}
+	}
+	return annotations
+}
@@ -0,0 +1,86 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/drone/drone/model"
+)
+
+func TestLoadAnnotations(t *testing.T) {
+	build := &model.Build{ID: 1}
+	pipeline := &model.Proc{
+		ID:  1,
+		PID: 1,
+		Children: []*model.Proc{
+			{ID: 2, PID: 2, PPID: 1, Name: "lint"},
+			{ID: 3, PID: 3, PPID: 1, Name: "test"},
+		},
+	}
+	files := &mockFileStore{
+		data: map[int64][]byte{
+			2: []byte(`[{"path": "main.go", "line": 3, "level": "warning", "message": "missing comment"}]`),
+			3: []byte(`{`),
+			5: []byte(`[{"path": "main.go", "line": 3, "level": "warning", "message": "missing comment"}]`),
+		},
+		files: []*model.File{
+			{ID: 1, BuildID: 1, ProcID: 2, PID: 2, Name: "lint.json", Mime: model.AnnotationMime},
+			{ID: 2, BuildID: 1, ProcID: 3, PID: 3, Name: "test.json", Mime: model.AnnotationMime},
+			{ID: 3, BuildID: 1, ProcID: 2, PID: 2, Name: "report.xml", Mime: "application/vnd.drone.test+xml"},
+			{ID: 4, BuildID: 1, ProcID: 5, PID: 5, Name: "lint.json", Mime: model.AnnotationMime},
+		},
+	}
+
+	annotations := loadAnnotations(files, build, pipeline)
+	if got, want := len(annotations), 1; got != want {
+		t.Errorf("Want %d annotations, got %d", want, got)
+		return
+	}
+	if got, want := annotations[0].Path, "main.go"; got != want {
+		t.Errorf("Want annotation path %s, got %s", want, got)
+	}
+}
+
+func TestCheckQueue(t *testing.T) {
+	q := new(checkQueue)
+	started := make(chan struct{})
+	block := make(chan struct{})
+	sent := make(chan int, 10)
+
+	q.push(1, func() {
+		close(started)
+		<-block
+		sent <- 0
+	})
+	<-started
+	for i := 1; i < 10; i++ {
+		i := i
+		q.push(1, func() { sent <- i })
+	}
+	close(block)
+
+	if got, want := <-sent, 0; got != want {
+		t.Errorf("Want update %d sent first, got %d", want, got)
+	}
+	if got, want := <-sent, 9; got != want {
+		t.Errorf("Want pending updates replaced by update %d, got %d", want, got)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if got := len(sent); got != 0 {
+		t.Errorf("Want replaced updates not sent, got %d more updates", got)
+	}
+}
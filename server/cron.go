@@ -0,0 +1,150 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/drone/drone/model"
+	"github.com/drone/drone/router/middleware/session"
+	"github.com/drone/drone/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCron gets the named cron job from the database and writes
+// to the response in json format.
+func GetCron(c *gin.Context) {
+	var (
+		repo = session.Repo(c)
+		name = c.Param("cron")
+	)
+	cron, err := store.FromContext(c).CronFind(repo, name)
+	if err != nil {
+		c.String(404, "Error getting cron %q. %s", name, err)
+		return
+	}
+	c.JSON(200, cron)
+}
+
+// GetCronList gets the cron job list from the database and writes
+// to the response in json format.
+func GetCronList(c *gin.Context) {
+	repo := session.Repo(c)
+	list, err := store.FromContext(c).CronList(repo)
+	if err != nil {
+		c.String(500, "Error getting cron list. %s", err)
+		return
+	}
+	c.JSON(200, list)
+}
+
+// PostCron persists the cron job to the database.
+func PostCron(c *gin.Context) {
+	repo := session.Repo(c)
+
+	in := new(model.Cron)
+	if err := c.Bind(in); err != nil {
+		c.String(http.StatusBadRequest, "Error parsing cron. %s", err)
+		return
+	}
+	cron := &model.Cron{
+		RepoID:   repo.ID,
+		Name:     in.Name,
+		Expr:     in.Expr,
+		Branch:   in.Branch,
+		Disabled: in.Disabled,
+		Created:  time.Now().Unix(),
+	}
+	if cron.Branch == "" {
+		cron.Branch = repo.Branch
+	}
+	if err := cron.Validate(); err != nil {
+		c.String(400, "Error inserting cron. %s", err)
+		return
+	}
+	cron.Updated = cron.Created
+	cron.Update(time.Now())
+
+	if err := store.FromContext(c).CronCreate(cron); err != nil {
+		c.String(500, "Error inserting cron %q. %s", in.Name, err)
+		return
+	}
+	audit(c, "cron.create", repo, cron.Name, nil, cron)
+	c.JSON(200, cron)
+}
+
+// PatchCron updates the cron job in the database.
+func PatchCron(c *gin.Context) {
+	var (
+		repo = session.Repo(c)
+		name = c.Param("cron")
+	)
+
+	in := new(model.CronPatch)
+	if err := c.Bind(in); err != nil {
+		c.String(http.StatusBadRequest, "Error parsing cron. %s", err)
+		return
+	}
+
+	cron, err := store.FromContext(c).CronFind(repo, name)
+	if err != nil {
+		c.String(404, "Error getting cron %q. %s", name, err)
+		return
+	}
+	before := *cron
+	if in.Expr != nil {
+		cron.Expr = *in.Expr
+	}
+	if in.Branch != nil {
+		cron.Branch = *in.Branch
+	}
+	if in.Disabled != nil {
+		cron.Disabled = *in.Disabled
+	}
+	if err := cron.Validate(); err != nil {
+		c.String(400, "Error updating cron. %s", err)
+		return
+	}
+	cron.Updated = time.Now().Unix()
+	cron.Update(time.Now())
+
+	if err := store.FromContext(c).CronUpdate(cron); err != nil {
+		c.String(500, "Error updating cron %q. %s", name, err)
+		return
+	}
+	audit(c, "cron.update", repo, cron.Name, &before, cron)
+	c.JSON(200, cron)
+}
+
+// DeleteCron deletes the named cron job from the database.
+func DeleteCron(c *gin.Context) {
+	var (
+		repo = session.Repo(c)
+		name = c.Param("cron")
+	)
+	cron, err := store.FromContext(c).CronFind(repo, name)
+	if err != nil {
+		c.String(404, "Error getting cron %q. %s", name, err)
+		return
+	}
+	if err := store.FromContext(c).CronDelete(cron); err != nil {
+		c.String(500, "Error deleting cron %q. %s", name, err)
+		return
+	}
+	audit(c, "cron.delete", repo, cron.Name, cron, nil)
+	c.String(204, "")
+}
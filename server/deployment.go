@@ -0,0 +1,241 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/drone/drone/model"
+	"github.com/drone/drone/remote"
+	"github.com/drone/drone/router/middleware/session"
+	"github.com/drone/drone/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetDeployments gets the deployment history of the repository from
+// the database and writes to the response in json format. The history
+// is optionally filtered by deployment target.
+func GetDeployments(c *gin.Context) {
+	repo := session.Repo(c)
+	list, err := store.FromContext(c).DeploymentList(repo, c.Query("target"))
+	if err != nil {
+		c.String(500, "Error getting deployment list. %s", err)
+		return
+	}
+	c.JSON(200, list)
+}
+
+// PostPromotion promotes a successful build to the deployment target.
+// A build older than the build currently deployed or being deployed to
+// the target cannot be promoted; use a rollback instead.
+func PostPromotion(c *gin.Context) {
+	repo := session.Repo(c)
+
+	target := c.Query("target")
+	if target == "" {
+		c.String(400, "Error promoting build. Missing deployment target.")
+		return
+	}
+	num, err := strconv.Atoi(c.Param("number"))
+	if err != nil {
+		c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+	source, err := store.GetBuildNumber(c, repo, num)
+	if err != nil {
+		c.String(404, "Error getting build %d. %s", num, err)
+		return
+	}
+	if source.Status != model.StatusSuccess {
+		c.String(400, "Cannot promote a build with status %s", source.Status)
+		return
+	}
+
+	history, err := store.FromContext(c).DeploymentList(repo, target)
+	if err != nil {
+		c.String(500, "Error getting deployment list. %s", err)
+		return
+	}
+	number := sourceNumber(store.FromContext(c), source)
+	if latest := model.DeploymentLatest(history); latest != nil && latest.Source > number {
+		c.String(409, "Cannot promote build %d. Build %d is deployed to %s", number, latest.Source, target)
+		return
+	}
+	deploy(c, repo, source, target, false)
+}
+
+// PostRollback re-promotes the build of the previous successful
+// deployment to the deployment target.
+func PostRollback(c *gin.Context) {
+	var (
+		repo   = session.Repo(c)
+		target = c.Param("target")
+	)
+
+	history, err := store.FromContext(c).DeploymentList(repo, target)
+	if err != nil {
+		c.String(500, "Error getting deployment list. %s", err)
+		return
+	}
+	prev := model.DeploymentPrevious(history)
+	if prev == nil {
+		c.String(404, "Cannot rollback %s. No previous deployment found", target)
+		return
+	}
+	source, err := store.GetBuildNumber(c, repo, prev.Source)
+	if err != nil {
+		c.String(404, "Error getting build %d. %s", prev.Source, err)
+		return
+	}
+	deploy(c, repo, source, target, true)
+}
+
+// deploy creates a deployment build of the source build for the target
+// and records it in the deployment history before it is scheduled.
+func deploy(c *gin.Context, repo *model.Repo, source *model.Build, target string, rollback bool) {
+	remote_ := remote.FromContext(c)
+
+	user, err := repoOwner(store.FromContext(c), remote_, repo)
+	if err != nil {
+		logrus.Errorf("failure to find repo owner %s. %s", repo.FullName, err)
+		c.AbortWithError(500, err)
+		return
+	}
+
+	// if the remote has a refresh token, the current access token
+	// may be stale. Therefore, we should refresh prior to dispatching
+	// the job.
+	if refresher, ok := remote_.(remote.Refresher); ok {
+		ok, _ := refresher.Refresh(user)
+		if ok {
+			store.UpdateUser(c, user)
+		}
+	}
+
+	conf, err := Config.Storage.Config.ConfigLoad(source.ConfigID)
+	if err != nil {
+		logrus.Errorf("failure to get build config for %s. %s", repo.FullName, err)
+		c.AbortWithError(404, err)
+		return
+	}
+
+	netrc, err := remote_.Netrc(user, repo)
+	if err != nil {
+		logrus.Errorf("failure to generate netrc for %s. %s", repo.FullName, err)
+		c.AbortWithError(500, err)
+		return
+	}
+
+	build := *source
+	build.ID = 0
+	build.Number = 0
+	build.Parent = source.Number
+	build.Event = model.EventDeploy
+	build.Deploy = target
+	build.Status = model.StatusPending
+	build.Started = 0
+	build.Finished = 0
+	build.Enqueued = time.Now().UTC().Unix()
+	build.Error = ""
+	build.Procs = nil
+
+	// Read query string parameters into buildParams, exclude reserved params
+	var buildParams = map[string]string{}
+	for key, val := range c.Request.URL.Query() {
+		switch key {
+		case "target":
+		default:
+			buildParams[key] = val[0]
+		}
+	}
+
+	deployment := &model.Deployment{
+		Source:   sourceNumber(store.FromContext(c), source),
+		Rollback: rollback,
+		Creator:  session.User(c).Login,
+	}
+	if err := startBuild(c, repo, &build, conf, netrc, buildParams, nil, deployment); err != nil {
+		if build.Status == model.StatusError {
+			c.JSON(500, build)
+		} else {
+			c.String(500, err.Error())
+		}
+		return
+	}
+	action := "deployment.promote"
+	if rollback {
+		action = "deployment.rollback"
+	}
+	audit(c, action, repo, target, nil, deployment)
+	c.JSON(202, deployment)
+}
+
+// createDeployment records the deployment build in the deployment
+// history of its target. The caller sets the deployed source build,
+// the rollback flag and the creator.
+func createDeployment(store_ model.DeploymentStore, build *model.Build, deployment *model.Deployment) error {
+	deployment.RepoID = build.RepoID
+	deployment.BuildID = build.ID
+	deployment.Number = build.Number
+	deployment.Target = build.Deploy
+	deployment.Commit = build.Commit
+	deployment.Status = build.Status
+	deployment.Created = time.Now().Unix()
+	deployment.Updated = deployment.Created
+	return store_.DeploymentCreate(deployment)
+}
+
+// updateDeployment records the final status of the deployment build in
+// the deployment history.
+func updateDeployment(store_ model.DeploymentStore, build *model.Build, deployment *model.Deployment) {
+	deployment.Status = build.Status
+	deployment.Updated = build.Finished
+	if deployment.Updated == 0 {
+		deployment.Updated = time.Now().Unix()
+	}
+	if err := store_.DeploymentUpdate(deployment); err != nil {
+		logrus.Errorf("failure to update deployment for build_id %d. %s", build.ID, err)
+	}
+}
+
+// finishDeployment records the final status of the build in the
+// deployment history, if the build is a deployment.
+func finishDeployment(store_ model.DeploymentStore, build *model.Build) {
+	if build.Event != model.EventDeploy {
+		return
+	}
+	deployment, err := store_.DeploymentFindBuild(build)
+	if err != nil {
+		return
+	}
+	updateDeployment(store_, build, deployment)
+}
+
+// sourceNumber returns the number of the build deployed by the build.
+// Promoting a deployment build deploys the build it was created from.
+func sourceNumber(store_ model.DeploymentStore, build *model.Build) int {
+	if build.Event != model.EventDeploy {
+		return build.Number
+	}
+	deployment, err := store_.DeploymentFindBuild(build)
+	if err != nil {
+		return build.Number
+	}
+	return deployment.Source
+}
@@ -0,0 +1,62 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"testing"
+
+	"github.com/drone/drone/model"
+)
+
+func TestSourceNumber(t *testing.T) {
+	store := &mockDeploymentStore{deployment: &model.Deployment{Number: 12, Source: 9}}
+
+	if got, want := sourceNumber(store, &model.Build{Number: 10, Event: model.EventPush}), 10; got != want {
+		t.Errorf("Want source number %d, got %d", want, got)
+	}
+	if got, want := sourceNumber(store, &model.Build{Number: 12, Event: model.EventDeploy}), 9; got != want {
+		t.Errorf("Want source number %d of the deployed build, got %d", want, got)
+	}
+}
+
+func TestFinishDeployment(t *testing.T) {
+	store := &mockDeploymentStore{deployment: &model.Deployment{Number: 12, Status: model.StatusRunning}}
+
+	finishDeployment(store, &model.Build{Number: 12, Event: model.EventDeploy, Status: model.StatusKilled})
+	if got, want := store.deployment.Status, model.StatusKilled; got != want {
+		t.Errorf("Want deployment status %s, got %s", want, got)
+	}
+	if store.deployment.Updated == 0 {
+		t.Errorf("Want deployment updated time set")
+	}
+
+	finishDeployment(store, &model.Build{Number: 12, Event: model.EventPush, Status: model.StatusFailure})
+	if got, want := store.deployment.Status, model.StatusKilled; got != want {
+		t.Errorf("Want deployment status %s unchanged, got %s", want, got)
+	}
+}
+
+type mockDeploymentStore struct {
+	model.DeploymentStore
+	deployment *model.Deployment
+}
+
+func (m *mockDeploymentStore) DeploymentFindBuild(*model.Build) (*model.Deployment, error) {
+	return m.deployment, nil
+}
+
+func (m *mockDeploymentStore) DeploymentUpdate(*model.Deployment) error {
+	return nil
+}
@@ -20,6 +20,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/drone/drone/model"
 	"github.com/drone/drone/router/middleware/session"
 	"github.com/drone/drone/store"
 	"github.com/gin-gonic/gin"
@@ -102,7 +103,7 @@ func FileGet(c *gin.Context) {
 	defer rc.Close()
 
 	switch file.Mime {
-	case "application/vnd.drone.test+json":
+	case "application/vnd.drone.test+json", model.AnnotationMime:
 		c.Header("Content-Type", "application/json")
 	}
 
@@ -15,14 +15,11 @@
 package server
 
 import (
-	"context"
 	"crypto/sha256"
-	"encoding/json"
 	"fmt"
 	"math/rand"
 	"net/url"
 	"regexp"
-	"strconv"
 	"strings"
 	"time"
 
@@ -42,8 +39,6 @@ import (
 	"github.com/cncd/pipeline/pipeline/frontend/yaml/compiler"
 	"github.com/cncd/pipeline/pipeline/frontend/yaml/linter"
 	"github.com/cncd/pipeline/pipeline/frontend/yaml/matrix"
-	"github.com/cncd/pipeline/pipeline/rpc"
-	"github.com/cncd/pubsub"
 	"github.com/cncd/queue"
 )
 
@@ -60,10 +55,19 @@ func init() {
 	rand.Seed(time.Now().UnixNano())
 }
 
+// queueInfoLimits extends the queue information with the reason pending tasks
+// exceed the concurrency limits, keyed by task id.
+type queueInfoLimits struct {
+	queue.InfoT
+	Blocked map[string]string `json:"blocked,omitempty"`
+}
+
 func GetQueueInfo(c *gin.Context) {
-	c.IndentedJSON(200,
-		Config.Services.Queue.Info(c),
-	)
+	info := queueInfoLimits{InfoT: Config.Services.Queue.Info(c)}
+	if limiter, ok := Config.Services.Queue.(model.QueueLimiter); ok {
+		info.Blocked = limiter.Blocked(info.InfoT)
+	}
+	c.IndentedJSON(200, info)
 }
 
 func PostHook(c *gin.Context) {
@@ -121,7 +125,7 @@ func PostHook(c *gin.Context) {
 		return
 	}
 
-	if repo.UserID == 0 {
+	if repo.UserID == 0 && !remote.AppAuth(remote_) {
 		logrus.Warnf("ignoring hook. repo %s has no owner.", repo.FullName)
 		c.Writer.WriteHeader(204)
 		return
@@ -140,7 +144,7 @@ func PostHook(c *gin.Context) {
 		return
 	}
 
-	user, err := store.GetUser(c, repo.UserID)
+	user, err := repoOwner(store.FromContext(c), remote_, repo)
 	if err != nil {
 		logrus.Errorf("failure to find repo owner %s. %s", repo.FullName, err)
 		c.AbortWithError(500, err)
@@ -225,37 +229,27 @@ func PostHook(c *gin.Context) {
 		return
 	}
 
-	c.JSON(200, build)
-
-	if build.Status == model.StatusBlocked {
-		return
-	}
-
-	envs := map[string]string{}
-	if Config.Services.Environ != nil {
-		globals, _ := Config.Services.Environ.EnvironList(repo)
-		for _, global := range globals {
-			envs[global.Name] = global.Value
+	// deployment events are recorded in the deployment history of the
+	// target, with the deployment build itself as the deployed source.
+	var deployment *model.Deployment
+	if build.Event == model.EventDeploy && build.Deploy != "" {
+		deployment = &model.Deployment{
+			Source:  build.Number,
+			Creator: build.Sender,
+		}
+		if err := createDeployment(store.FromContext(c), build, deployment); err != nil {
+			logrus.Errorf("failure to record deployment %s#%d. %s", repo.FullName, build.Number, err)
+			deployment = nil
 		}
 	}
 
-	secs, err := Config.Services.Secrets.SecretListBuild(repo, build)
-	if err != nil {
-		logrus.Debugf("Error getting secrets for %s#%d. %s", repo.FullName, build.Number, err)
-	}
+	c.JSON(200, build)
 
-	regs, err := Config.Services.Registries.RegistryList(repo)
-	if err != nil {
-		logrus.Debugf("Error getting registry credentials for %s#%d. %s", repo.FullName, build.Number, err)
+	if build.Status == model.StatusBlocked {
+		return
 	}
 
-	// get the previous build so that we can send
-	// on status change notifications
-	last, _ := store.GetBuildLastBefore(c, repo, build.Branch, build.ID)
-
-	//
-	// BELOW: NEW
-	//
+	cancelSuperseded(c, user, repo, build)
 
 	defer func() {
 		uri := fmt.Sprintf("%s/%s/%d", httputil.GetURL(c.Request), repo.FullName, build.Number)
@@ -265,97 +259,12 @@ func PostHook(c *gin.Context) {
 		}
 	}()
 
-	b := builder{
-		Repo:  repo,
-		Curr:  build,
-		Last:  last,
-		Netrc: netrc,
-		Secs:  secs,
-		Regs:  regs,
-		Envs:  envs,
-		Link:  httputil.GetURL(c.Request),
-		Yaml:  conf.Data,
-	}
-	items, err := b.Build()
-	if err != nil {
-		build.Status = model.StatusError
-		build.Started = time.Now().Unix()
-		build.Finished = build.Started
-		build.Error = err.Error()
-		store.UpdateBuild(c, build)
-		return
-	}
-
-	var pcounter = len(items)
-
-	for _, item := range items {
-		build.Procs = append(build.Procs, item.Proc)
-		item.Proc.BuildID = build.ID
-
-		for _, stage := range item.Config.Stages {
-			var gid int
-			for _, step := range stage.Steps {
-				pcounter++
-				if gid == 0 {
-					gid = pcounter
-				}
-				proc := &model.Proc{
-					BuildID: build.ID,
-					Name:    step.Alias,
-					PID:     pcounter,
-					PPID:    item.Proc.PID,
-					PGID:    gid,
-					State:   model.StatusPending,
-				}
-				build.Procs = append(build.Procs, proc)
-			}
-		}
-	}
-	err = store.FromContext(c).ProcCreate(build.Procs)
-	if err != nil {
-		logrus.Errorf("error persisting procs %s/%d: %s", repo.FullName, build.Number, err)
-	}
-
-	//
-	// publish topic
-	//
-	message := pubsub.Message{
-		Labels: map[string]string{
-			"repo":    repo.FullName,
-			"private": strconv.FormatBool(repo.IsPrivate),
-		},
-	}
-	buildCopy := *build
-	buildCopy.Procs = model.Tree(buildCopy.Procs)
-	message.Data, _ = json.Marshal(model.Event{
-		Type:  model.Enqueued,
-		Repo:  *repo,
-		Build: buildCopy,
-	})
-	// TODO remove global reference
-	Config.Services.Pubsub.Publish(c, "topic/events", message)
-	//
-	// end publish topic
-	//
-
-	for _, item := range items {
-		task := new(queue.Task)
-		task.ID = fmt.Sprint(item.Proc.ID)
-		task.Labels = map[string]string{}
-		for k, v := range item.Labels {
-			task.Labels[k] = v
+	envs := map[string]string{}
+	if err := queueBuild(store.FromContext(c), httputil.GetURL(c.Request), repo, build, conf, netrc, envs, nil); err != nil {
+		logrus.Errorf("failure to start build %s/%d. %s", repo.FullName, build.Number, err)
+		if deployment != nil {
+			updateDeployment(store.FromContext(c), build, deployment)
 		}
-		task.Labels["platform"] = item.Platform
-		task.Labels["repo"] = b.Repo.FullName
-
-		task.Data, _ = json.Marshal(rpc.Pipeline{
-			ID:      fmt.Sprint(item.Proc.ID),
-			Config:  item.Config,
-			Timeout: b.Repo.Timeout,
-		})
-
-		Config.Services.Logs.Open(context.Background(), task.ID)
-		Config.Services.Queue.Push(context.Background(), task)
 	}
 }
 
@@ -0,0 +1,160 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net/http"
+	"path"
+
+	"github.com/drone/drone/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+//
+// The handlers below manage secrets shared by all repositories of an owner.
+// When the route does not include an owner the handlers manage the global
+// secrets shared by all repositories in the system.
+//
+
+// GetOrgSecret gets the named shared secret from the database and writes
+// to the response in json format.
+func GetOrgSecret(c *gin.Context) {
+	var (
+		owner = c.Param("owner")
+		name  = c.Param("secret")
+	)
+	secret, err := Config.Services.Secrets.OrgSecretFind(owner, name)
+	if err != nil {
+		c.String(404, "Error getting secret %q. %s", name, err)
+		return
+	}
+	c.JSON(200, secret.Copy())
+}
+
+// PostOrgSecret persists the shared secret to the database.
+func PostOrgSecret(c *gin.Context) {
+	owner := c.Param("owner")
+
+	in := new(model.Secret)
+	if err := c.Bind(in); err != nil {
+		c.String(http.StatusBadRequest, "Error parsing secret. %s", err)
+		return
+	}
+	secret := &model.Secret{
+		Owner:  owner,
+		Name:   in.Name,
+		Value:  in.Value,
+		Events: in.Events,
+		Images: in.Images,
+	}
+	if err := secret.Validate(); err != nil {
+		c.String(400, "Error inserting secret. %s", err)
+		return
+	}
+	if err := Config.Services.Secrets.OrgSecretCreate(owner, secret); err != nil {
+		c.String(500, "Error inserting secret %q. %s", in.Name, err)
+		return
+	}
+	audit(c, orgSecretAction(owner, "create"), nil, path.Join(owner, secret.Name), nil, secret)
+	c.JSON(200, secret.Copy())
+}
+
+// PatchOrgSecret updates the shared secret in the database.
+func PatchOrgSecret(c *gin.Context) {
+	var (
+		owner = c.Param("owner")
+		name  = c.Param("secret")
+	)
+
+	in := new(model.Secret)
+	err := c.Bind(in)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Error parsing secret. %s", err)
+		return
+	}
+
+	secret, err := Config.Services.Secrets.OrgSecretFind(owner, name)
+	if err != nil {
+		c.String(404, "Error getting secret %q. %s", name, err)
+		return
+	}
+	before := *secret
+	if in.Value != "" {
+		secret.Value = in.Value
+	}
+	if len(in.Events) != 0 {
+		secret.Events = in.Events
+	}
+	if len(in.Images) != 0 {
+		secret.Images = in.Images
+	}
+
+	if err := secret.Validate(); err != nil {
+		c.String(400, "Error updating secret. %s", err)
+		return
+	}
+	if err := Config.Services.Secrets.OrgSecretUpdate(owner, secret); err != nil {
+		c.String(500, "Error updating secret %q. %s", in.Name, err)
+		return
+	}
+	audit(c, orgSecretAction(owner, "update"), nil, path.Join(owner, secret.Name), &before, secret)
+	c.JSON(200, secret.Copy())
+}
+
+// GetOrgSecretList gets the shared secret list from the database and
+// writes to the response in json format.
+func GetOrgSecretList(c *gin.Context) {
+	owner := c.Param("owner")
+	list, err := Config.Services.Secrets.OrgSecretList(owner)
+	if err != nil {
+		c.String(500, "Error getting secret list. %s", err)
+		return
+	}
+	// copy the secret detail to remove the sensitive
+	// password and token fields.
+	for i, secret := range list {
+		list[i] = secret.Copy()
+	}
+	c.JSON(200, list)
+}
+
+// DeleteOrgSecret deletes the named shared secret from the database.
+func DeleteOrgSecret(c *gin.Context) {
+	var (
+		owner = c.Param("owner")
+		name  = c.Param("secret")
+	)
+	secret, err := Config.Services.Secrets.OrgSecretFind(owner, name)
+	if err != nil {
+		c.String(404, "Error getting secret %q. %s", name, err)
+		return
+	}
+	if err := Config.Services.Secrets.OrgSecretDelete(owner, name); err != nil {
+		c.String(500, "Error deleting secret %q. %s", name, err)
+		return
+	}
+	audit(c, orgSecretAction(owner, "delete"), nil, path.Join(owner, name), secret, nil)
+	c.String(204, "")
+}
+
+// orgSecretAction returns the audit action for the shared secret
+// operation. Secrets without an owner are global secrets.
+func orgSecretAction(owner, op string) string {
+	if owner == "" {
+		return "global.secret." + op
+	}
+	return "org.secret." + op
+}
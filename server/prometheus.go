@@ -0,0 +1,149 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"sync"
+
+	"github.com/cncd/queue"
+	"github.com/drone/drone/model"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/metadata"
+)
+
+var (
+	buildCount = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "drone",
+			Name:      "builds_total",
+			Help:      "Total number of finished builds.",
+		},
+		[]string{"repo", "event", "status"},
+	)
+
+	buildDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "drone",
+			Name:      "build_duration_seconds",
+			Help:      "Duration of finished builds, from start to finish.",
+			Buckets:   prometheus.ExponentialBuckets(15, 2, 10),
+		},
+	)
+
+	buildQueueWait = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "drone",
+			Name:      "build_queue_wait_seconds",
+			Help:      "Time builds wait in the queue, from enqueued to started.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 13),
+		},
+	)
+
+	// connectedAgents tracks the agents connected to this server.
+	connectedAgents = &agentTracker{conns: map[string]int{}}
+)
+
+func init() {
+	prometheus.MustRegister(
+		buildCount,
+		buildDuration,
+		buildQueueWait,
+		prometheus.NewGaugeFunc(
+			prometheus.GaugeOpts{
+				Namespace: "drone",
+				Name:      "pending_tasks",
+				Help:      "Number of tasks pending in the queue.",
+			},
+			func() float64 { return float64(queueInfo().Stats.Pending) },
+		),
+		prometheus.NewGaugeFunc(
+			prometheus.GaugeOpts{
+				Namespace: "drone",
+				Name:      "running_tasks",
+				Help:      "Number of tasks running on agents.",
+			},
+			func() float64 { return float64(queueInfo().Stats.Running) },
+		),
+		prometheus.NewGaugeFunc(
+			prometheus.GaugeOpts{
+				Namespace: "drone",
+				Name:      "connected_agents",
+				Help:      "Number of agents connected to this server.",
+			},
+			func() float64 { return float64(connectedAgents.count()) },
+		),
+	)
+}
+
+// queueInfo returns the queue information, or empty information when
+// the queue is not configured.
+func queueInfo() queue.InfoT {
+	if Config.Services.Queue == nil {
+		return queue.InfoT{}
+	}
+	return Config.Services.Queue.Info(context.Background())
+}
+
+// observeStarted records the queue wait time of a started build.
+func observeStarted(build *model.Build) {
+	if build.Enqueued != 0 && build.Started >= build.Enqueued {
+		buildQueueWait.Observe(float64(build.Started - build.Enqueued))
+	}
+}
+
+// observeFinished records the status and duration of a finished build.
+func observeFinished(repo *model.Repo, build *model.Build) {
+	buildCount.WithLabelValues(repo.FullName, build.Event, build.Status).Inc()
+	if build.Started != 0 && build.Finished >= build.Started {
+		buildDuration.Observe(float64(build.Finished - build.Started))
+	}
+}
+
+// agentTracker counts the agents with open connections, identified by
+// hostname. An agent is connected while it polls for work or waits on a
+// running pipeline.
+type agentTracker struct {
+	sync.Mutex
+	conns map[string]int
+}
+
+// connect registers a connection from the agent in the context, and
+// returns a function that unregisters the connection.
+func (t *agentTracker) connect(c context.Context) func() {
+	meta, ok := metadata.FromContext(c)
+	if !ok || len(meta["hostname"]) == 0 || meta["hostname"][0] == "" {
+		return func() {}
+	}
+	hostname := meta["hostname"][0]
+
+	t.Lock()
+	t.conns[hostname]++
+	t.Unlock()
+
+	return func() {
+		t.Lock()
+		if t.conns[hostname]--; t.conns[hostname] <= 0 {
+			delete(t.conns, hostname)
+		}
+		t.Unlock()
+	}
+}
+
+func (t *agentTracker) count() int {
+	t.Lock()
+	defer t.Unlock()
+	return len(t.conns)
+}
@@ -0,0 +1,47 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+)
+
+func TestAgentTracker(t *testing.T) {
+	tracker := &agentTracker{conns: map[string]int{}}
+
+	agent1 := metadata.NewIncomingContext(context.Background(), metadata.Pairs("hostname", "agent1"))
+	agent2 := metadata.NewIncomingContext(context.Background(), metadata.Pairs("hostname", "agent2"))
+
+	done1 := tracker.connect(agent1)
+	done2 := tracker.connect(agent1)
+	done3 := tracker.connect(agent2)
+	tracker.connect(context.Background())()
+
+	if got, want := tracker.count(), 2; got != want {
+		t.Errorf("Want %d connected agents, got %d", want, got)
+	}
+	done1()
+	done3()
+	if got, want := tracker.count(), 1; got != want {
+		t.Errorf("Want %d connected agents, got %d", want, got)
+	}
+	done2()
+	if got, want := tracker.count(), 0; got != want {
+		t.Errorf("Want %d connected agents, got %d", want, got)
+	}
+}
@@ -0,0 +1,161 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/drone/drone/model"
+	"github.com/drone/drone/store"
+)
+
+// Pruner periodically removes the logs, files and procs of builds that
+// fall outside the retention policy of their repository.
+type Pruner struct {
+	Store    store.Store
+	Logs     model.LogStore
+	Interval time.Duration
+}
+
+// PruneReport describes the builds of a repository that are pruned by
+// its retention policy.
+type PruneReport struct {
+	Repo   string           `json:"repo"`
+	Policy *model.Retention `json:"policy"`
+	Builds []int            `json:"builds"`
+
+	builds []*model.Build
+}
+
+// Start runs the pruner until the context is cancelled.
+func (p *Pruner) Start(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(p.Interval):
+			p.run(time.Now())
+		}
+	}
+}
+
+func (p *Pruner) run(now time.Time) {
+	reports, err := p.Plan(now)
+	if err != nil {
+		logrus.Errorf("retention: cannot plan pruning. %s", err)
+		return
+	}
+	for _, report := range reports {
+		for _, build := range report.builds {
+			if p.Logs != nil {
+				p.deleteLogs(build)
+			}
+			if report.Policy.DeleteBuilds {
+				err = p.Store.BuildDelete(build)
+			} else {
+				err = p.Store.BuildPrune(build)
+			}
+			if err != nil {
+				logrus.Errorf("retention: cannot prune %s#%d. %s", report.Repo, build.Number, err)
+			}
+		}
+		logrus.Debugf("retention: pruned %d builds from %s", len(report.builds), report.Repo)
+	}
+}
+
+// deleteLogs deletes the build logs from the log store, which may be
+// separate from the database.
+func (p *Pruner) deleteLogs(build *model.Build) {
+	procs, err := p.Store.ProcList(build)
+	if err != nil {
+		logrus.Errorf("retention: cannot list procs for build %d. %s", build.ID, err)
+		return
+	}
+	for _, proc := range procs {
+		if err := p.Logs.LogDelete(proc); err != nil {
+			logrus.Errorf("retention: cannot delete logs for proc %d. %s", proc.ID, err)
+		}
+	}
+}
+
+// Plan returns the builds that are pruned by the retention policies at
+// the given time, grouped by repository.
+func (p *Pruner) Plan(now time.Time) ([]*PruneReport, error) {
+	policies, err := p.Store.RetentionList()
+	if err != nil {
+		return nil, err
+	}
+	var global *model.Retention
+	repoPolicies := map[int64]*model.Retention{}
+	for _, policy := range policies {
+		if policy.RepoID == 0 {
+			global = policy
+		} else {
+			repoPolicies[policy.RepoID] = policy
+		}
+	}
+
+	var repos []*model.Repo
+	if global != nil {
+		repos, err = p.Store.RepoListActive()
+		if err != nil {
+			return nil, err
+		}
+	}
+	// repositories with a policy are pruned even when inactive.
+	listed := map[int64]bool{}
+	for _, repo := range repos {
+		listed[repo.ID] = true
+	}
+	for id := range repoPolicies {
+		if listed[id] {
+			continue
+		}
+		repo, err := p.Store.GetRepo(id)
+		if err != nil {
+			continue
+		}
+		repos = append(repos, repo)
+	}
+
+	var reports []*PruneReport
+	for _, repo := range repos {
+		policy, ok := repoPolicies[repo.ID]
+		if !ok {
+			policy = global
+		}
+		builds, err := p.Store.RetentionBuildList(repo)
+		if err != nil {
+			return nil, err
+		}
+		pruned := policy.Prune(builds, now.Unix())
+		if len(pruned) == 0 {
+			continue
+		}
+		report := &PruneReport{
+			Repo:   repo.FullName,
+			Policy: policy,
+			Builds: []int{},
+			builds: pruned,
+		}
+		for _, build := range pruned {
+			report.Builds = append(report.Builds, build.Number)
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
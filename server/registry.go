@@ -63,6 +63,7 @@ func PostRegistry(c *gin.Context) {
 		c.String(500, "Error inserting registry %q. %s", in.Address, err)
 		return
 	}
+	audit(c, "registry.create", repo, registry.Address, nil, registry)
 	c.JSON(200, in.Copy())
 }
 
@@ -85,6 +86,7 @@ func PatchRegistry(c *gin.Context) {
 		c.String(404, "Error getting registry %q. %s", name, err)
 		return
 	}
+	before := *registry
 	if in.Username != "" {
 		registry.Username = in.Username
 	}
@@ -106,6 +108,7 @@ func PatchRegistry(c *gin.Context) {
 		c.String(500, "Error updating registry %q. %s", in.Address, err)
 		return
 	}
+	audit(c, "registry.update", repo, registry.Address, &before, registry)
 	c.JSON(200, in.Copy())
 }
 
@@ -132,9 +135,15 @@ func DeleteRegistry(c *gin.Context) {
 		repo = session.Repo(c)
 		name = c.Param("registry")
 	)
+	registry, err := Config.Services.Registries.RegistryFind(repo, name)
+	if err != nil {
+		c.String(404, "Error getting registry %q. %s", name, err)
+		return
+	}
 	if err := Config.Services.Registries.RegistryDelete(repo, name); err != nil {
 		c.String(500, "Error deleting registry %q. %s", name, err)
 		return
 	}
+	audit(c, "registry.delete", repo, name, registry, nil)
 	c.String(204, "")
 }
@@ -100,6 +100,7 @@ func PostRepo(c *gin.Context) {
 		c.String(500, err.Error())
 		return
 	}
+	audit(c, "repo.activate", repo, repo.FullName, nil, repo)
 
 	c.JSON(200, repo)
 }
@@ -118,6 +119,7 @@ func PatchRepo(c *gin.Context) {
 		c.String(403, "Insufficient privileges")
 		return
 	}
+	before := *repo
 
 	if in.AllowPush != nil {
 		repo.AllowPush = *in.AllowPush
@@ -134,6 +136,12 @@ func PatchRepo(c *gin.Context) {
 	if in.IsGated != nil {
 		repo.IsGated = *in.IsGated
 	}
+	if in.CancelPush != nil {
+		repo.CancelPush = *in.CancelPush
+	}
+	if in.CancelPull != nil {
+		repo.CancelPull = *in.CancelPull
+	}
 	if in.IsTrusted != nil {
 		repo.IsTrusted = *in.IsTrusted
 	}
@@ -161,6 +169,7 @@ func PatchRepo(c *gin.Context) {
 		c.AbortWithError(http.StatusInternalServerError, err)
 		return
 	}
+	audit(c, "repo.update", repo, repo.FullName, &before, repo)
 
 	c.JSON(http.StatusOK, repo)
 }
@@ -168,6 +177,7 @@ func PatchRepo(c *gin.Context) {
 func ChownRepo(c *gin.Context) {
 	repo := session.Repo(c)
 	user := session.User(c)
+	before := *repo
 	repo.UserID = user.ID
 
 	err := store.UpdateRepo(c, repo)
@@ -175,6 +185,7 @@ func ChownRepo(c *gin.Context) {
 		c.AbortWithError(http.StatusInternalServerError, err)
 		return
 	}
+	audit(c, "repo.chown", repo, repo.FullName, &before, repo)
 	c.JSON(http.StatusOK, repo)
 }
 
@@ -187,6 +198,7 @@ func DeleteRepo(c *gin.Context) {
 	remote := remote.FromContext(c)
 	repo := session.Repo(c)
 	user := session.User(c)
+	before := *repo
 
 	repo.IsActive = false
 	repo.UserID = 0
@@ -206,6 +218,11 @@ func DeleteRepo(c *gin.Context) {
 	}
 
 	remote.Deactivate(user, repo, httputil.GetURL(c.Request))
+	if remove {
+		audit(c, "repo.delete", repo, repo.FullName, &before, nil)
+	} else {
+		audit(c, "repo.deactivate", repo, repo.FullName, &before, repo)
+	}
 	c.JSON(200, repo)
 }
 
@@ -282,6 +299,7 @@ func MoveRepo(c *gin.Context) {
 		c.AbortWithStatus(http.StatusUnauthorized)
 		return
 	}
+	before := *repo
 
 	repo.Name = from.Name
 	repo.Owner = from.Owner
@@ -299,6 +317,7 @@ func MoveRepo(c *gin.Context) {
 		c.AbortWithError(http.StatusInternalServerError, errStore)
 		return
 	}
+	audit(c, "repo.move", repo, repo.FullName, &before, repo)
 
 	// creates the jwt token used to verify the repository
 	t := token.New(token.HookToken, repo.FullName)
@@ -0,0 +1,119 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/drone/drone/model"
+	"github.com/drone/drone/router/middleware/session"
+	"github.com/drone/drone/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetRetention gets the retention policy from the database and writes
+// to the response in json format. The global policy is returned when
+// no repository is in context.
+func GetRetention(c *gin.Context) {
+	policy, err := store.FromContext(c).RetentionFind(retentionRepoID(c))
+	if err != nil {
+		c.String(404, "Error getting retention policy. %s", err)
+		return
+	}
+	c.JSON(200, policy)
+}
+
+// PostRetention creates or replaces the retention policy in the
+// database.
+func PostRetention(c *gin.Context) {
+	in := new(model.Retention)
+	if err := c.Bind(in); err != nil {
+		c.String(http.StatusBadRequest, "Error parsing retention policy. %s", err)
+		return
+	}
+	if err := in.Validate(); err != nil {
+		c.String(400, "Error saving retention policy. %s", err)
+		return
+	}
+
+	var (
+		repoID = retentionRepoID(c)
+		now    = time.Now().Unix()
+	)
+	var before *model.Retention
+	policy, err := store.FromContext(c).RetentionFind(repoID)
+	if err != nil {
+		policy = &model.Retention{RepoID: repoID, Created: now}
+	} else {
+		prev := *policy
+		before = &prev
+	}
+	policy.MaxAge = in.MaxAge
+	policy.KeepBuilds = in.KeepBuilds
+	policy.KeepSuccess = in.KeepSuccess
+	policy.DeleteBuilds = in.DeleteBuilds
+	policy.Updated = now
+
+	if policy.ID == 0 {
+		err = store.FromContext(c).RetentionCreate(policy)
+	} else {
+		err = store.FromContext(c).RetentionUpdate(policy)
+	}
+	if err != nil {
+		c.String(500, "Error saving retention policy. %s", err)
+		return
+	}
+	audit(c, "retention.update", session.Repo(c), "retention", before, policy)
+	c.JSON(200, policy)
+}
+
+// DeleteRetention deletes the retention policy from the database.
+func DeleteRetention(c *gin.Context) {
+	policy, err := store.FromContext(c).RetentionFind(retentionRepoID(c))
+	if err != nil {
+		c.String(404, "Error getting retention policy. %s", err)
+		return
+	}
+	if err := store.FromContext(c).RetentionDelete(policy); err != nil {
+		c.String(500, "Error deleting retention policy. %s", err)
+		return
+	}
+	audit(c, "retention.delete", session.Repo(c), "retention", policy, nil)
+	c.String(204, "")
+}
+
+// GetRetentionReport writes the builds that would be pruned by the
+// retention policies to the response in json format, without pruning.
+func GetRetentionReport(c *gin.Context) {
+	pruner := &Pruner{Store: store.FromContext(c)}
+	reports, err := pruner.Plan(time.Now())
+	if err != nil {
+		c.String(500, "Error getting retention report. %s", err)
+		return
+	}
+	if reports == nil {
+		reports = []*PruneReport{}
+	}
+	c.JSON(200, reports)
+}
+
+func retentionRepoID(c *gin.Context) int64 {
+	if repo := session.Repo(c); repo != nil {
+		return repo.ID
+	}
+	return 0
+}
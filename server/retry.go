@@ -0,0 +1,162 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/drone/drone/model"
+)
+
+// buildRetry describes a restart of the failed pipelines of a previous
+// build. Pipelines that are not restarted are copied to the new build,
+// including their logs and files, so that the new build shows the
+// complete result.
+type buildRetry struct {
+	prev  *model.Build
+	procs []*model.Proc
+	pids  map[int]bool
+}
+
+// parseRetry returns a buildRetry for the comma-separated list of proc
+// pids. A step pid restarts the pipeline it belongs to. Only failed
+// procs of a finished build can be restarted, since the pipelines that
+// are not restarted are copied with their final state.
+func parseRetry(prev *model.Build, procs []*model.Proc, s string) (*buildRetry, error) {
+	switch prev.Status {
+	case model.StatusPending, model.StatusRunning:
+		return nil, fmt.Errorf("build %d is not finished", prev.Number)
+	}
+	retry := &buildRetry{
+		prev:  prev,
+		procs: procs,
+		pids:  map[int]bool{},
+	}
+	for _, field := range strings.Split(s, ",") {
+		pid, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return nil, fmt.Errorf("invalid proc pid %q", field)
+		}
+		proc := retry.find(pid)
+		if proc == nil {
+			return nil, fmt.Errorf("proc %d not found", pid)
+		}
+		if !proc.Failing() {
+			return nil, fmt.Errorf("proc %d did not fail", pid)
+		}
+		if proc.PPID != 0 {
+			pid = proc.PPID
+		}
+		retry.pids[pid] = true
+	}
+	return retry, nil
+}
+
+// restart returns true if the pipeline with the given pid is restarted.
+func (r *buildRetry) restart(pid int) bool {
+	return r.pids[pid]
+}
+
+// find returns the proc of the previous build with the given pid.
+func (r *buildRetry) find(pid int) *model.Proc {
+	for _, proc := range r.procs {
+		if proc.PID == pid {
+			return proc
+		}
+	}
+	return nil
+}
+
+// copied returns the previous proc that is copied to the new proc, or
+// nil if the new proc belongs to a restarted pipeline.
+func (r *buildRetry) copied(proc *model.Proc) *model.Proc {
+	pid := proc.PID
+	if proc.PPID != 0 {
+		pid = proc.PPID
+	}
+	if r.restart(pid) {
+		return nil
+	}
+	return r.find(proc.PID)
+}
+
+// copyProcs copies the state of the pipelines that are not restarted to
+// the procs of the new build.
+func (r *buildRetry) copyProcs(procs []*model.Proc) {
+	for _, proc := range procs {
+		prev := r.copied(proc)
+		if prev == nil {
+			continue
+		}
+		proc.State = prev.State
+		proc.Error = prev.Error
+		proc.ExitCode = prev.ExitCode
+		proc.Started = prev.Started
+		proc.Stopped = prev.Stopped
+		proc.Machine = prev.Machine
+		proc.Platform = prev.Platform
+	}
+}
+
+// copyResults copies the logs and files of the pipelines that are not
+// restarted to the procs of the new build. Failures are logged and do
+// not prevent the build from starting.
+func (r *buildRetry) copyResults(build *model.Build, logs model.LogStore, files model.FileStore) {
+	copied := map[int]*model.Proc{}
+	for _, proc := range build.Procs {
+		prev := r.copied(proc)
+		if prev == nil || prev.PPID == 0 {
+			continue
+		}
+		copied[proc.PID] = proc
+
+		rc, err := logs.LogFind(prev)
+		if err != nil {
+			continue
+		}
+		if err := logs.LogSave(proc, rc); err != nil {
+			logrus.Errorf("cannot copy logs of proc %d to build %d. %s", prev.PID, build.ID, err)
+		}
+		rc.Close()
+	}
+
+	list, err := files.FileList(r.prev)
+	if err != nil {
+		logrus.Errorf("cannot list files of build %d. %s", r.prev.ID, err)
+		return
+	}
+	for _, file := range list {
+		proc, ok := copied[file.PID]
+		if !ok {
+			continue
+		}
+		rc, err := files.FileRead(r.find(file.PID), file.Name)
+		if err != nil {
+			logrus.Errorf("cannot read file %s of proc %d. %s", file.Name, file.PID, err)
+			continue
+		}
+		dup := *file
+		dup.ID = 0
+		dup.BuildID = build.ID
+		dup.ProcID = proc.ID
+		if err := files.FileCreate(&dup, rc); err != nil {
+			logrus.Errorf("cannot copy file %s of proc %d to build %d. %s", file.Name, file.PID, build.ID, err)
+		}
+		rc.Close()
+	}
+}
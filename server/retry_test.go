@@ -0,0 +1,156 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/cncd/pipeline/pipeline/rpc"
+	"github.com/drone/drone/model"
+)
+
+func TestParseRetry(t *testing.T) {
+	procs := retryProcs()
+
+	retry, err := parseRetry(&model.Build{ID: 1}, procs, "3, 4")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !retry.restart(4) {
+		t.Errorf("Want failed pipeline restarted")
+	}
+	if !retry.restart(2) {
+		t.Errorf("Want pipeline of failed step restarted")
+	}
+	if retry.restart(1) {
+		t.Errorf("Want successful pipeline not restarted")
+	}
+
+	for _, pids := range []string{"1", "5", "9", "x"} {
+		if _, err := parseRetry(&model.Build{ID: 1}, procs, pids); err == nil {
+			t.Errorf("Want error restarting procs %q", pids)
+		}
+	}
+
+	for _, status := range []string{model.StatusPending, model.StatusRunning} {
+		if _, err := parseRetry(&model.Build{ID: 1, Status: status}, procs, "4"); err == nil {
+			t.Errorf("Want error restarting procs of %s build", status)
+		}
+	}
+}
+
+func TestRetryCopy(t *testing.T) {
+	prev := &model.Build{ID: 1}
+	retry, err := parseRetry(prev, retryProcs(), "2")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	build := &model.Build{ID: 2}
+	for i, proc := range retryProcs() {
+		proc.ID = int64(i + 11)
+		proc.BuildID = build.ID
+		proc.State = model.StatusPending
+		proc.ExitCode = 0
+		build.Procs = append(build.Procs, proc)
+	}
+	retry.copyProcs(build.Procs)
+
+	if got, want := build.Procs[0].State, model.StatusSuccess; got != want {
+		t.Errorf("Want copied pipeline state %s, got %s", want, got)
+	}
+	if got, want := build.Procs[4].Machine, "agent1"; got != want {
+		t.Errorf("Want copied step machine %s, got %s", want, got)
+	}
+	if got, want := build.Procs[1].State, model.StatusPending; got != want {
+		t.Errorf("Want restarted pipeline state %s, got %s", want, got)
+	}
+	if got, want := build.Procs[2].ExitCode, 0; got != want {
+		t.Errorf("Want restarted step exit code %d, got %d", want, got)
+	}
+
+	logs := mockLogStore{
+		3: []*rpc.Line{{Proc: "test", Out: "FAIL"}},
+		5: []*rpc.Line{{Proc: "build", Out: "ok"}},
+	}
+	files := &mockFileStore{
+		data: map[int64][]byte{5: []byte("<testsuite/>"), 3: []byte("<testsuite/>")},
+		files: []*model.File{
+			{ID: 1, BuildID: 1, ProcID: 5, PID: 5, Name: "report.xml"},
+			{ID: 2, BuildID: 1, ProcID: 3, PID: 3, Name: "report.xml"},
+		},
+	}
+	retry.copyResults(build, logs, files)
+
+	if got, want := len(logs[15]), 1; got != want {
+		t.Errorf("Want %d copied log lines, got %d", want, got)
+	}
+	if _, ok := logs[13]; ok {
+		t.Errorf("Want logs of restarted step not copied")
+	}
+	if got, want := len(files.created), 1; got != want {
+		t.Errorf("Want %d copied files, got %d", want, got)
+		return
+	}
+	if got, want := files.created[0].ProcID, int64(15); got != want {
+		t.Errorf("Want copied file proc id %d, got %d", want, got)
+	}
+	if got, want := files.created[0].BuildID, int64(2); got != want {
+		t.Errorf("Want copied file build id %d, got %d", want, got)
+	}
+}
+
+// retryProcs returns the procs of a build with a successful and a failed
+// pipeline.
+func retryProcs() []*model.Proc {
+	return []*model.Proc{
+		{ID: 1, PID: 1, Name: "pipeline", State: model.StatusSuccess, Machine: "agent1"},
+		{ID: 2, PID: 2, Name: "pipeline", State: model.StatusFailure, ExitCode: 1},
+		{ID: 3, PID: 3, PPID: 2, Name: "test", State: model.StatusFailure, ExitCode: 1},
+		{ID: 4, PID: 4, PPID: 0, Name: "pipeline", State: model.StatusKilled},
+		{ID: 5, PID: 5, PPID: 1, Name: "build", State: model.StatusSuccess, Machine: "agent1"},
+	}
+}
+
+type mockFileStore struct {
+	data    map[int64][]byte
+	files   []*model.File
+	created []*model.File
+}
+
+func (m *mockFileStore) FileList(*model.Build) ([]*model.File, error) { return m.files, nil }
+
+func (m *mockFileStore) FileFind(*model.Proc, string) (*model.File, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *mockFileStore) FileRead(proc *model.Proc, name string) (io.ReadCloser, error) {
+	data, ok := m.data[proc.ID]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return ioutil.NopCloser(bytes.NewBuffer(data)), nil
+}
+
+func (m *mockFileStore) FileCreate(file *model.File, r io.Reader) error {
+	m.created = append(m.created, file)
+	return nil
+}
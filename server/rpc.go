@@ -17,10 +17,13 @@ package server
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"log"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	oldcontext "golang.org/x/net/context"
@@ -61,7 +64,7 @@ var Config = struct {
 		// Users  model.UserStore
 		// Repos  model.RepoStore
 		// Builds model.BuildStore
-		// Logs   model.LogStore
+		Logs   model.LogStore
 		Config model.ConfigStore
 		Files  model.FileStore
 		Procs  model.ProcStore
@@ -76,6 +79,9 @@ var Config = struct {
 		Pass           string
 		RepoConfig     string
 		SessionExpires time.Duration
+		// WebhookPrivate permits repository webhooks that target
+		// loopback, link-local or private network addresses.
+		WebhookPrivate bool
 		// Open bool
 		// Orgs map[string]struct{}
 		// Admins map[string]struct{}
@@ -98,10 +104,16 @@ type RPC struct {
 	logger logging.Log
 	store  store.Store
 	host   string
+
+	// procStatus enables a separate commit status for each
+	// pipeline in addition to the aggregate build status.
+	procStatus bool
 }
 
 // Next implements the rpc.Next function
 func (s *RPC) Next(c context.Context, filter rpc.Filter) (*rpc.Pipeline, error) {
+	defer connectedAgents.connect(c)()
+
 	metadata, ok := metadata.FromContext(c)
 	if ok {
 		hostname, ok := metadata["hostname"]
@@ -110,10 +122,34 @@ func (s *RPC) Next(c context.Context, filter rpc.Filter) (*rpc.Pipeline, error)
 		}
 	}
 
-	fn, err := createFilterFunc(filter)
+	// draining agents are not given new work. The request is held
+	// open so that the agent does not immediately poll again.
+	agent := s.checkin(c, filter)
+	if agent != nil && agent.Draining {
+		select {
+		case <-c.Done():
+		case <-time.After(agentHeartbeat):
+		}
+		return nil, nil
+	}
+
+	fn, err := createFilterFunc(filter, AgentTokenFromContext(c))
 	if err != nil {
 		return nil, err
 	}
+
+	var draining int32
+	if agent != nil {
+		ctx, cancel := context.WithCancel(c)
+		defer cancel()
+		go s.heartbeat(ctx, agent, &draining)
+
+		matches := fn
+		fn = func(task *queue.Task) bool {
+			return atomic.LoadInt32(&draining) == 0 && matches(task)
+		}
+	}
+
 	task, err := s.queue.Poll(c, fn)
 	if err != nil {
 		return nil, err
@@ -136,18 +172,136 @@ func (s *RPC) Next(c context.Context, filter rpc.Filter) (*rpc.Pipeline, error)
 	return pipeline, err
 }
 
+// agentHeartbeat is the interval at which polling agents are marked as
+// seen and their draining status is refreshed.
+const agentHeartbeat = time.Second * 30
+
+// checkin records the agent polling for work in the agent registry. It
+// returns nil if the agent does not identify itself.
+func (s *RPC) checkin(c context.Context, filter rpc.Filter) *model.Agent {
+	meta, ok := metadata.FromContext(c)
+	if !ok || len(meta["hostname"]) == 0 || meta["hostname"][0] == "" {
+		return nil
+	}
+	hostname := meta["hostname"][0]
+
+	agent, err := s.store.AgentFind(hostname)
+	if err != nil {
+		agent = &model.Agent{
+			Hostname: hostname,
+			Created:  time.Now().Unix(),
+		}
+	}
+	agent.Platform = filter.Labels["platform"]
+	agent.Labels = filter.Labels
+	agent.Filter = filter.Expr
+	agent.LastSeen = time.Now().Unix()
+	if v := meta["capacity"]; len(v) != 0 {
+		agent.Capacity, _ = strconv.Atoi(v[0])
+	}
+	if v := meta["version"]; len(v) != 0 {
+		agent.Version = v[0]
+	}
+
+	// only the reported metadata is written so that concurrent
+	// changes to the agent, such as draining, are preserved.
+	if agent.ID == 0 {
+		// retry as a checkin in case a parallel worker of the
+		// same agent created the record at the same time.
+		if err = s.store.AgentCreate(agent); err != nil {
+			if existing, ferr := s.store.AgentFind(hostname); ferr == nil {
+				agent.ID = existing.ID
+				agent.Created = existing.Created
+				agent.Draining = existing.Draining
+				err = s.store.AgentCheckin(agent)
+			}
+		}
+	} else {
+		err = s.store.AgentCheckin(agent)
+	}
+	if err != nil {
+		logrus.Errorf("agent registry: cannot record agent %s: %s", hostname, err)
+	}
+	return agent
+}
+
+// heartbeat periodically marks the polling agent as seen and sets the
+// draining flag when an administrator drains the agent.
+func (s *RPC) heartbeat(c context.Context, agent *model.Agent, draining *int32) {
+	for {
+		select {
+		case <-c.Done():
+			return
+		case <-time.After(agentHeartbeat):
+		}
+
+		current, err := s.store.AgentFind(agent.Hostname)
+		if err != nil {
+			continue
+		}
+		if current.Draining {
+			atomic.StoreInt32(draining, 1)
+		}
+		current.LastSeen = time.Now().Unix()
+		if err := s.store.AgentSeen(current); err != nil {
+			logrus.Errorf("agent registry: cannot update agent %s: %s", agent.Hostname, err)
+		}
+	}
+}
+
+// agentsSeen records when busy agents were last marked as seen.
+var agentsSeen = struct {
+	sync.Mutex
+	last map[string]time.Time
+}{last: map[string]time.Time{}}
+
+// seen marks the agent as seen when it reports progress, so that agents
+// running pipelines on all slots, and therefore not polling, are not
+// reported as stale. The agent is updated at most once per heartbeat.
+func (s *RPC) seen(c context.Context) {
+	meta, ok := metadata.FromContext(c)
+	if !ok || len(meta["hostname"]) == 0 || meta["hostname"][0] == "" {
+		return
+	}
+	hostname := meta["hostname"][0]
+
+	now := time.Now()
+	agentsSeen.Lock()
+	due := now.Sub(agentsSeen.last[hostname]) >= agentHeartbeat
+	if due {
+		agentsSeen.last[hostname] = now
+	}
+	agentsSeen.Unlock()
+	if !due {
+		return
+	}
+
+	agent, err := s.store.AgentFind(hostname)
+	if err != nil {
+		return
+	}
+	agent.LastSeen = now.Unix()
+	if err := s.store.AgentSeen(agent); err != nil {
+		logrus.Errorf("agent registry: cannot update agent %s: %s", hostname, err)
+	}
+}
+
 // Wait implements the rpc.Wait function
 func (s *RPC) Wait(c context.Context, id string) error {
+	defer connectedAgents.connect(c)()
 	return s.queue.Wait(c, id)
 }
 
 // Extend implements the rpc.Extend function
 func (s *RPC) Extend(c context.Context, id string) error {
+	s.seen(c)
 	return s.queue.Extend(c, id)
 }
 
 // Update implements the rpc.Update function
 func (s *RPC) Update(c context.Context, id string, state rpc.State) error {
+	s.seen(c)
+
 	procID, err := strconv.ParseInt(id, 10, 64)
 	if err != nil {
 		return err
@@ -210,6 +364,7 @@ func (s *RPC) Update(c context.Context, id string, state rpc.State) error {
 	}
 
 	build.Procs, _ = s.store.ProcList(build)
+	s.updateCheck(repo, build, pproc, build.Procs)
 	build.Procs = model.Tree(build.Procs)
 	message := pubsub.Message{
 		Labels: map[string]string{
@@ -252,7 +407,7 @@ func (s *RPC) Upload(c context.Context, id string, file *rpc.File) error {
 	}
 
 	if file.Mime == "application/json+logs" {
-		return s.store.LogSave(
+		return Config.Storage.Logs.LogSave(
 			proc,
 			bytes.NewBuffer(file.Data),
 		)
@@ -301,6 +456,8 @@ func (s *RPC) Upload(c context.Context, id string, file *rpc.File) error {
 
 // Init implements the rpc.Init function
 func (s *RPC) Init(c context.Context, id string, state rpc.State) error {
+	s.seen(c)
+
 	procID, err := strconv.ParseInt(id, 10, 64)
 	if err != nil {
 		return err
@@ -331,12 +488,15 @@ func (s *RPC) Init(c context.Context, id string, state rpc.State) error {
 		return err
 	}
 
+	var event model.EventType
 	if build.Status == model.StatusPending {
+		event = model.Started
 		build.Status = model.StatusRunning
 		build.Started = state.Started
 		if err := s.store.UpdateBuild(build); err != nil {
 			log.Printf("error: init: cannot update build_id %d state: %s", build.ID, err)
 		}
+		observeStarted(build)
 	}
 
 	defer func() {
@@ -348,6 +508,7 @@ func (s *RPC) Init(c context.Context, id string, state rpc.State) error {
 			},
 		}
 		message.Data, _ = json.Marshal(model.Event{
+			Type:  event,
 			Repo:  *repo,
 			Build: *build,
 		})
@@ -356,11 +517,19 @@ func (s *RPC) Init(c context.Context, id string, state rpc.State) error {
 
 	proc.Started = state.Started
 	proc.State = model.StatusRunning
-	return s.store.ProcUpdate(proc)
+	if err := s.store.ProcUpdate(proc); err != nil {
+		return err
+	}
+
+	procs, _ := s.store.ProcList(build)
+	s.updateCheck(repo, build, proc, procs)
+	return nil
 }
 
 // Done implements the rpc.Done function
 func (s *RPC) Done(c context.Context, id string, state rpc.State) error {
+	s.seen(c)
+
 	procID, err := strconv.ParseInt(id, 10, 64)
 	if err != nil {
 		return err
@@ -414,6 +583,9 @@ func (s *RPC) Done(c context.Context, id string, state rpc.State) error {
 		}
 	}
 
+	s.updateCheck(repo, build, proc, procs)
+
+	var event model.EventType
 	running := false
 	status := model.StatusSuccess
 	for _, p := range procs {
@@ -427,21 +599,28 @@ func (s *RPC) Done(c context.Context, id string, state rpc.State) error {
 		}
 	}
 	if !running {
+		event = model.Finished
 		build.Status = status
 		build.Finished = proc.Stopped
 		if err := s.store.UpdateBuild(build); err != nil {
 			log.Printf("error: done: cannot update build_id %d final state: %s", build.ID, err)
 		}
+		observeFinished(repo, build)
+		finishDeployment(s.store, build)
+	}
 
-		// update the status
-		user, err := s.store.GetUser(repo.UserID)
-		if err == nil {
-			if refresher, ok := s.remote.(remote.Refresher); ok {
-				ok, _ := refresher.Refresh(user)
-				if ok {
-					s.store.UpdateUser(user)
-				}
+	// update the status
+	if statuser, ok := s.remote.(remote.ProcStatuser); ok && s.procStatus && isMatrix(procs) {
+		if user, err := s.statusUser(repo); err == nil {
+			uri := fmt.Sprintf("%s/%s/%d/%d", s.host, repo.FullName, build.Number, proc.PID)
+			err = statuser.ProcStatus(user, repo, build, proc, uri)
+			if err != nil {
+				logrus.Errorf("error setting commit status for %s/%d/%d: %v", repo.FullName, build.Number, proc.PID, err)
 			}
+		}
+	}
+	if !running {
+		if user, err := s.statusUser(repo); err == nil {
 			uri := fmt.Sprintf("%s/%s/%d", s.host, repo.FullName, build.Number)
 			err = s.remote.Status(user, repo, build, uri)
 			if err != nil {
@@ -462,6 +641,7 @@ func (s *RPC) Done(c context.Context, id string, state rpc.State) error {
 		},
 	}
 	message.Data, _ = json.Marshal(model.Event{
+		Type:  event,
 		Repo:  *repo,
 		Build: *build,
 	})
@@ -470,6 +650,51 @@ func (s *RPC) Done(c context.Context, id string, state rpc.State) error {
 	return nil
 }
 
+// statusUser returns the repository owner used to send the commit
+// status, refreshing the oauth token if required.
+func (s *RPC) statusUser(repo *model.Repo) (*model.User, error) {
+	user, err := repoOwner(s.store, s.remote, repo)
+	if err != nil {
+		return nil, err
+	}
+	if refresher, ok := s.remote.(remote.Refresher); ok {
+		ok, _ := refresher.Refresh(user)
+		if ok {
+			s.store.UpdateUser(user)
+		}
+	}
+	return user, nil
+}
+
+// repoOwner returns the repository owner, whose token is used to access
+// the repository. If the remote system accesses repositories as an app
+// installation the owner is optional, and a placeholder user is returned
+// when the repository has no owner or the owner no longer exists.
+func repoOwner(store_ store.Store, remote_ remote.Remote, repo *model.Repo) (*model.User, error) {
+	if !remote.AppAuth(remote_) {
+		return store_.GetUser(repo.UserID)
+	}
+	if repo.UserID == 0 {
+		return &model.User{Login: repo.Owner}, nil
+	}
+	user, err := store_.GetUser(repo.UserID)
+	if err == sql.ErrNoRows {
+		return &model.User{Login: repo.Owner}, nil
+	}
+	return user, err
+}
+
+// isMatrix returns true if the build has more than one pipeline.
+func isMatrix(procs []*model.Proc) bool {
+	var n int
+	for _, p := range procs {
+		if p.PPID == 0 {
+			n++
+		}
+	}
+	return n > 1
+}
+
 // Log implements the rpc.Log function
 func (s *RPC) Log(c context.Context, id string, line *rpc.Line) error {
 	entry := new(logging.Entry)
@@ -493,7 +718,10 @@ func (s *RPC) checkCancelled(pipeline *rpc.Pipeline) (bool, error) {
 	return false, err
 }
 
-func createFilterFunc(filter rpc.Filter) (queue.Filter, error) {
+// createFilterFunc returns a queue filter that matches tasks against the
+// agent filter. If the agent authenticated with an agent token, tasks must
+// also match the platform and label restrictions of the token.
+func createFilterFunc(filter rpc.Filter, token *model.AgentToken) (queue.Filter, error) {
 	var st *expr.Selector
 	var err error
 
@@ -505,6 +733,9 @@ func createFilterFunc(filter rpc.Filter) (queue.Filter, error) {
 	}
 
 	return func(task *queue.Task) bool {
+		if token != nil && !token.Match(task.Labels) {
+			return false
+		}
 		if st != nil {
 			match, _ := st.Eval(expr.NewRow(task.Labels))
 			return match
@@ -519,6 +750,21 @@ func createFilterFunc(filter rpc.Filter) (queue.Filter, error) {
 	}, nil
 }
 
+type agentTokenKey struct{}
+
+// WithAgentToken returns a copy of the context with the agent token used
+// to authenticate the agent.
+func WithAgentToken(c context.Context, token *model.AgentToken) context.Context {
+	return context.WithValue(c, agentTokenKey{}, token)
+}
+
+// AgentTokenFromContext returns the agent token used to authenticate the
+// agent, or nil if the agent authenticated with the shared secret.
+func AgentTokenFromContext(c context.Context) *model.AgentToken {
+	token, _ := c.Value(agentTokenKey{}).(*model.AgentToken)
+	return token
+}
+
 //
 //
 //
@@ -531,16 +777,21 @@ type DroneServer struct {
 	Logger logging.Log
 	Store  store.Store
 	Host   string
+
+	// ProcStatus enables a separate commit status for each pipeline
+	// in addition to the aggregate build status.
+	ProcStatus bool
 }
 
 func (s *DroneServer) Next(c oldcontext.Context, req *proto.NextRequest) (*proto.NextReply, error) {
 	peer := RPC{
-		remote: s.Remote,
-		store:  s.Store,
-		queue:  s.Queue,
-		pubsub: s.Pubsub,
-		logger: s.Logger,
-		host:   s.Host,
+		remote:     s.Remote,
+		store:      s.Store,
+		queue:      s.Queue,
+		pubsub:     s.Pubsub,
+		logger:     s.Logger,
+		host:       s.Host,
+		procStatus: s.ProcStatus,
 	}
 	filter := rpc.Filter{
 		Labels: req.GetFilter().GetLabels(),
@@ -602,12 +853,13 @@ func (s *DroneServer) Next(c oldcontext.Context, req *proto.NextRequest) (*proto
 
 func (s *DroneServer) Init(c oldcontext.Context, req *proto.InitRequest) (*proto.Empty, error) {
 	peer := RPC{
-		remote: s.Remote,
-		store:  s.Store,
-		queue:  s.Queue,
-		pubsub: s.Pubsub,
-		logger: s.Logger,
-		host:   s.Host,
+		remote:     s.Remote,
+		store:      s.Store,
+		queue:      s.Queue,
+		pubsub:     s.Pubsub,
+		logger:     s.Logger,
+		host:       s.Host,
+		procStatus: s.ProcStatus,
 	}
 	state := rpc.State{
 		Error:    req.GetState().GetError(),
@@ -624,12 +876,13 @@ func (s *DroneServer) Init(c oldcontext.Context, req *proto.InitRequest) (*proto
 
 func (s *DroneServer) Update(c oldcontext.Context, req *proto.UpdateRequest) (*proto.Empty, error) {
 	peer := RPC{
-		remote: s.Remote,
-		store:  s.Store,
-		queue:  s.Queue,
-		pubsub: s.Pubsub,
-		logger: s.Logger,
-		host:   s.Host,
+		remote:     s.Remote,
+		store:      s.Store,
+		queue:      s.Queue,
+		pubsub:     s.Pubsub,
+		logger:     s.Logger,
+		host:       s.Host,
+		procStatus: s.ProcStatus,
 	}
 	state := rpc.State{
 		Error:    req.GetState().GetError(),
@@ -646,12 +899,13 @@ func (s *DroneServer) Update(c oldcontext.Context, req *proto.UpdateRequest) (*p
 
 func (s *DroneServer) Upload(c oldcontext.Context, req *proto.UploadRequest) (*proto.Empty, error) {
 	peer := RPC{
-		remote: s.Remote,
-		store:  s.Store,
-		queue:  s.Queue,
-		pubsub: s.Pubsub,
-		logger: s.Logger,
-		host:   s.Host,
+		remote:     s.Remote,
+		store:      s.Store,
+		queue:      s.Queue,
+		pubsub:     s.Pubsub,
+		logger:     s.Logger,
+		host:       s.Host,
+		procStatus: s.ProcStatus,
 	}
 	file := &rpc.File{
 		Data: req.GetFile().GetData(),
@@ -670,12 +924,13 @@ func (s *DroneServer) Upload(c oldcontext.Context, req *proto.UploadRequest) (*p
 
 func (s *DroneServer) Done(c oldcontext.Context, req *proto.DoneRequest) (*proto.Empty, error) {
 	peer := RPC{
-		remote: s.Remote,
-		store:  s.Store,
-		queue:  s.Queue,
-		pubsub: s.Pubsub,
-		logger: s.Logger,
-		host:   s.Host,
+		remote:     s.Remote,
+		store:      s.Store,
+		queue:      s.Queue,
+		pubsub:     s.Pubsub,
+		logger:     s.Logger,
+		host:       s.Host,
+		procStatus: s.ProcStatus,
 	}
 	state := rpc.State{
 		Error:    req.GetState().GetError(),
@@ -692,12 +947,13 @@ func (s *DroneServer) Done(c oldcontext.Context, req *proto.DoneRequest) (*proto
 
 func (s *DroneServer) Wait(c oldcontext.Context, req *proto.WaitRequest) (*proto.Empty, error) {
 	peer := RPC{
-		remote: s.Remote,
-		store:  s.Store,
-		queue:  s.Queue,
-		pubsub: s.Pubsub,
-		logger: s.Logger,
-		host:   s.Host,
+		remote:     s.Remote,
+		store:      s.Store,
+		queue:      s.Queue,
+		pubsub:     s.Pubsub,
+		logger:     s.Logger,
+		host:       s.Host,
+		procStatus: s.ProcStatus,
 	}
 	res := new(proto.Empty)
 	err := peer.Wait(c, req.GetId())
@@ -706,12 +962,13 @@ func (s *DroneServer) Wait(c oldcontext.Context, req *proto.WaitRequest) (*proto
 
 func (s *DroneServer) Extend(c oldcontext.Context, req *proto.ExtendRequest) (*proto.Empty, error) {
 	peer := RPC{
-		remote: s.Remote,
-		store:  s.Store,
-		queue:  s.Queue,
-		pubsub: s.Pubsub,
-		logger: s.Logger,
-		host:   s.Host,
+		remote:     s.Remote,
+		store:      s.Store,
+		queue:      s.Queue,
+		pubsub:     s.Pubsub,
+		logger:     s.Logger,
+		host:       s.Host,
+		procStatus: s.ProcStatus,
 	}
 	res := new(proto.Empty)
 	err := peer.Extend(c, req.GetId())
@@ -720,12 +977,13 @@ func (s *DroneServer) Extend(c oldcontext.Context, req *proto.ExtendRequest) (*p
 
 func (s *DroneServer) Log(c oldcontext.Context, req *proto.LogRequest) (*proto.Empty, error) {
 	peer := RPC{
-		remote: s.Remote,
-		store:  s.Store,
-		queue:  s.Queue,
-		pubsub: s.Pubsub,
-		logger: s.Logger,
-		host:   s.Host,
+		remote:     s.Remote,
+		store:      s.Store,
+		queue:      s.Queue,
+		pubsub:     s.Pubsub,
+		logger:     s.Logger,
+		host:       s.Host,
+		procStatus: s.ProcStatus,
 	}
 	line := &rpc.Line{
 		Out:  req.GetLine().GetOut(),
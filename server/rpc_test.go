@@ -0,0 +1,126 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/drone/drone/model"
+	"github.com/drone/drone/remote"
+	"github.com/drone/drone/store"
+
+	"google.golang.org/grpc/metadata"
+)
+
+func TestRepoOwner(t *testing.T) {
+	repo := &model.Repo{UserID: 1, Owner: "octocat", FullName: "octocat/hello-world"}
+	users := &mockUserStore{users: map[int64]*model.User{}}
+
+	if _, err := repoOwner(users, &mockAppRemote{}, repo); err == nil {
+		t.Errorf("Want error without the repository owner")
+	}
+
+	user, err := repoOwner(users, &mockAppRemote{app: true}, repo)
+	if err != nil {
+		t.Errorf("Want placeholder owner in app mode, got error %s", err)
+		return
+	}
+	if got, want := user.Login, "octocat"; got != want {
+		t.Errorf("Want placeholder login %s, got %s", want, got)
+	}
+
+	user, err = repoOwner(users, &mockAppRemote{app: true}, &model.Repo{Owner: "octocat"})
+	if err != nil {
+		t.Errorf("Want placeholder owner for repository without owner, got error %s", err)
+		return
+	}
+	if got, want := user.Login, "octocat"; got != want {
+		t.Errorf("Want placeholder login %s, got %s", want, got)
+	}
+
+	users.users[1] = &model.User{ID: 1, Login: "bradrydzewski"}
+	user, _ = repoOwner(users, &mockAppRemote{app: true}, repo)
+	if got, want := user.Login, "bradrydzewski"; got != want {
+		t.Errorf("Want repository owner %s, got %s", want, got)
+	}
+
+	users.err = errors.New("database is locked")
+	if _, err := repoOwner(users, &mockAppRemote{app: true}, repo); err != users.err {
+		t.Errorf("Want database error returned in app mode, got %v", err)
+	}
+}
+
+func TestRPCSeen(t *testing.T) {
+	store := &mockAgentStore{agent: &model.Agent{ID: 1, Hostname: "agent01"}}
+	s := &RPC{store: store}
+	c := metadata.NewIncomingContext(context.Background(), metadata.Pairs("hostname", "agent01"))
+
+	agentsSeen.Lock()
+	delete(agentsSeen.last, "agent01")
+	agentsSeen.Unlock()
+
+	s.seen(c)
+	if store.agent.LastSeen == 0 {
+		t.Errorf("Want busy agent marked as seen")
+	}
+
+	// the agent is updated at most once per heartbeat.
+	store.agent.LastSeen = 0
+	s.seen(c)
+	if got := store.agent.LastSeen; got != 0 {
+		t.Errorf("Want agent update throttled, got last seen %d", got)
+	}
+}
+
+type mockAgentStore struct {
+	store.Store
+	agent *model.Agent
+}
+
+func (m *mockAgentStore) AgentFind(string) (*model.Agent, error) {
+	agent := *m.agent
+	return &agent, nil
+}
+
+func (m *mockAgentStore) AgentSeen(agent *model.Agent) error {
+	m.agent.LastSeen = agent.LastSeen
+	return nil
+}
+
+type mockUserStore struct {
+	store.Store
+	users map[int64]*model.User
+	err   error
+}
+
+func (m *mockUserStore) GetUser(id int64) (*model.User, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if user, ok := m.users[id]; ok {
+		return user, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+type mockAppRemote struct {
+	remote.Remote
+	app bool
+}
+
+func (m *mockAppRemote) AppAuth() bool { return m.app }
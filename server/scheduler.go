@@ -0,0 +1,172 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/drone/drone/model"
+	"github.com/drone/drone/remote"
+	"github.com/drone/drone/store"
+)
+
+// Scheduler periodically creates builds for cron jobs that are due.
+type Scheduler struct {
+	Remote   remote.Remote
+	Store    store.Store
+	Interval time.Duration
+}
+
+// Start runs the scheduler until the context is cancelled.
+func (s *Scheduler) Start(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(s.Interval):
+			s.run(time.Now())
+		}
+	}
+}
+
+func (s *Scheduler) run(now time.Time) {
+	crons, err := s.Store.CronListReady(now.Unix())
+	if err != nil {
+		logrus.Errorf("cron: cannot list pending jobs. %s", err)
+		return
+	}
+	for _, cron := range crons {
+		// the next execution is scheduled before the build is created
+		// so that a failing job is not retried on every interval. The
+		// job is claimed with a conditional update so that it is only
+		// executed once when multiple servers share the database.
+		next := cron.Next
+		cron.Prev = now.Unix()
+		cron.Update(now)
+		claimed, err := s.Store.CronClaim(cron, next)
+		if err != nil {
+			logrus.Errorf("cron: cannot update job %d. %s", cron.ID, err)
+			continue
+		}
+		if !claimed {
+			logrus.Debugf("cron: ignoring job %s. claimed by another server.", cron.Name)
+			continue
+		}
+		if err := s.exec(cron); err != nil {
+			logrus.Errorf("cron: cannot execute job %s. %s", cron.Name, err)
+		}
+	}
+}
+
+func (s *Scheduler) exec(cron *model.Cron) error {
+	repo, err := s.Store.GetRepo(cron.RepoID)
+	if err != nil {
+		return err
+	}
+	if !repo.IsActive || repo.UserID == 0 && !remote.AppAuth(s.Remote) {
+		logrus.Debugf("cron: ignoring job %s. repo %s is inactive.", cron.Name, repo.FullName)
+		return nil
+	}
+
+	user, err := repoOwner(s.Store, s.Remote, repo)
+	if err != nil {
+		return err
+	}
+
+	// if the remote has a refresh token, the current access token
+	// may be stale. Therefore, we should refresh prior to dispatching
+	// the build.
+	if refresher, ok := s.Remote.(remote.Refresher); ok {
+		ok, _ := refresher.Refresh(user)
+		if ok {
+			s.Store.UpdateUser(user)
+		}
+	}
+
+	branch := cron.Branch
+	if branch == "" {
+		branch = repo.Branch
+	}
+
+	build := &model.Build{
+		RepoID:    repo.ID,
+		Event:     model.EventCron,
+		Status:    model.StatusPending,
+		Ref:       "refs/heads/" + branch,
+		Branch:    branch,
+		Message:   fmt.Sprintf("cron: %s", cron.Name),
+		Sender:    cron.Name,
+		Link:      repo.Link,
+		Timestamp: time.Now().Unix(),
+		Verified:  true,
+	}
+
+	// if supported, the build is created for the head commit of the
+	// branch, the same as a push hook, so that the commit status is
+	// reported and the pipeline clones the exact commit. Otherwise
+	// the build is created for the branch ref.
+	var confb []byte
+	if brancher, ok := s.Remote.(remote.Brancher); ok {
+		commit, err := brancher.BranchHead(user, repo, branch)
+		if err != nil {
+			return fmt.Errorf("cannot resolve the head of branch %s: %s", branch, err)
+		}
+		build.Commit = commit.Sha
+		build.Author = commit.Author
+		build.Avatar = commit.Avatar
+		build.Email = commit.Email
+		if commit.Link != "" {
+			build.Link = commit.Link
+		}
+		confb, err = s.Remote.File(user, repo, build, repo.Config)
+	} else {
+		confb, err = s.Remote.FileRef(user, repo, build.Ref, repo.Config)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot find %s in %s: %s", repo.Config, build.Ref, err)
+	}
+	sha := shasum(confb)
+	conf, err := s.Store.ConfigFind(repo, sha)
+	if err != nil {
+		conf = &model.Config{
+			RepoID: repo.ID,
+			Data:   string(confb),
+			Hash:   sha,
+		}
+		if err = s.Store.ConfigCreate(conf); err != nil {
+			// retry in case a hook persisted the same config concurrently
+			conf, err = s.Store.ConfigFind(repo, sha)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	build.ConfigID = conf.ID
+
+	netrc, err := s.Remote.Netrc(user, repo)
+	if err != nil {
+		return err
+	}
+
+	if err = s.Store.CreateBuild(build); err != nil {
+		return err
+	}
+
+	envs := map[string]string{}
+	return queueBuild(s.Store, Config.Server.Host, repo, build, conf, netrc, envs, nil)
+}
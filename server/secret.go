@@ -62,6 +62,7 @@ func PostSecret(c *gin.Context) {
 		c.String(500, "Error inserting secret %q. %s", in.Name, err)
 		return
 	}
+	audit(c, "secret.create", repo, secret.Name, nil, secret)
 	c.JSON(200, secret.Copy())
 }
 
@@ -84,6 +85,7 @@ func PatchSecret(c *gin.Context) {
 		c.String(404, "Error getting secret %q. %s", name, err)
 		return
 	}
+	before := *secret
 	if in.Value != "" {
 		secret.Value = in.Value
 	}
@@ -102,6 +104,7 @@ func PatchSecret(c *gin.Context) {
 		c.String(500, "Error updating secret %q. %s", in.Name, err)
 		return
 	}
+	audit(c, "secret.update", repo, secret.Name, &before, secret)
 	c.JSON(200, secret.Copy())
 }
 
@@ -128,9 +131,15 @@ func DeleteSecret(c *gin.Context) {
 		repo = session.Repo(c)
 		name = c.Param("secret")
 	)
+	secret, err := Config.Services.Secrets.SecretFind(repo, name)
+	if err != nil {
+		c.String(404, "Error getting secret %q. %s", name, err)
+		return
+	}
 	if err := Config.Services.Secrets.SecretDelete(repo, name); err != nil {
 		c.String(500, "Error deleting secret %q. %s", name, err)
 		return
 	}
+	audit(c, "secret.delete", repo, name, secret, nil)
 	c.String(204, "")
 }
@@ -0,0 +1,65 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/drone/drone/model"
+	"github.com/drone/drone/router/middleware/session"
+	"github.com/drone/drone/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetRepoStats gets the build statistics of the repository and writes
+// to the response in json format. The statistics include the success
+// rate, build duration, queue wait time and step durations, bucketed by
+// day or week and optionally by branch.
+func GetRepoStats(c *gin.Context) {
+	repo := session.Repo(c)
+
+	opts := model.StatsOptions{
+		Interval:      c.DefaultQuery("interval", model.StatsDay),
+		GroupByBranch: c.Query("group") == "branch",
+	}
+	if opts.Interval != model.StatsDay && opts.Interval != model.StatsWeek {
+		c.String(http.StatusBadRequest, "Error parsing interval. Expect day or week")
+		return
+	}
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days < 1 || days > 365 {
+		c.String(http.StatusBadRequest, "Error parsing days. Expect a number between 1 and 365")
+		return
+	}
+	var (
+		branch = c.Query("branch")
+		since  = time.Now().AddDate(0, 0, -days).Unix()
+	)
+
+	builds, err := store.FromContext(c).StatsBuildList(repo, branch, since)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error getting build statistics. %s", err)
+		return
+	}
+	procs, err := store.FromContext(c).StatsProcList(repo, branch, since)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error getting step statistics. %s", err)
+		return
+	}
+	c.JSON(http.StatusOK, model.Stats(builds, procs, opts))
+}
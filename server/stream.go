@@ -16,13 +16,16 @@ package server
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 	"time"
 
 	"github.com/cncd/logging"
+	"github.com/cncd/pipeline/pipeline/rpc"
 	"github.com/cncd/pubsub"
 	"github.com/drone/drone/model"
 	"github.com/drone/drone/router/middleware/session"
@@ -130,26 +133,6 @@ func LogStreamSSE(c *gin.Context) {
 	io.WriteString(rw, ": ping\n\n")
 	flusher.Flush()
 
-	// repo := session.Repo(c)
-	//
-	// // parse the build number and job sequence number from
-	// // the repquest parameter.
-	// num, _ := strconv.Atoi(c.Params.ByName("number"))
-	// ppid, _ := strconv.Atoi(c.Params.ByName("ppid"))
-	// name := c.Params.ByName("proc")
-	//
-	// build, err := store.GetBuildNumber(c, repo, num)
-	// if err != nil {
-	// 	c.AbortWithError(404, err)
-	// 	return
-	// }
-	//
-	// proc, err := store.FromContext(c).ProcChild(build, ppid, name)
-	// if err != nil {
-	// 	c.AbortWithError(404, err)
-	// 	return
-	// }
-
 	repo := session.Repo(c)
 	buildn, _ := strconv.Atoi(c.Param("build"))
 	jobn, _ := strconv.Atoi(c.Param("number"))
@@ -166,25 +149,63 @@ func LogStreamSSE(c *gin.Context) {
 		io.WriteString(rw, "event: error\ndata: process not found\n\n")
 		return
 	}
-	if proc.State != model.StatusRunning {
-		logrus.Debugln("stream not found.")
-		io.WriteString(rw, "event: error\ndata: stream not found\n\n")
+
+	// the event id is the position of the last line sent for each step,
+	// so that a reconnecting client resumes exactly where it left off,
+	// whether the lines are streamed live or replayed from storage.
+	progress := parseLogProgress(c.Request.Header.Get("Last-Event-ID"))
+	if len(progress) != 0 {
+		logrus.Debugf("log stream: reconnect: last-event-id: %s", progress)
+	}
+
+	send := func(line *rpc.Line) {
+		if !progress.next(line) {
+			return
+		}
+		data, _ := json.Marshal(line)
+		io.WriteString(rw, "id: "+progress.String()+"\n")
+		io.WriteString(rw, "data: ")
+		rw.Write(data)
+		io.WriteString(rw, "\n\n")
+		flusher.Flush()
+	}
+
+	logrus.Debugf("log stream: connection opened")
+	defer logrus.Debugf("log stream: connection closed")
+
+	// the live stream replays the lines buffered so far, and ends when
+	// the log is closed after the process completes.
+	if proc.Running() {
+		if closed := streamLiveLogs(rw, flusher, proc, send); closed {
+			return
+		}
+	}
+
+	// the stored logs include lines the client missed because the
+	// process completed before or while streaming.
+	procs, err := store.FromContext(c).ProcList(build)
+	if err != nil {
+		logrus.Debugln("stream cannot get process list.", err)
+		io.WriteString(rw, "event: error\ndata: process not found\n\n")
 		return
 	}
+	for _, line := range storedLogLines(Config.Storage.Logs, proc, procs) {
+		send(line)
+	}
+	io.WriteString(rw, "event: error\ndata: eof\n\n")
+	flusher.Flush()
+}
 
+// streamLiveLogs sends the lines of the live log stream until the log is
+// closed. It returns true if the client closed the connection.
+func streamLiveLogs(rw gin.ResponseWriter, flusher http.Flusher, proc *model.Proc, send func(*rpc.Line)) bool {
 	logc := make(chan []byte, 10)
 	ctx, cancel := context.WithCancel(
 		context.Background(),
 	)
+	defer cancel()
 
-	logrus.Debugf("log stream: connection opened")
-
-	defer func() {
-		cancel()
-		close(logc)
-		logrus.Debugf("log stream: connection closed")
-	}()
-
+	done := make(chan struct{})
 	go func() {
 		// TODO remove global variable
 		Config.Services.Logs.Tail(ctx, fmt.Sprint(proc.ID), func(entries ...*logging.Entry) {
@@ -192,53 +213,106 @@ func LogStreamSSE(c *gin.Context) {
 				select {
 				case <-ctx.Done():
 					return
-				default:
-					logc <- entry.Data
+				case logc <- entry.Data:
 				}
 			}
 		})
-
-		io.WriteString(rw, "event: error\ndata: eof\n\n")
-
-		cancel()
+		close(done)
 	}()
 
-	id := 1
-	last, _ := strconv.Atoi(
-		c.Request.Header.Get("Last-Event-ID"),
-	)
-	if last != 0 {
-		logrus.Debugf("log stream: reconnect: last-event-id: %d", last)
+	sendData := func(data []byte) {
+		line := new(rpc.Line)
+		if err := json.Unmarshal(data, line); err == nil {
+			send(line)
+		}
 	}
 
-	// retry: 10000\n
-
 	for {
 		select {
 		// after 1 hour of idle (no response) end the stream.
 		// this is more of a safety mechanism than anything,
 		// and can be removed once the code is more mature.
 		case <-time.After(time.Hour):
-			return
+			return true
 		case <-rw.CloseNotify():
-			return
-		case <-ctx.Done():
-			return
+			return true
 		case <-time.After(time.Second * 30):
 			io.WriteString(rw, ": ping\n\n")
 			flusher.Flush()
-		case buf, ok := <-logc:
-			if ok {
-				if id > last {
-					io.WriteString(rw, "id: "+strconv.Itoa(id))
-					io.WriteString(rw, "\n")
-					io.WriteString(rw, "data: ")
-					rw.Write(buf)
-					io.WriteString(rw, "\n\n")
-					flusher.Flush()
+		case data := <-logc:
+			sendData(data)
+		case <-done:
+			for {
+				select {
+				case data := <-logc:
+					sendData(data)
+				default:
+					return false
 				}
-				id++
 			}
 		}
 	}
 }
+
+// storedLogLines returns the stored log lines of the steps of the
+// pipeline process, in step order.
+func storedLogLines(logs model.LogStore, pipeline *model.Proc, procs []*model.Proc) []*rpc.Line {
+	var lines []*rpc.Line
+	for _, proc := range procs {
+		if proc.PPID != pipeline.PID {
+			continue
+		}
+		rc, err := logs.LogFind(proc)
+		if err != nil {
+			continue
+		}
+		var steps []*rpc.Line
+		err = json.NewDecoder(rc).Decode(&steps)
+		rc.Close()
+		if err != nil {
+			logrus.Debugf("log stream: cannot decode stored log of proc %d. %s", proc.ID, err)
+			continue
+		}
+		lines = append(lines, steps...)
+	}
+	return lines
+}
+
+// logProgress records the position of the last line sent for each step
+// of the log stream.
+type logProgress map[string]int
+
+// parseLogProgress parses the progress from the event id. An invalid id
+// returns empty progress, replaying the log from the start.
+func parseLogProgress(id string) logProgress {
+	progress := logProgress{}
+	values, err := url.ParseQuery(id)
+	if err != nil {
+		return progress
+	}
+	for step := range values {
+		if pos, err := strconv.Atoi(values.Get(step)); err == nil {
+			progress[step] = pos
+		}
+	}
+	return progress
+}
+
+// next returns true if the line was not sent before, and records it as
+// the last line sent for its step.
+func (p logProgress) next(line *rpc.Line) bool {
+	if pos, ok := p[line.Proc]; ok && line.Pos <= pos {
+		return false
+	}
+	p[line.Proc] = line.Pos
+	return true
+}
+
+// String returns the progress encoded as an event id.
+func (p logProgress) String() string {
+	values := url.Values{}
+	for step, pos := range p {
+		values.Set(step, strconv.Itoa(pos))
+	}
+	return values.Encode()
+}
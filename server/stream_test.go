@@ -0,0 +1,115 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/cncd/pipeline/pipeline/rpc"
+	"github.com/drone/drone/model"
+)
+
+func TestLogProgress(t *testing.T) {
+	progress := parseLogProgress("")
+	lines := []*rpc.Line{
+		{Proc: "clone", Pos: 0},
+		{Proc: "build", Pos: 0},
+		{Proc: "clone", Pos: 1},
+		{Proc: "build", Pos: 1},
+	}
+	for _, line := range lines[:3] {
+		if !progress.next(line) {
+			t.Errorf("Want line %s:%d sent", line.Proc, line.Pos)
+		}
+	}
+	if progress.next(lines[1]) {
+		t.Errorf("Want line sent twice to be skipped")
+	}
+
+	// resume from the event id, as a reconnecting client would.
+	resumed := parseLogProgress(progress.String())
+	for _, line := range lines[:3] {
+		if resumed.next(line) {
+			t.Errorf("Want line %s:%d skipped after reconnect", line.Proc, line.Pos)
+		}
+	}
+	if !resumed.next(lines[3]) {
+		t.Errorf("Want new line sent after reconnect")
+	}
+	if got, want := resumed.String(), "build=1&clone=1"; got != want {
+		t.Errorf("Want event id %s, got %s", want, got)
+	}
+}
+
+func TestLogProgressInvalid(t *testing.T) {
+	if got := parseLogProgress("%zz"); len(got) != 0 {
+		t.Errorf("Want invalid event id to replay from the start")
+	}
+	if got := parseLogProgress("42"); len(got) != 0 {
+		t.Errorf("Want numeric event id to replay from the start")
+	}
+}
+
+func TestStoredLogLines(t *testing.T) {
+	logs := mockLogStore{
+		2: []*rpc.Line{{Proc: "clone", Pos: 0, Out: "git clone"}},
+		3: []*rpc.Line{{Proc: "build", Pos: 0, Out: "go build"}, {Proc: "build", Pos: 1, Out: "go test"}},
+		5: []*rpc.Line{{Proc: "other", Pos: 0}},
+	}
+	pipeline := &model.Proc{ID: 1, PID: 1}
+	procs := []*model.Proc{
+		pipeline,
+		{ID: 2, PID: 2, PPID: 1, Name: "clone"},
+		{ID: 3, PID: 3, PPID: 1, Name: "build"},
+		{ID: 4, PID: 4, PPID: 1, Name: "skipped"},
+		{ID: 5, PID: 5, PPID: 6, Name: "other"},
+	}
+
+	lines := storedLogLines(logs, pipeline, procs)
+	if got, want := len(lines), 3; got != want {
+		t.Errorf("Want %d stored lines, got %d", want, got)
+		return
+	}
+	if got, want := lines[2].Out, "go test"; got != want {
+		t.Errorf("Want stored lines in step order, got %s", got)
+	}
+}
+
+type mockLogStore map[int64][]*rpc.Line
+
+func (m mockLogStore) LogFind(proc *model.Proc) (io.ReadCloser, error) {
+	lines, ok := m[proc.ID]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	data, _ := json.Marshal(lines)
+	return ioutil.NopCloser(bytes.NewBuffer(data)), nil
+}
+
+func (m mockLogStore) LogSave(proc *model.Proc, r io.Reader) error {
+	var lines []*rpc.Line
+	if err := json.NewDecoder(r).Decode(&lines); err != nil {
+		return err
+	}
+	m[proc.ID] = lines
+	return nil
+}
+
+func (m mockLogStore) LogDelete(*model.Proc) error { return nil }
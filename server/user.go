@@ -130,6 +130,7 @@ func PostToken(c *gin.Context) {
 		c.AbortWithError(http.StatusInternalServerError, err)
 		return
 	}
+	audit(c, "user.token.create", nil, user.Login, nil, nil)
 	c.String(http.StatusOK, tokenstr)
 }
 
@@ -142,6 +143,7 @@ func DeleteToken(c *gin.Context) {
 		c.String(500, "Error revoking tokens. %s", err)
 		return
 	}
+	audit(c, "user.token.revoke", nil, user.Login, nil, nil)
 
 	token := token.New(token.UserToken, user.Login)
 	tokenstr, err := token.Sign(user.Hash)
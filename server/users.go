@@ -56,6 +56,7 @@ func PatchUser(c *gin.Context) {
 		c.AbortWithStatus(http.StatusNotFound)
 		return
 	}
+	before := *user
 	user.Active = in.Active
 
 	err = store.UpdateUser(c, user)
@@ -63,6 +64,7 @@ func PatchUser(c *gin.Context) {
 		c.AbortWithStatus(http.StatusConflict)
 		return
 	}
+	audit(c, "user.update", nil, user.Login, &before, user)
 
 	c.JSON(http.StatusOK, user)
 }
@@ -91,6 +93,7 @@ func PostUser(c *gin.Context) {
 		c.String(http.StatusInternalServerError, err.Error())
 		return
 	}
+	audit(c, "user.create", nil, user.Login, nil, user)
 	c.JSON(http.StatusOK, user)
 }
 
@@ -104,5 +107,6 @@ func DeleteUser(c *gin.Context) {
 		c.String(500, "Error deleting user. %s", err)
 		return
 	}
+	audit(c, "user.delete", nil, user.Login, user, nil)
 	c.String(200, "")
 }
@@ -0,0 +1,272 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/drone/drone/model"
+	"github.com/drone/drone/router/middleware/session"
+	"github.com/drone/drone/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetWebhook gets the webhook from the database and writes
+// to the response in json format.
+func GetWebhook(c *gin.Context) {
+	hook, ok := webhookFromContext(c)
+	if !ok {
+		return
+	}
+	c.JSON(200, hook.Copy())
+}
+
+// GetWebhookList gets the webhook list from the database and writes
+// to the response in json format. Global webhooks are listed when no
+// repository is in context.
+func GetWebhookList(c *gin.Context) {
+	var (
+		list []*model.Webhook
+		err  error
+	)
+	if repo := session.Repo(c); repo != nil {
+		list, err = store.FromContext(c).WebhookList(repo)
+	} else {
+		list, err = store.FromContext(c).WebhookListGlobal()
+	}
+	if err != nil {
+		c.String(500, "Error getting webhook list. %s", err)
+		return
+	}
+	// copy the webhook detail to remove the secret
+	// value from the response.
+	for i, hook := range list {
+		list[i] = hook.Copy()
+	}
+	c.JSON(200, list)
+}
+
+// PostWebhook persists the webhook to the database.
+func PostWebhook(c *gin.Context) {
+	in := new(model.Webhook)
+	if err := c.Bind(in); err != nil {
+		c.String(http.StatusBadRequest, "Error parsing webhook. %s", err)
+		return
+	}
+	hook := &model.Webhook{
+		URL:        in.URL,
+		Secret:     in.Secret,
+		Events:     in.Events,
+		SkipVerify: in.SkipVerify,
+		Disabled:   in.Disabled,
+		Created:    time.Now().Unix(),
+	}
+	if repo := session.Repo(c); repo != nil {
+		hook.RepoID = repo.ID
+	}
+	if err := hook.Validate(); err != nil {
+		c.String(400, "Error inserting webhook. %s", err)
+		return
+	}
+	if err := checkWebhookTarget(hook); err != nil {
+		c.String(400, "Error inserting webhook. %s", err)
+		return
+	}
+	hook.Updated = hook.Created
+
+	if err := store.FromContext(c).WebhookCreate(hook); err != nil {
+		c.String(500, "Error inserting webhook %q. %s", in.URL, err)
+		return
+	}
+	audit(c, "webhook.create", session.Repo(c), strconv.FormatInt(hook.ID, 10), nil, hook)
+	c.JSON(200, hook.Copy())
+}
+
+// PatchWebhook updates the webhook in the database.
+func PatchWebhook(c *gin.Context) {
+	in := new(model.WebhookPatch)
+	if err := c.Bind(in); err != nil {
+		c.String(http.StatusBadRequest, "Error parsing webhook. %s", err)
+		return
+	}
+
+	hook, ok := webhookFromContext(c)
+	if !ok {
+		return
+	}
+	before := *hook
+	if in.URL != nil {
+		hook.URL = *in.URL
+	}
+	if in.Secret != nil {
+		hook.Secret = *in.Secret
+	}
+	if in.Events != nil {
+		hook.Events = in.Events
+	}
+	if in.SkipVerify != nil {
+		hook.SkipVerify = *in.SkipVerify
+	}
+	if in.Disabled != nil {
+		hook.Disabled = *in.Disabled
+	}
+	if err := hook.Validate(); err != nil {
+		c.String(400, "Error updating webhook. %s", err)
+		return
+	}
+	if hook.URL != before.URL {
+		if err := checkWebhookTarget(hook); err != nil {
+			c.String(400, "Error updating webhook. %s", err)
+			return
+		}
+	}
+	hook.Updated = time.Now().Unix()
+
+	if err := store.FromContext(c).WebhookUpdate(hook); err != nil {
+		c.String(500, "Error updating webhook %d. %s", hook.ID, err)
+		return
+	}
+	audit(c, "webhook.update", session.Repo(c), strconv.FormatInt(hook.ID, 10), &before, hook)
+	c.JSON(200, hook.Copy())
+}
+
+// DeleteWebhook deletes the webhook and its delivery history from
+// the database.
+func DeleteWebhook(c *gin.Context) {
+	hook, ok := webhookFromContext(c)
+	if !ok {
+		return
+	}
+	if err := store.FromContext(c).WebhookDelete(hook); err != nil {
+		c.String(500, "Error deleting webhook %d. %s", hook.ID, err)
+		return
+	}
+	audit(c, "webhook.delete", session.Repo(c), strconv.FormatInt(hook.ID, 10), hook, nil)
+	c.String(204, "")
+}
+
+// GetWebhookDeliveries gets the recent deliveries of the webhook from
+// the database and writes to the response in json format.
+func GetWebhookDeliveries(c *gin.Context) {
+	hook, ok := webhookFromContext(c)
+	if !ok {
+		return
+	}
+	list, err := store.FromContext(c).WebhookDeliveryList(hook)
+	if err != nil {
+		c.String(500, "Error getting webhook deliveries. %s", err)
+		return
+	}
+	c.JSON(200, list)
+}
+
+// PostWebhookRedelivery sends the payload of a previous delivery to the
+// webhook again, recording the attempt as a new delivery.
+func PostWebhookRedelivery(c *gin.Context) {
+	hook, ok := webhookFromContext(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseInt(c.Param("delivery"), 10, 64)
+	if err != nil {
+		c.String(400, "Error parsing delivery id. %s", err)
+		return
+	}
+	prev, err := store.FromContext(c).WebhookDeliveryFind(id)
+	if err != nil || prev.WebhookID != hook.ID {
+		c.String(404, "Error getting delivery %d. %v", id, err)
+		return
+	}
+	delivery := &model.WebhookDelivery{
+		WebhookID:  hook.ID,
+		Event:      prev.Event,
+		Payload:    prev.Payload,
+		Redelivery: true,
+	}
+	if err := webhookDispatcherFromContext(c).Dispatch(hook, delivery); err != nil {
+		c.String(500, "Error redelivering delivery %d. %s", id, err)
+		return
+	}
+	c.JSON(200, delivery)
+}
+
+// checkWebhookTarget returns an error if a repository webhook targets a
+// loopback, link-local or private network address, which would let
+// repository admins probe the internal network of the server. Global
+// webhooks are managed by system admins and are not restricted.
+func checkWebhookTarget(hook *model.Webhook) error {
+	if hook.RepoID == 0 || Config.Server.WebhookPrivate {
+		return nil
+	}
+	uri, err := url.Parse(hook.URL)
+	if err != nil {
+		return err
+	}
+	host := uri.Hostname()
+	ips := []net.IP{net.ParseIP(host)}
+	if ips[0] == nil {
+		ips, err = net.LookupIP(host)
+		if err != nil {
+			return fmt.Errorf("Cannot resolve webhook host %s", host)
+		}
+	}
+	for _, ip := range ips {
+		if privateIP(ip) {
+			return fmt.Errorf("Webhook host %s resolves to private address %s", host, ip)
+		}
+	}
+	return nil
+}
+
+// privateIP reports whether the address is unspecified, loopback,
+// link-local or in a private network range.
+func privateIP(ip net.IP) bool {
+	return ip.IsUnspecified() ||
+		ip.IsLoopback() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsPrivate()
+}
+
+// webhookFromContext loads the webhook named in the request path and
+// verifies it belongs to the repository in context, or is global when
+// no repository is in context. An error response is written on failure.
+func webhookFromContext(c *gin.Context) (*model.Webhook, bool) {
+	id, err := strconv.ParseInt(c.Param("webhook"), 10, 64)
+	if err != nil {
+		c.String(400, "Error parsing webhook id. %s", err)
+		return nil, false
+	}
+	hook, err := store.FromContext(c).WebhookFind(id)
+	if err != nil {
+		c.String(404, "Error getting webhook %d. %s", id, err)
+		return nil, false
+	}
+	var repoID int64
+	if repo := session.Repo(c); repo != nil {
+		repoID = repo.ID
+	}
+	if hook.RepoID != repoID {
+		c.String(404, "Error getting webhook %d.", id)
+		return nil, false
+	}
+	return hook, true
+}
@@ -0,0 +1,260 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/drone/drone/model"
+
+	"github.com/cncd/pubsub"
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookDispatcher delivers build events to the outbound webhooks
+// registered globally and for the build repository.
+type WebhookDispatcher struct {
+	Store   model.WebhookStore
+	Pubsub  pubsub.Publisher
+	Retries int
+	Backoff time.Duration
+	Timeout time.Duration
+}
+
+const webhookDispatcherKey = "webhooks"
+
+// WebhookDispatcherToContext adds the webhook dispatcher to the context.
+func WebhookDispatcherToContext(c *gin.Context, d *WebhookDispatcher) {
+	c.Set(webhookDispatcherKey, d)
+}
+
+// webhookDispatcherFromContext returns the webhook dispatcher associated
+// with the context.
+func webhookDispatcherFromContext(c *gin.Context) *WebhookDispatcher {
+	return c.MustGet(webhookDispatcherKey).(*WebhookDispatcher)
+}
+
+// Start subscribes to build events and dispatches them until the
+// context is cancelled.
+func (d *WebhookDispatcher) Start(ctx context.Context) error {
+	return d.Pubsub.Subscribe(ctx, "topic/events", func(m pubsub.Message) {
+		event := new(model.Event)
+		if err := json.Unmarshal(m.Data, event); err != nil {
+			logrus.Errorf("webhook: cannot unmarshal event. %s", err)
+			return
+		}
+		d.handle(event, m.Data)
+	})
+}
+
+func (d *WebhookDispatcher) handle(event *model.Event, payload []byte) {
+	switch event.Type {
+	case model.Enqueued, model.Started, model.Finished:
+	default:
+		return
+	}
+
+	hooks, err := d.Store.WebhookListGlobal()
+	if err != nil {
+		logrus.Errorf("webhook: cannot list global webhooks. %s", err)
+		return
+	}
+	repoHooks, err := d.Store.WebhookList(&event.Repo)
+	if err != nil {
+		logrus.Errorf("webhook: cannot list webhooks for %s. %s", event.Repo.FullName, err)
+		return
+	}
+	for _, hook := range append(hooks, repoHooks...) {
+		if hook.Disabled || !hook.Match(event.Type) {
+			continue
+		}
+		delivery := &model.WebhookDelivery{
+			WebhookID: hook.ID,
+			Event:     event.Type,
+			Payload:   payload,
+		}
+		if err := d.Dispatch(hook, delivery); err != nil {
+			logrus.Errorf("webhook: cannot dispatch %s event to webhook %d. %s", event.Type, hook.ID, err)
+		}
+	}
+}
+
+// Dispatch records the delivery and sends it to the webhook in the
+// background, retrying failed attempts with exponential backoff.
+func (d *WebhookDispatcher) Dispatch(hook *model.Webhook, delivery *model.WebhookDelivery) error {
+	delivery.Created = time.Now().Unix()
+	delivery.Updated = delivery.Created
+	if err := d.Store.WebhookDeliveryCreate(delivery); err != nil {
+		return err
+	}
+	go d.deliver(hook, delivery)
+	return nil
+}
+
+func (d *WebhookDispatcher) deliver(hook *model.Webhook, delivery *model.WebhookDelivery) {
+	retries := d.Retries
+	if retries == 0 {
+		retries = 5
+	}
+	backoff := d.Backoff
+	if backoff == 0 {
+		backoff = time.Second * 5
+	}
+	for {
+		status, err := d.send(hook, delivery)
+		delivery.Attempts++
+		delivery.Status = status
+		delivery.Error = ""
+		if err != nil {
+			delivery.Error = err.Error()
+		}
+		delivery.Updated = time.Now().Unix()
+		if uerr := d.Store.WebhookDeliveryUpdate(delivery); uerr != nil {
+			logrus.Errorf("webhook: cannot update delivery %d. %s", delivery.ID, uerr)
+		}
+		if err == nil || delivery.Attempts >= retries {
+			return
+		}
+		time.Sleep(backoff)
+		backoff = backoff * 2
+	}
+}
+
+// send posts the delivery payload to the webhook and returns the
+// response status code.
+func (d *WebhookDispatcher) send(hook *model.Webhook, delivery *model.WebhookDelivery) (int, error) {
+	req, err := http.NewRequest("POST", hook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Drone-Event", string(delivery.Event))
+	req.Header.Set("X-Drone-Delivery", strconv.FormatInt(delivery.ID, 10))
+	if hook.Secret != "" {
+		req.Header.Set("X-Drone-Signature", "sha256="+signWebhook(hook.Secret, delivery.Payload))
+	}
+
+	timeout := d.Timeout
+	if timeout == 0 {
+		timeout = time.Second * 30
+	}
+	res, err := webhookClient(hook, timeout).Do(req)
+	if err != nil {
+		return 0, err
+	}
+	res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("Unexpected response status %s", res.Status)
+	}
+	return res.StatusCode, nil
+}
+
+// webhookClient returns the http client used to deliver to the webhook.
+// Repository webhooks do not follow redirects, which could otherwise
+// point the request at an address that was never validated.
+func webhookClient(hook *model.Webhook, timeout time.Duration) *http.Client {
+	client := &http.Client{
+		Timeout:   timeout,
+		Transport: webhookTransport(hook),
+	}
+	if restrictWebhook(hook) {
+		client.CheckRedirect = func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+	return client
+}
+
+// restrictWebhook reports whether the webhook may only connect to public
+// network addresses.
+func restrictWebhook(hook *model.Webhook) bool {
+	return hook.RepoID != 0 && !Config.Server.WebhookPrivate
+}
+
+// webhookTransport returns the transport used to deliver to the webhook.
+// Repository webhooks connect directly, without a proxy, and the target
+// address is checked when connecting, so that a host which resolved to a
+// public address when the webhook was saved cannot later be pointed at
+// the internal network of the server.
+func webhookTransport(hook *model.Webhook) http.RoundTripper {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	transport := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+	if hook.SkipVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	if restrictWebhook(hook) {
+		transport.Proxy = nil
+		transport.DialContext = dialPublic(dialer)
+	}
+	return transport
+}
+
+// dialPublic returns a dial function that resolves the host and refuses
+// to connect if any of its addresses is a private network address.
+func dialPublic(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			if privateIP(ip.IP) {
+				return nil, fmt.Errorf("Webhook host %s resolves to private address %s", host, ip.IP)
+			}
+		}
+		for _, ip := range ips {
+			var conn net.Conn
+			conn, err = dialer.DialContext(ctx, network, net.JoinHostPort(ip.IP.String(), port))
+			if err == nil {
+				return conn, nil
+			}
+		}
+		if err == nil {
+			err = fmt.Errorf("Cannot resolve webhook host %s", host)
+		}
+		return nil, err
+	}
+}
+
+// signWebhook returns the hex encoded HMAC-SHA256 signature of the
+// payload, keyed with the webhook secret.
+func signWebhook(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
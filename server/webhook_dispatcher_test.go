@@ -0,0 +1,124 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/drone/drone/model"
+)
+
+func TestWebhookDeliver(t *testing.T) {
+	var attempts int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		body, _ := ioutil.ReadAll(r.Body)
+		if got, want := r.Header.Get("X-Drone-Signature"), "sha256="+signWebhook("correct-horse", body); got != want {
+			t.Errorf("Want signature %s, got %s", want, got)
+		}
+		if got, want := r.Header.Get("X-Drone-Event"), "finished"; got != want {
+			t.Errorf("Want event header %s, got %s", want, got)
+		}
+		if attempts == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	s := new(mockWebhookStore)
+	d := &WebhookDispatcher{Store: s, Retries: 3, Backoff: time.Millisecond}
+	hook := &model.Webhook{ID: 1, URL: srv.URL, Secret: "correct-horse"}
+	delivery := &model.WebhookDelivery{ID: 1, Event: model.Finished, Payload: []byte(`{"type":"finished"}`)}
+	d.deliver(hook, delivery)
+
+	if got, want := delivery.Attempts, 2; got != want {
+		t.Errorf("Want %d attempts, got %d", want, got)
+	}
+	if got, want := delivery.Status, http.StatusNoContent; got != want {
+		t.Errorf("Want status %d, got %d", want, got)
+	}
+	if got, want := delivery.Error, ""; got != want {
+		t.Errorf("Want error cleared, got %q", got)
+	}
+	if got, want := s.updates, 2; got != want {
+		t.Errorf("Want %d delivery updates, got %d", want, got)
+	}
+}
+
+func TestWebhookDeliverRetries(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	s := new(mockWebhookStore)
+	d := &WebhookDispatcher{Store: s, Retries: 3, Backoff: time.Millisecond}
+	delivery := &model.WebhookDelivery{ID: 1, Event: model.Started}
+	d.deliver(&model.Webhook{ID: 1, URL: srv.URL}, delivery)
+
+	if got, want := delivery.Attempts, 3; got != want {
+		t.Errorf("Want %d attempts, got %d", want, got)
+	}
+	if delivery.Error == "" {
+		t.Errorf("Want delivery error recorded")
+	}
+}
+
+func TestWebhookSendPrivate(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer srv.Close()
+
+	d := new(WebhookDispatcher)
+	delivery := &model.WebhookDelivery{ID: 1, Event: model.Started}
+	if _, err := d.send(&model.Webhook{ID: 1, RepoID: 1, URL: srv.URL}, delivery); err == nil {
+		t.Errorf("Want repository webhook to loopback address refused")
+	}
+	if got, want := requests, 0; got != want {
+		t.Errorf("Want %d requests, got %d", want, got)
+	}
+	if _, err := d.send(&model.Webhook{ID: 1, URL: srv.URL}, delivery); err != nil {
+		t.Errorf("Want global webhook delivered, got %s", err)
+	}
+}
+
+func TestWebhookClientRedirect(t *testing.T) {
+	client := webhookClient(&model.Webhook{RepoID: 1}, time.Second)
+	if client.CheckRedirect == nil || client.CheckRedirect(nil, nil) != http.ErrUseLastResponse {
+		t.Errorf("Want redirects refused for repository webhooks")
+	}
+	client = webhookClient(&model.Webhook{}, time.Second)
+	if client.CheckRedirect != nil {
+		t.Errorf("Want redirects followed for global webhooks")
+	}
+}
+
+type mockWebhookStore struct {
+	model.WebhookStore
+	updates int
+}
+
+func (m *mockWebhookStore) WebhookDeliveryUpdate(*model.WebhookDelivery) error {
+	m.updates++
+	return nil
+}
@@ -0,0 +1,53 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"testing"
+
+	"github.com/drone/drone/model"
+)
+
+func TestCheckWebhookTarget(t *testing.T) {
+	tests := []struct {
+		url    string
+		repo   int64
+		reject bool
+	}{
+		{url: "https://8.8.8.8/hook", repo: 1},
+		{url: "http://127.0.0.1:8000/hook", repo: 1, reject: true},
+		{url: "http://10.0.0.1/hook", repo: 1, reject: true},
+		{url: "http://192.168.1.1/hook", repo: 1, reject: true},
+		{url: "http://169.254.169.254/latest/meta-data", repo: 1, reject: true},
+		{url: "http://[::1]/hook", repo: 1, reject: true},
+		{url: "http://0.0.0.0/hook", repo: 1, reject: true},
+		{url: "http://localhost/hook", repo: 1, reject: true},
+		// global webhooks are managed by system admins.
+		{url: "http://127.0.0.1:8000/hook", repo: 0},
+	}
+	for _, test := range tests {
+		hook := &model.Webhook{URL: test.url, RepoID: test.repo}
+		err := checkWebhookTarget(hook)
+		if got, want := err != nil, test.reject; got != want {
+			t.Errorf("Want %s rejected %v, got error %v", test.url, want, err)
+		}
+	}
+
+	Config.Server.WebhookPrivate = true
+	defer func() { Config.Server.WebhookPrivate = false }()
+	if err := checkWebhookTarget(&model.Webhook{URL: "http://10.0.0.1/hook", RepoID: 1}); err != nil {
+		t.Errorf("Want private target allowed, got error %s", err)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"encoding/json"
+
+	"github.com/drone/drone/model"
+	"github.com/drone/drone/store/datastore/sql"
+	"github.com/russross/meddler"
+)
+
+func (db *datastore) AgentFind(hostname string) (*model.Agent, error) {
+	stmt := sql.Lookup(db.driver, "agent-find-addr")
+	data := new(model.Agent)
+	err := meddler.QueryRow(db, data, stmt, hostname)
+	return data, err
+}
+
+func (db *datastore) AgentList() ([]*model.Agent, error) {
+	stmt := sql.Lookup(db.driver, "agent-list")
+	data := []*model.Agent{}
+	err := meddler.QueryAll(db, &data, stmt)
+	return data, err
+}
+
+func (db *datastore) AgentCreate(agent *model.Agent) error {
+	return meddler.Insert(db, "agents", agent)
+}
+
+func (db *datastore) AgentUpdate(agent *model.Agent) error {
+	return meddler.Update(db, "agents", agent)
+}
+
+func (db *datastore) AgentSeen(agent *model.Agent) error {
+	stmt := sql.Lookup(db.driver, "agent-update-seen")
+	_, err := db.Exec(stmt, agent.LastSeen, agent.ID)
+	return err
+}
+
+func (db *datastore) AgentCheckin(agent *model.Agent) error {
+	labels, err := json.Marshal(agent.Labels)
+	if err != nil {
+		return err
+	}
+	stmt := sql.Lookup(db.driver, "agent-update-checkin")
+	_, err = db.Exec(stmt,
+		agent.Platform,
+		agent.Capacity,
+		string(labels),
+		agent.Filter,
+		agent.Version,
+		agent.LastSeen,
+		agent.ID,
+	)
+	return err
+}
+
+func (db *datastore) AgentDelete(agent *model.Agent) error {
+	stmt := sql.Lookup(db.driver, "agent-delete")
+	_, err := db.Exec(stmt, agent.ID)
+	return err
+}
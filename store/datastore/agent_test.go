@@ -0,0 +1,190 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"testing"
+
+	"github.com/drone/drone/model"
+)
+
+func TestAgentFind(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from agents")
+		s.Close()
+	}()
+
+	err := s.AgentCreate(&model.Agent{
+		Hostname: "agent01",
+		Platform: "linux/amd64",
+		Capacity: 2,
+		Labels:   map[string]string{"platform": "linux/amd64"},
+		Version:  "0.8.5",
+		LastSeen: 1,
+	})
+	if err != nil {
+		t.Errorf("Unexpected error: insert agent: %s", err)
+		return
+	}
+
+	agent, err := s.AgentFind("agent01")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := agent.Platform, "linux/amd64"; got != want {
+		t.Errorf("Want agent platform %s, got %s", want, got)
+	}
+	if got, want := agent.Capacity, 2; got != want {
+		t.Errorf("Want agent capacity %d, got %d", want, got)
+	}
+	if got, want := agent.Labels["platform"], "linux/amd64"; got != want {
+		t.Errorf("Want agent label %s, got %s", want, got)
+	}
+	if got, want := agent.Version, "0.8.5"; got != want {
+		t.Errorf("Want agent version %s, got %s", want, got)
+	}
+
+	agent.LastSeen = 2
+	if err := s.AgentSeen(agent); err != nil {
+		t.Errorf("Unexpected error: update agent: %s", err)
+		return
+	}
+	agent, _ = s.AgentFind("agent01")
+	if got, want := agent.LastSeen, int64(2); got != want {
+		t.Errorf("Want agent last seen %d, got %d", want, got)
+	}
+}
+
+func TestAgentCheckin(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from agents")
+		s.Close()
+	}()
+
+	agent := &model.Agent{
+		Hostname: "agent01",
+		Platform: "linux/amd64",
+		Capacity: 2,
+		Version:  "0.8.5",
+		LastSeen: 1,
+	}
+	if err := s.AgentCreate(agent); err != nil {
+		t.Errorf("Unexpected error: insert agent: %s", err)
+		return
+	}
+
+	// drain the agent while a stale copy checks in.
+	stale := *agent
+	agent.Draining = true
+	if err := s.AgentUpdate(agent); err != nil {
+		t.Errorf("Unexpected error: update agent: %s", err)
+		return
+	}
+
+	stale.Capacity = 4
+	stale.Labels = map[string]string{"platform": "linux/arm"}
+	stale.Version = "0.8.6"
+	stale.LastSeen = 2
+	if err := s.AgentCheckin(&stale); err != nil {
+		t.Errorf("Unexpected error: checkin agent: %s", err)
+		return
+	}
+
+	agent, _ = s.AgentFind("agent01")
+	if got, want := agent.Draining, true; got != want {
+		t.Errorf("Want agent draining %v, got %v", want, got)
+	}
+	if got, want := agent.Capacity, 4; got != want {
+		t.Errorf("Want agent capacity %d, got %d", want, got)
+	}
+	if got, want := agent.Labels["platform"], "linux/arm"; got != want {
+		t.Errorf("Want agent label %s, got %s", want, got)
+	}
+	if got, want := agent.Version, "0.8.6"; got != want {
+		t.Errorf("Want agent version %s, got %s", want, got)
+	}
+	if got, want := agent.LastSeen, int64(2); got != want {
+		t.Errorf("Want agent last seen %d, got %d", want, got)
+	}
+}
+
+func TestAgentList(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from agents")
+		s.Close()
+	}()
+
+	s.AgentCreate(&model.Agent{Hostname: "agent02"})
+	s.AgentCreate(&model.Agent{Hostname: "agent01"})
+
+	list, err := s.AgentList()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(list), 2; got != want {
+		t.Errorf("Want %d agents, got %d", want, got)
+		return
+	}
+	if got, want := list[0].Hostname, "agent01"; got != want {
+		t.Errorf("Want agents sorted by hostname, got %s", got)
+	}
+}
+
+func TestAgentDelete(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from agents")
+		s.Close()
+	}()
+
+	agent := &model.Agent{Hostname: "agent01"}
+	if err := s.AgentCreate(agent); err != nil {
+		t.Errorf("Unexpected error: insert agent: %s", err)
+		return
+	}
+	agent.Draining = true
+	if err := s.AgentUpdate(agent); err != nil {
+		t.Errorf("Unexpected error: update agent: %s", err)
+		return
+	}
+	if err := s.AgentDelete(agent); err != nil {
+		t.Errorf("Unexpected error: delete agent: %s", err)
+		return
+	}
+	if _, err := s.AgentFind("agent01"); err == nil {
+		t.Errorf("Expected error: sql.ErrNoRows")
+	}
+}
+
+func TestAgentIndexes(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from agents")
+		s.Close()
+	}()
+
+	if err := s.AgentCreate(&model.Agent{Hostname: "agent01"}); err != nil {
+		t.Errorf("Unexpected error: insert agent: %s", err)
+		return
+	}
+	if err := s.AgentCreate(&model.Agent{Hostname: "agent01"}); err == nil {
+		t.Errorf("Unexpected error: duplicate hostname")
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"github.com/drone/drone/model"
+	"github.com/drone/drone/store/datastore/sql"
+	"github.com/russross/meddler"
+)
+
+func (db *datastore) AgentTokenFind(id int64) (*model.AgentToken, error) {
+	data := new(model.AgentToken)
+	err := meddler.Load(db, "agent_tokens", data, id)
+	return data, err
+}
+
+func (db *datastore) AgentTokenFindHash(hash string) (*model.AgentToken, error) {
+	stmt := sql.Lookup(db.driver, "agent-token-find-hash")
+	data := new(model.AgentToken)
+	err := meddler.QueryRow(db, data, stmt, hash)
+	return data, err
+}
+
+func (db *datastore) AgentTokenList() ([]*model.AgentToken, error) {
+	stmt := sql.Lookup(db.driver, "agent-token-list")
+	data := []*model.AgentToken{}
+	err := meddler.QueryAll(db, &data, stmt)
+	return data, err
+}
+
+func (db *datastore) AgentTokenCreate(token *model.AgentToken) error {
+	return meddler.Insert(db, "agent_tokens", token)
+}
+
+func (db *datastore) AgentTokenDelete(token *model.AgentToken) error {
+	stmt := sql.Lookup(db.driver, "agent-token-delete")
+	_, err := db.Exec(stmt, token.ID)
+	return err
+}
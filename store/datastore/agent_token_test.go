@@ -0,0 +1,124 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"testing"
+
+	"github.com/drone/drone/model"
+)
+
+func TestAgentTokenFind(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from agent_tokens")
+		s.Close()
+	}()
+
+	token := &model.AgentToken{
+		Name:      "arm-builders",
+		Hash:      model.HashAgentToken("correct-horse-battery-staple"),
+		Platforms: []string{"linux/arm*"},
+		Labels:    map[string]string{"gpu": "true"},
+	}
+	if err := s.AgentTokenCreate(token); err != nil {
+		t.Errorf("Unexpected error: insert token: %s", err)
+		return
+	}
+
+	found, err := s.AgentTokenFindHash(model.HashAgentToken("correct-horse-battery-staple"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := found.Name, "arm-builders"; got != want {
+		t.Errorf("Want token name %s, got %s", want, got)
+	}
+	if got, want := found.Platforms[0], "linux/arm*"; got != want {
+		t.Errorf("Want token platform %s, got %s", want, got)
+	}
+	if got, want := found.Labels["gpu"], "true"; got != want {
+		t.Errorf("Want token label %s, got %s", want, got)
+	}
+
+	found, err = s.AgentTokenFind(token.ID)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := found.Name, "arm-builders"; got != want {
+		t.Errorf("Want token name %s, got %s", want, got)
+	}
+}
+
+func TestAgentTokenList(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from agent_tokens")
+		s.Close()
+	}()
+
+	s.AgentTokenCreate(&model.AgentToken{Name: "foo", Hash: "1"})
+	s.AgentTokenCreate(&model.AgentToken{Name: "bar", Hash: "2"})
+
+	list, err := s.AgentTokenList()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(list), 2; got != want {
+		t.Errorf("Want %d tokens, got %d", want, got)
+	}
+}
+
+func TestAgentTokenDelete(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from agent_tokens")
+		s.Close()
+	}()
+
+	token := &model.AgentToken{Name: "foo", Hash: "1"}
+	if err := s.AgentTokenCreate(token); err != nil {
+		t.Errorf("Unexpected error: insert token: %s", err)
+		return
+	}
+	if err := s.AgentTokenDelete(token); err != nil {
+		t.Errorf("Unexpected error: delete token: %s", err)
+		return
+	}
+	if _, err := s.AgentTokenFindHash("1"); err == nil {
+		t.Errorf("Expected error: sql.ErrNoRows")
+	}
+}
+
+func TestAgentTokenIndexes(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from agent_tokens")
+		s.Close()
+	}()
+
+	if err := s.AgentTokenCreate(&model.AgentToken{Name: "foo", Hash: "1"}); err != nil {
+		t.Errorf("Unexpected error: insert token: %s", err)
+		return
+	}
+	if err := s.AgentTokenCreate(&model.AgentToken{Name: "foo", Hash: "2"}); err == nil {
+		t.Errorf("Unexpected error: duplicate name")
+	}
+	if err := s.AgentTokenCreate(&model.AgentToken{Name: "bar", Hash: "1"}); err == nil {
+		t.Errorf("Unexpected error: duplicate hash")
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"testing"
+
+	"github.com/drone/drone/model"
+)
+
+func TestAuditList(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from audit_events")
+		s.Close()
+	}()
+
+	s.AuditCreate(&model.AuditEvent{Actor: "octocat", Action: "secret.delete", Repo: "octocat/hello-world", Target: "password"})
+	s.AuditCreate(&model.AuditEvent{Actor: "octocat", Action: "repo.update", Repo: "octocat/hello-world", Target: "octocat/hello-world",
+		Diff: map[string]*model.AuditChange{"trusted": {Before: false, After: true}},
+	})
+	s.AuditCreate(&model.AuditEvent{Actor: "spaceghost", Action: "user.create", Target: "octocat"})
+
+	list, err := s.AuditList(&model.AuditFilter{Limit: 50})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(list), 3; got != want {
+		t.Errorf("Want %d audit events, got %d", want, got)
+		return
+	}
+	if got, want := list[0].Action, "user.create"; got != want {
+		t.Errorf("Want most recent audit event %s, got %s", want, got)
+	}
+	if got, want := list[1].Diff["trusted"].After, true; got != want {
+		t.Errorf("Want audit diff after value %v, got %v", want, got)
+	}
+
+	list, _ = s.AuditList(&model.AuditFilter{Actor: "octocat", Limit: 50})
+	if got, want := len(list), 2; got != want {
+		t.Errorf("Want %d audit events filtered by actor, got %d", want, got)
+	}
+	list, _ = s.AuditList(&model.AuditFilter{Repo: "octocat/hello-world", Action: "secret.delete", Limit: 50})
+	if got, want := len(list), 1; got != want {
+		t.Errorf("Want %d audit events filtered by repo and action, got %d", want, got)
+	}
+	list, _ = s.AuditList(&model.AuditFilter{Limit: 2, Offset: 2})
+	if got, want := len(list), 1; got != want {
+		t.Errorf("Want %d audit events on the second page, got %d", want, got)
+	}
+}
@@ -65,6 +65,48 @@ func (db *datastore) GetBuildList(repo *model.Repo, page int) ([]*model.Build, e
 	return builds, err
 }
 
+func (db *datastore) GetBuildListFilter(repo *model.Repo, filter *model.BuildFilter) ([]*model.Build, error) {
+	stmt := sql.Lookup(db.driver, "build-find-filter")
+	builds := []*model.Build{}
+	err := meddler.QueryAll(db, &builds, stmt, repo.ID,
+		filter.Branch, likeGlob(filter.Branch),
+		filter.Event, filter.Event,
+		filter.Status, filter.Status,
+		filter.Author, filter.Author,
+		filter.Commit, likeEscape(filter.Commit)+"%",
+		filter.After, filter.After,
+		filter.Before, filter.Before,
+		filter.Cursor, filter.Cursor,
+		filter.Limit,
+	)
+	return builds, err
+}
+
+func (db *datastore) GetBuildSearch(filter *model.BuildFilter) ([]*model.Feed, error) {
+	stmt := sql.Lookup(db.driver, "build-search")
+	feed := []*model.Feed{}
+	err := meddler.QueryAll(db, &feed, stmt,
+		filter.Repo, likeGlob(filter.Repo),
+		filter.Branch, likeGlob(filter.Branch),
+		filter.Event, filter.Event,
+		filter.Status, filter.Status,
+		filter.Author, filter.Author,
+		filter.Commit, likeEscape(filter.Commit)+"%",
+		filter.After, filter.After,
+		filter.Before, filter.Before,
+		filter.Cursor, filter.Cursor,
+		filter.Limit,
+	)
+	return feed, err
+}
+
+func (db *datastore) GetBuildSuperseded(build *model.Build) ([]*model.Build, error) {
+	stmt := sql.Lookup(db.driver, "build-find-superseded")
+	builds := []*model.Build{}
+	err := meddler.QueryAll(db, &builds, stmt, build.RepoID, build.Event, build.Ref, build.Number)
+	return builds, err
+}
+
 func (db *datastore) GetBuildQueue() ([]*model.Feed, error) {
 	feed := []*model.Feed{}
 	err := meddler.QueryAll(db, &feed, buildQueueList)
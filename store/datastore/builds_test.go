@@ -274,6 +274,79 @@ func TestBuilds(t *testing.T) {
 			g.Assert(builds[0].RepoID).Equal(build2.RepoID)
 			g.Assert(builds[0].Status).Equal(build2.Status)
 		})
+
+		g.It("Should get filtered Builds", func() {
+			s.CreateBuild(&model.Build{RepoID: repo.ID, Status: model.StatusFailure, Event: model.EventPush, Branch: "release/1.0", Author: "alice", Commit: "85f8c029b902"})
+			s.CreateBuild(&model.Build{RepoID: repo.ID, Status: model.StatusSuccess, Event: model.EventPush, Branch: "release/1.1", Author: "alice", Commit: "0a1b2c3d4e5f"})
+			s.CreateBuild(&model.Build{RepoID: repo.ID, Status: model.StatusFailure, Event: model.EventPull, Branch: "master", Author: "bob", Commit: "85f8c0aaaaaa"})
+			s.CreateBuild(&model.Build{RepoID: repo.ID, Status: model.StatusFailure, Event: model.EventPush, Branch: "release_2", Author: "alice", Commit: "ffffffffffff"})
+
+			builds, err := s.GetBuildListFilter(repo, &model.BuildFilter{Branch: "release/*", Status: model.StatusFailure, Author: "alice", Limit: 50})
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(builds)).Equal(1)
+			g.Assert(builds[0].Branch).Equal("release/1.0")
+
+			builds, _ = s.GetBuildListFilter(repo, &model.BuildFilter{Commit: "85f8c0", Limit: 50})
+			g.Assert(len(builds)).Equal(2)
+			builds, _ = s.GetBuildListFilter(repo, &model.BuildFilter{Event: model.EventPush, Limit: 50})
+			g.Assert(len(builds)).Equal(3)
+			builds, _ = s.GetBuildListFilter(repo, &model.BuildFilter{After: builds[0].Created + 1, Limit: 50})
+			g.Assert(len(builds)).Equal(0)
+		})
+
+		g.It("Should search Builds of all repositories", func() {
+			other := &model.Repo{UserID: 1, FullName: "octocat/hello-world", Owner: "octocat", Name: "hello-world"}
+			s.CreateRepo(other)
+			defer s.DeleteRepo(other)
+
+			s.CreateBuild(&model.Build{RepoID: repo.ID, Status: model.StatusFailure})
+			s.CreateBuild(&model.Build{RepoID: other.ID, Status: model.StatusFailure})
+			s.CreateBuild(&model.Build{RepoID: other.ID, Status: model.StatusSuccess})
+
+			feed, err := s.GetBuildSearch(&model.BuildFilter{Status: model.StatusFailure, Limit: 50})
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(feed)).Equal(2)
+			g.Assert(feed[0].FullName).Equal("octocat/hello-world")
+			g.Assert(feed[0].ID > feed[1].ID).IsTrue()
+
+			feed, _ = s.GetBuildSearch(&model.BuildFilter{Repo: "octocat/*", Limit: 50})
+			g.Assert(len(feed)).Equal(2)
+			feed, _ = s.GetBuildSearch(&model.BuildFilter{Repo: "octocat/*", Cursor: feed[0].ID, Limit: 50})
+			g.Assert(len(feed)).Equal(1)
+		})
+
+		g.It("Should paginate filtered Builds by cursor", func() {
+			for i := 0; i < 5; i++ {
+				s.CreateBuild(&model.Build{RepoID: repo.ID, Status: model.StatusSuccess})
+			}
+			builds, err := s.GetBuildListFilter(repo, &model.BuildFilter{Limit: 2})
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(builds)).Equal(2)
+
+			cursor := builds[1].Number
+			builds, _ = s.GetBuildListFilter(repo, &model.BuildFilter{Cursor: int64(cursor), Limit: 2})
+			g.Assert(len(builds)).Equal(2)
+			g.Assert(builds[0].Number).Equal(cursor - 1)
+		})
+
+		g.It("Should Get superseded Builds", func() {
+			ref := "refs/pull/42/head"
+			running := &model.Build{RepoID: repo.ID, Event: model.EventPull, Ref: ref, Status: model.StatusRunning}
+			pending := &model.Build{RepoID: repo.ID, Event: model.EventPull, Ref: ref, Status: model.StatusPending}
+			s.CreateBuild(running)
+			s.CreateBuild(pending)
+			s.CreateBuild(&model.Build{RepoID: repo.ID, Event: model.EventPull, Ref: ref, Status: model.StatusSuccess})
+			s.CreateBuild(&model.Build{RepoID: repo.ID, Event: model.EventPush, Ref: ref, Status: model.StatusRunning})
+			s.CreateBuild(&model.Build{RepoID: repo.ID, Event: model.EventPull, Ref: "refs/pull/43/head", Status: model.StatusRunning})
+			build := &model.Build{RepoID: repo.ID, Event: model.EventPull, Ref: ref, Status: model.StatusPending}
+			s.CreateBuild(build)
+
+			builds, err := s.GetBuildSuperseded(build)
+			g.Assert(err == nil).IsTrue()
+			g.Assert(len(builds)).Equal(2)
+			g.Assert(builds[0].ID).Equal(running.ID)
+			g.Assert(builds[1].ID).Equal(pending.ID)
+		})
 	})
 }
 
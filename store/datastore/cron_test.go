@@ -0,0 +1,271 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"testing"
+
+	"github.com/drone/drone/model"
+)
+
+func TestCronFind(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from crons")
+		s.Close()
+	}()
+
+	err := s.CronCreate(&model.Cron{
+		RepoID: 1,
+		Name:   "nightly",
+		Expr:   "@daily",
+		Branch: "master",
+		Next:   1514764800,
+	})
+	if err != nil {
+		t.Errorf("Unexpected error: insert cron: %s", err)
+		return
+	}
+
+	cron, err := s.CronFind(&model.Repo{ID: 1}, "nightly")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := cron.RepoID, int64(1); got != want {
+		t.Errorf("Want repo id %d, got %d", want, got)
+	}
+	if got, want := cron.Name, "nightly"; got != want {
+		t.Errorf("Want cron name %s, got %s", want, got)
+	}
+	if got, want := cron.Expr, "@daily"; got != want {
+		t.Errorf("Want cron expr %s, got %s", want, got)
+	}
+	if got, want := cron.Branch, "master"; got != want {
+		t.Errorf("Want cron branch %s, got %s", want, got)
+	}
+	if got, want := cron.Next, int64(1514764800); got != want {
+		t.Errorf("Want cron next %d, got %d", want, got)
+	}
+}
+
+func TestCronList(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from crons")
+		s.Close()
+	}()
+
+	s.CronCreate(&model.Cron{
+		RepoID: 1,
+		Name:   "nightly",
+		Expr:   "@daily",
+	})
+	s.CronCreate(&model.Cron{
+		RepoID: 1,
+		Name:   "weekly",
+		Expr:   "@weekly",
+	})
+
+	list, err := s.CronList(&model.Repo{ID: 1})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(list), 2; got != want {
+		t.Errorf("Want %d crons, got %d", want, got)
+	}
+}
+
+func TestCronListReady(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from crons")
+		s.Close()
+	}()
+
+	s.CronCreate(&model.Cron{
+		RepoID: 1,
+		Name:   "nightly",
+		Expr:   "@daily",
+		Next:   100,
+	})
+	s.CronCreate(&model.Cron{
+		RepoID: 1,
+		Name:   "weekly",
+		Expr:   "@weekly",
+		Next:   300,
+	})
+	s.CronCreate(&model.Cron{
+		RepoID:   2,
+		Name:     "nightly",
+		Expr:     "@daily",
+		Next:     100,
+		Disabled: true,
+	})
+
+	list, err := s.CronListReady(200)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(list), 1; got != want {
+		t.Errorf("Want %d crons, got %d", want, got)
+		return
+	}
+	if got, want := list[0].Name, "nightly"; got != want {
+		t.Errorf("Want cron name %s, got %s", want, got)
+	}
+}
+
+func TestCronUpdate(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from crons")
+		s.Close()
+	}()
+
+	cron := &model.Cron{
+		RepoID: 1,
+		Name:   "nightly",
+		Expr:   "@daily",
+	}
+	if err := s.CronCreate(cron); err != nil {
+		t.Errorf("Unexpected error: insert cron: %s", err)
+		return
+	}
+	cron.Expr = "@hourly"
+	if err := s.CronUpdate(cron); err != nil {
+		t.Errorf("Unexpected error: update cron: %s", err)
+		return
+	}
+	updated, err := s.CronFind(&model.Repo{ID: 1}, "nightly")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := updated.Expr, "@hourly"; got != want {
+		t.Errorf("Want cron expr %s, got %s", want, got)
+	}
+}
+
+func TestCronClaim(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from crons")
+		s.Close()
+	}()
+
+	cron := &model.Cron{
+		RepoID: 1,
+		Name:   "nightly",
+		Expr:   "@daily",
+		Next:   100,
+	}
+	if err := s.CronCreate(cron); err != nil {
+		t.Errorf("Unexpected error: insert cron: %s", err)
+		return
+	}
+
+	first := *cron
+	first.Prev = 100
+	first.Next = 200
+	claimed, err := s.CronClaim(&first, 100)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !claimed {
+		t.Errorf("Want cron claimed")
+	}
+
+	second := *cron
+	second.Prev = 100
+	second.Next = 200
+	claimed, err = s.CronClaim(&second, 100)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if claimed {
+		t.Errorf("Want cron claimed only once")
+	}
+
+	updated, err := s.CronFind(&model.Repo{ID: 1}, "nightly")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := updated.Next, int64(200); got != want {
+		t.Errorf("Want cron next %d, got %d", want, got)
+	}
+	if got, want := updated.Prev, int64(100); got != want {
+		t.Errorf("Want cron prev %d, got %d", want, got)
+	}
+}
+
+func TestCronDelete(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from crons")
+		s.Close()
+	}()
+
+	cron := &model.Cron{
+		RepoID: 1,
+		Name:   "nightly",
+		Expr:   "@daily",
+	}
+	if err := s.CronCreate(cron); err != nil {
+		t.Errorf("Unexpected error: insert cron: %s", err)
+		return
+	}
+
+	if err := s.CronDelete(cron); err != nil {
+		t.Errorf("Unexpected error: delete cron: %s", err)
+		return
+	}
+	_, err := s.CronFind(&model.Repo{ID: 1}, "nightly")
+	if err == nil {
+		t.Errorf("Expect error: sql.ErrNoRows")
+		return
+	}
+}
+
+func TestCronIndexes(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from crons")
+		s.Close()
+	}()
+
+	if err := s.CronCreate(&model.Cron{
+		RepoID: 1,
+		Name:   "nightly",
+		Expr:   "@daily",
+	}); err != nil {
+		t.Errorf("Unexpected error: insert cron: %s", err)
+		return
+	}
+
+	// fail due to duplicate name
+	if err := s.CronCreate(&model.Cron{
+		RepoID: 1,
+		Name:   "nightly",
+		Expr:   "@weekly",
+	}); err == nil {
+		t.Errorf("Unexpected error: dupliate name")
+	}
+}
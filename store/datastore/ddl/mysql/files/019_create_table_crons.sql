@@ -0,0 +1,24 @@
+-- name: create-table-crons
+
+CREATE TABLE IF NOT EXISTS crons (
+ cron_id       INTEGER PRIMARY KEY AUTO_INCREMENT
+,cron_repo_id  INTEGER
+,cron_name     VARCHAR(250)
+,cron_expr     VARCHAR(250)
+,cron_branch   VARCHAR(250)
+,cron_next     INTEGER
+,cron_prev     INTEGER
+,cron_disabled BOOLEAN
+,cron_created  INTEGER
+,cron_updated  INTEGER
+
+,UNIQUE(cron_repo_id, cron_name)
+);
+
+-- name: create-index-crons-repo
+
+CREATE INDEX ix_crons_repo ON crons (cron_repo_id);
+
+-- name: create-index-crons-next
+
+CREATE INDEX ix_crons_next ON crons (cron_next);
@@ -0,0 +1,18 @@
+-- name: create-table-org-secrets
+
+CREATE TABLE IF NOT EXISTS org_secrets (
+ secret_id          INTEGER PRIMARY KEY AUTO_INCREMENT
+,secret_owner       VARCHAR(250)
+,secret_name        VARCHAR(250)
+,secret_value       MEDIUMBLOB
+,secret_images      VARCHAR(2000)
+,secret_events      VARCHAR(2000)
+,secret_skip_verify BOOLEAN
+,secret_conceal     BOOLEAN
+
+,UNIQUE(secret_owner, secret_name)
+);
+
+-- name: create-index-org-secrets-owner
+
+CREATE INDEX ix_org_secrets_owner ON org_secrets (secret_owner);
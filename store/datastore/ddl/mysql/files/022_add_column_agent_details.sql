@@ -0,0 +1,19 @@
+-- name: alter-table-add-agent-labels
+
+ALTER TABLE agents ADD COLUMN agent_labels VARCHAR(2000);
+
+-- name: alter-table-add-agent-filter
+
+ALTER TABLE agents ADD COLUMN agent_filter VARCHAR(2000);
+
+-- name: alter-table-add-agent-version
+
+ALTER TABLE agents ADD COLUMN agent_version VARCHAR(250);
+
+-- name: alter-table-add-agent-draining
+
+ALTER TABLE agents ADD COLUMN agent_draining BOOLEAN;
+
+-- name: update-table-set-agent-details
+
+UPDATE agents SET agent_labels = '{}', agent_filter = '', agent_version = '', agent_draining = false;
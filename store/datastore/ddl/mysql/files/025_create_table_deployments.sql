@@ -0,0 +1,24 @@
+-- name: create-table-deployments
+
+CREATE TABLE IF NOT EXISTS deployments (
+ deployment_id       INTEGER PRIMARY KEY AUTO_INCREMENT
+,deployment_repo_id  INTEGER
+,deployment_build_id INTEGER
+,deployment_number   INTEGER
+,deployment_source   INTEGER
+,deployment_target   VARCHAR(250)
+,deployment_commit   VARCHAR(250)
+,deployment_status   VARCHAR(50)
+,deployment_rollback BOOLEAN
+,deployment_creator  VARCHAR(250)
+,deployment_created  INTEGER
+,deployment_updated  INTEGER
+);
+
+-- name: create-index-deployments-repo
+
+CREATE INDEX ix_deployments_repo ON deployments (deployment_repo_id, deployment_target);
+
+-- name: create-index-deployments-build
+
+CREATE INDEX ix_deployments_build ON deployments (deployment_build_id);
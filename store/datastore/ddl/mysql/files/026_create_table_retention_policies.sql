@@ -0,0 +1,14 @@
+-- name: create-table-retention-policies
+
+CREATE TABLE IF NOT EXISTS retention_policies (
+ policy_id            INTEGER PRIMARY KEY AUTO_INCREMENT
+,policy_repo_id       INTEGER
+,policy_max_age       INTEGER
+,policy_keep_builds   INTEGER
+,policy_keep_success  BOOLEAN
+,policy_delete_builds BOOLEAN
+,policy_created       INTEGER
+,policy_updated       INTEGER
+
+,UNIQUE(policy_repo_id)
+);
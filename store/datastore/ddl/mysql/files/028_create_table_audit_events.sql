@@ -0,0 +1,20 @@
+-- name: create-table-audit-events
+
+CREATE TABLE IF NOT EXISTS audit_events (
+ audit_id      INTEGER PRIMARY KEY AUTO_INCREMENT
+,audit_actor   VARCHAR(250)
+,audit_action  VARCHAR(250)
+,audit_repo    VARCHAR(250)
+,audit_target  VARCHAR(500)
+,audit_ip      VARCHAR(250)
+,audit_diff    MEDIUMTEXT
+,audit_created INTEGER
+);
+
+-- name: create-index-audit-events-actor
+
+CREATE INDEX ix_audit_events_actor ON audit_events (audit_actor);
+
+-- name: create-index-audit-events-repo
+
+CREATE INDEX ix_audit_events_repo ON audit_events (audit_repo);
@@ -0,0 +1,23 @@
+-- name: create-index-builds-repo-branch
+
+CREATE INDEX ix_build_repo_branch ON builds (build_repo_id, build_branch);
+
+-- name: create-index-builds-repo-event
+
+CREATE INDEX ix_build_repo_event ON builds (build_repo_id, build_event);
+
+-- name: create-index-builds-repo-status
+
+CREATE INDEX ix_build_repo_status ON builds (build_repo_id, build_status);
+
+-- name: create-index-builds-repo-author
+
+CREATE INDEX ix_build_repo_author ON builds (build_repo_id, build_author);
+
+-- name: create-index-builds-repo-commit
+
+CREATE INDEX ix_build_repo_commit ON builds (build_repo_id, build_commit);
+
+-- name: create-index-builds-repo-created
+
+CREATE INDEX ix_build_repo_created ON builds (build_repo_id, build_created);
@@ -0,0 +1,11 @@
+-- name: alter-table-add-repo-cancel-push
+
+ALTER TABLE repos ADD COLUMN repo_cancel_push BOOLEAN;
+
+-- name: alter-table-add-repo-cancel-pull
+
+ALTER TABLE repos ADD COLUMN repo_cancel_pull BOOLEAN;
+
+-- name: update-table-set-repo-cancel
+
+UPDATE repos SET repo_cancel_push = false, repo_cancel_pull = false;
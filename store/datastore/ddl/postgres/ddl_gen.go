@@ -170,6 +170,166 @@ var migrations = []struct {
 		name: "alter-table-update-file-meta",
 		stmt: alterTableUpdateFileMeta,
 	},
+	{
+		name: "create-table-crons",
+		stmt: createTableCrons,
+	},
+	{
+		name: "create-index-crons-repo",
+		stmt: createIndexCronsRepo,
+	},
+	{
+		name: "create-index-crons-next",
+		stmt: createIndexCronsNext,
+	},
+	{
+		name: "create-table-org-secrets",
+		stmt: createTableOrgSecrets,
+	},
+	{
+		name: "create-index-org-secrets-owner",
+		stmt: createIndexOrgSecretsOwner,
+	},
+	{
+		name: "alter-table-add-task-status",
+		stmt: alterTableAddTaskStatus,
+	},
+	{
+		name: "alter-table-add-task-error",
+		stmt: alterTableAddTaskError,
+	},
+	{
+		name: "alter-table-add-task-deadline",
+		stmt: alterTableAddTaskDeadline,
+	},
+	{
+		name: "alter-table-add-task-created",
+		stmt: alterTableAddTaskCreated,
+	},
+	{
+		name: "alter-table-add-task-updated",
+		stmt: alterTableAddTaskUpdated,
+	},
+	{
+		name: "update-table-set-task-status",
+		stmt: updateTableSetTaskStatus,
+	},
+	{
+		name: "alter-table-add-agent-labels",
+		stmt: alterTableAddAgentLabels,
+	},
+	{
+		name: "alter-table-add-agent-filter",
+		stmt: alterTableAddAgentFilter,
+	},
+	{
+		name: "alter-table-add-agent-version",
+		stmt: alterTableAddAgentVersion,
+	},
+	{
+		name: "alter-table-add-agent-draining",
+		stmt: alterTableAddAgentDraining,
+	},
+	{
+		name: "update-table-set-agent-details",
+		stmt: updateTableSetAgentDetails,
+	},
+	{
+		name: "create-table-agent-tokens",
+		stmt: createTableAgentTokens,
+	},
+	{
+		name: "create-table-webhooks",
+		stmt: createTableWebhooks,
+	},
+	{
+		name: "create-index-webhooks-repo",
+		stmt: createIndexWebhooksRepo,
+	},
+	{
+		name: "create-table-webhook-deliveries",
+		stmt: createTableWebhookDeliveries,
+	},
+	{
+		name: "create-index-webhook-deliveries-webhook",
+		stmt: createIndexWebhookDeliveriesWebhook,
+	},
+	{
+		name: "create-table-deployments",
+		stmt: createTableDeployments,
+	},
+	{
+		name: "create-index-deployments-repo",
+		stmt: createIndexDeploymentsRepo,
+	},
+	{
+		name: "create-index-deployments-build",
+		stmt: createIndexDeploymentsBuild,
+	},
+	{
+		name: "create-table-retention-policies",
+		stmt: createTableRetentionPolicies,
+	},
+	{
+		name: "alter-table-add-build-pruned",
+		stmt: alterTableAddBuildPruned,
+	},
+	{
+		name: "update-table-set-build-pruned",
+		stmt: updateTableSetBuildPruned,
+	},
+	{
+		name: "create-table-audit-events",
+		stmt: createTableAuditEvents,
+	},
+	{
+		name: "create-index-audit-events-actor",
+		stmt: createIndexAuditEventsActor,
+	},
+	{
+		name: "create-index-audit-events-repo",
+		stmt: createIndexAuditEventsRepo,
+	},
+	{
+		name: "create-index-builds-repo-branch",
+		stmt: createIndexBuildsRepoBranch,
+	},
+	{
+		name: "create-index-builds-repo-event",
+		stmt: createIndexBuildsRepoEvent,
+	},
+	{
+		name: "create-index-builds-repo-status",
+		stmt: createIndexBuildsRepoStatus,
+	},
+	{
+		name: "create-index-builds-repo-author",
+		stmt: createIndexBuildsRepoAuthor,
+	},
+	{
+		name: "create-index-builds-repo-commit",
+		stmt: createIndexBuildsRepoCommit,
+	},
+	{
+		name: "create-index-builds-repo-created",
+		stmt: createIndexBuildsRepoCreated,
+	},
+	{
+		name: "create-index-builds-created",
+		stmt: createIndexBuildsCreated,
+	},
+	{
+		name: "alter-table-add-repo-cancel-push",
+		stmt: alterTableAddRepoCancelPush,
+	},
+	{
+		name: "alter-table-add-repo-cancel-pull",
+		stmt: alterTableAddRepoCancelPull,
+	},
+	{
+		name: "update-table-set-repo-cancel",
+		stmt: updateTableSetRepoCancel,
+	},
 }
 
 // Migrate performs the database migration. If the migration fails
@@ -636,3 +796,303 @@ UPDATE files SET
 ,file_meta_failed=0
 ,file_meta_skipped=0
 `
+
+//
+// 019_create_table_crons.sql
+//
+
+var createTableCrons = `
+CREATE TABLE IF NOT EXISTS crons (
+ cron_id       SERIAL PRIMARY KEY
+,cron_repo_id  INTEGER
+,cron_name     VARCHAR(250)
+,cron_expr     VARCHAR(250)
+,cron_branch   VARCHAR(250)
+,cron_next     INTEGER
+,cron_prev     INTEGER
+,cron_disabled BOOLEAN
+,cron_created  INTEGER
+,cron_updated  INTEGER
+
+,UNIQUE(cron_repo_id, cron_name)
+);
+`
+
+var createIndexCronsRepo = `
+CREATE INDEX IF NOT EXISTS ix_crons_repo ON crons (cron_repo_id);
+`
+
+var createIndexCronsNext = `
+CREATE INDEX IF NOT EXISTS ix_crons_next ON crons (cron_next);
+`
+
+//
+// 020_create_table_org_secrets.sql
+//
+
+var createTableOrgSecrets = `
+CREATE TABLE IF NOT EXISTS org_secrets (
+ secret_id          SERIAL PRIMARY KEY
+,secret_owner       VARCHAR(250)
+,secret_name        VARCHAR(250)
+,secret_value       BYTEA
+,secret_images      VARCHAR(2000)
+,secret_events      VARCHAR(2000)
+,secret_skip_verify BOOLEAN
+,secret_conceal     BOOLEAN
+
+,UNIQUE(secret_owner, secret_name)
+);
+`
+
+var createIndexOrgSecretsOwner = `
+CREATE INDEX IF NOT EXISTS ix_org_secrets_owner ON org_secrets (secret_owner);
+`
+
+//
+// 021_add_column_task_status.sql
+//
+
+var alterTableAddTaskStatus = `
+ALTER TABLE tasks ADD COLUMN task_status VARCHAR(20);
+`
+
+var alterTableAddTaskError = `
+ALTER TABLE tasks ADD COLUMN task_error VARCHAR(500);
+`
+
+var alterTableAddTaskDeadline = `
+ALTER TABLE tasks ADD COLUMN task_deadline INTEGER;
+`
+
+var alterTableAddTaskCreated = `
+ALTER TABLE tasks ADD COLUMN task_created INTEGER;
+`
+
+var alterTableAddTaskUpdated = `
+ALTER TABLE tasks ADD COLUMN task_updated INTEGER;
+`
+
+var updateTableSetTaskStatus = `
+UPDATE tasks SET task_status = 'pending', task_error = '', task_deadline = 0, task_created = 0, task_updated = 0;
+`
+
+//
+// 022_add_column_agent_details.sql
+//
+
+var alterTableAddAgentLabels = `
+ALTER TABLE agents ADD COLUMN agent_labels VARCHAR(2000);
+`
+
+var alterTableAddAgentFilter = `
+ALTER TABLE agents ADD COLUMN agent_filter VARCHAR(2000);
+`
+
+var alterTableAddAgentVersion = `
+ALTER TABLE agents ADD COLUMN agent_version VARCHAR(250);
+`
+
+var alterTableAddAgentDraining = `
+ALTER TABLE agents ADD COLUMN agent_draining BOOLEAN;
+`
+
+var updateTableSetAgentDetails = `
+UPDATE agents SET agent_labels = '{}', agent_filter = '', agent_version = '', agent_draining = false;
+`
+
+//
+// 023_create_table_agent_tokens.sql
+//
+
+var createTableAgentTokens = `
+CREATE TABLE IF NOT EXISTS agent_tokens (
+ token_id        SERIAL PRIMARY KEY
+,token_name      VARCHAR(250)
+,token_hash      VARCHAR(250)
+,token_platforms VARCHAR(2000)
+,token_labels    VARCHAR(2000)
+,token_created   INTEGER
+
+,UNIQUE(token_name)
+,UNIQUE(token_hash)
+);
+`
+
+//
+// 024_create_table_webhooks.sql
+//
+
+var createTableWebhooks = `
+CREATE TABLE IF NOT EXISTS webhooks (
+ webhook_id          SERIAL PRIMARY KEY
+,webhook_repo_id     INTEGER
+,webhook_url         VARCHAR(2000)
+,webhook_secret      VARCHAR(500)
+,webhook_events      VARCHAR(2000)
+,webhook_skip_verify BOOLEAN
+,webhook_disabled    BOOLEAN
+,webhook_created     INTEGER
+,webhook_updated     INTEGER
+);
+`
+
+var createIndexWebhooksRepo = `
+CREATE INDEX IF NOT EXISTS ix_webhooks_repo ON webhooks (webhook_repo_id);
+`
+
+var createTableWebhookDeliveries = `
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+ delivery_id         SERIAL PRIMARY KEY
+,delivery_webhook_id INTEGER
+,delivery_event      VARCHAR(50)
+,delivery_payload    BYTEA
+,delivery_status     INTEGER
+,delivery_error      VARCHAR(500)
+,delivery_attempts   INTEGER
+,delivery_redelivery BOOLEAN
+,delivery_created    INTEGER
+,delivery_updated    INTEGER
+);
+`
+
+var createIndexWebhookDeliveriesWebhook = `
+CREATE INDEX IF NOT EXISTS ix_webhook_deliveries_webhook ON webhook_deliveries (delivery_webhook_id);
+`
+
+//
+// 025_create_table_deployments.sql
+//
+
+var createTableDeployments = `
+CREATE TABLE IF NOT EXISTS deployments (
+ deployment_id       SERIAL PRIMARY KEY
+,deployment_repo_id  INTEGER
+,deployment_build_id INTEGER
+,deployment_number   INTEGER
+,deployment_source   INTEGER
+,deployment_target   VARCHAR(250)
+,deployment_commit   VARCHAR(250)
+,deployment_status   VARCHAR(50)
+,deployment_rollback BOOLEAN
+,deployment_creator  VARCHAR(250)
+,deployment_created  INTEGER
+,deployment_updated  INTEGER
+);
+`
+
+var createIndexDeploymentsRepo = `
+CREATE INDEX IF NOT EXISTS ix_deployments_repo ON deployments (deployment_repo_id, deployment_target);
+`
+
+var createIndexDeploymentsBuild = `
+CREATE INDEX IF NOT EXISTS ix_deployments_build ON deployments (deployment_build_id);
+`
+
+//
+// 026_create_table_retention_policies.sql
+//
+
+var createTableRetentionPolicies = `
+CREATE TABLE IF NOT EXISTS retention_policies (
+ policy_id            SERIAL PRIMARY KEY
+,policy_repo_id       INTEGER
+,policy_max_age       INTEGER
+,policy_keep_builds   INTEGER
+,policy_keep_success  BOOLEAN
+,policy_delete_builds BOOLEAN
+,policy_created       INTEGER
+,policy_updated       INTEGER
+
+,UNIQUE(policy_repo_id)
+);
+`
+
+//
+// 027_add_column_build_pruned.sql
+//
+
+var alterTableAddBuildPruned = `
+ALTER TABLE builds ADD COLUMN build_pruned BOOLEAN;
+`
+
+var updateTableSetBuildPruned = `
+UPDATE builds SET build_pruned = false;
+`
+
+//
+// 028_create_table_audit_events.sql
+//
+
+var createTableAuditEvents = `
+CREATE TABLE IF NOT EXISTS audit_events (
+ audit_id      SERIAL PRIMARY KEY
+,audit_actor   VARCHAR(250)
+,audit_action  VARCHAR(250)
+,audit_repo    VARCHAR(250)
+,audit_target  VARCHAR(500)
+,audit_ip      VARCHAR(250)
+,audit_diff    TEXT
+,audit_created INTEGER
+);
+`
+
+var createIndexAuditEventsActor = `
+CREATE INDEX IF NOT EXISTS ix_audit_events_actor ON audit_events (audit_actor);
+`
+
+var createIndexAuditEventsRepo = `
+CREATE INDEX IF NOT EXISTS ix_audit_events_repo ON audit_events (audit_repo);
+`
+
+//
+// 029_create_index_builds_filters.sql
+//
+
+var createIndexBuildsRepoBranch = `
+CREATE INDEX IF NOT EXISTS ix_build_repo_branch ON builds (build_repo_id, build_branch);
+`
+
+var createIndexBuildsRepoEvent = `
+CREATE INDEX IF NOT EXISTS ix_build_repo_event ON builds (build_repo_id, build_event);
+`
+
+var createIndexBuildsRepoStatus = `
+CREATE INDEX IF NOT EXISTS ix_build_repo_status ON builds (build_repo_id, build_status);
+`
+
+var createIndexBuildsRepoAuthor = `
+CREATE INDEX IF NOT EXISTS ix_build_repo_author ON builds (build_repo_id, build_author);
+`
+
+var createIndexBuildsRepoCommit = `
+CREATE INDEX IF NOT EXISTS ix_build_repo_commit ON builds (build_repo_id, build_commit);
+`
+
+var createIndexBuildsRepoCreated = `
+CREATE INDEX IF NOT EXISTS ix_build_repo_created ON builds (build_repo_id, build_created);
+`
+
+//
+// 030_create_index_builds_created.sql
+//
+
+var createIndexBuildsCreated = `
+CREATE INDEX IF NOT EXISTS ix_build_created ON builds (build_created);
+`
+
+//
+// 031_add_column_repo_cancel.sql
+//
+
+var alterTableAddRepoCancelPush = `
+ALTER TABLE repos ADD COLUMN repo_cancel_push BOOLEAN;
+`
+
+var alterTableAddRepoCancelPull = `
+ALTER TABLE repos ADD COLUMN repo_cancel_pull BOOLEAN;
+`
+
+var updateTableSetRepoCancel = `
+UPDATE repos SET repo_cancel_push = false, repo_cancel_pull = false;
+`
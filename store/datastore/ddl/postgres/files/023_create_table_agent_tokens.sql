@@ -0,0 +1,13 @@
+-- name: create-table-agent-tokens
+
+CREATE TABLE IF NOT EXISTS agent_tokens (
+ token_id        SERIAL PRIMARY KEY
+,token_name      VARCHAR(250)
+,token_hash      VARCHAR(250)
+,token_platforms VARCHAR(2000)
+,token_labels    VARCHAR(2000)
+,token_created   INTEGER
+
+,UNIQUE(token_name)
+,UNIQUE(token_hash)
+);
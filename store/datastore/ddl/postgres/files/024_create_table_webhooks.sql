@@ -0,0 +1,36 @@
+-- name: create-table-webhooks
+
+CREATE TABLE IF NOT EXISTS webhooks (
+ webhook_id          SERIAL PRIMARY KEY
+,webhook_repo_id     INTEGER
+,webhook_url         VARCHAR(2000)
+,webhook_secret      VARCHAR(500)
+,webhook_events      VARCHAR(2000)
+,webhook_skip_verify BOOLEAN
+,webhook_disabled    BOOLEAN
+,webhook_created     INTEGER
+,webhook_updated     INTEGER
+);
+
+-- name: create-index-webhooks-repo
+
+CREATE INDEX IF NOT EXISTS ix_webhooks_repo ON webhooks (webhook_repo_id);
+
+-- name: create-table-webhook-deliveries
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+ delivery_id         SERIAL PRIMARY KEY
+,delivery_webhook_id INTEGER
+,delivery_event      VARCHAR(50)
+,delivery_payload    BYTEA
+,delivery_status     INTEGER
+,delivery_error      VARCHAR(500)
+,delivery_attempts   INTEGER
+,delivery_redelivery BOOLEAN
+,delivery_created    INTEGER
+,delivery_updated    INTEGER
+);
+
+-- name: create-index-webhook-deliveries-webhook
+
+CREATE INDEX IF NOT EXISTS ix_webhook_deliveries_webhook ON webhook_deliveries (delivery_webhook_id);
@@ -0,0 +1,20 @@
+-- name: create-table-audit-events
+
+CREATE TABLE IF NOT EXISTS audit_events (
+ audit_id      SERIAL PRIMARY KEY
+,audit_actor   VARCHAR(250)
+,audit_action  VARCHAR(250)
+,audit_repo    VARCHAR(250)
+,audit_target  VARCHAR(500)
+,audit_ip      VARCHAR(250)
+,audit_diff    TEXT
+,audit_created INTEGER
+);
+
+-- name: create-index-audit-events-actor
+
+CREATE INDEX IF NOT EXISTS ix_audit_events_actor ON audit_events (audit_actor);
+
+-- name: create-index-audit-events-repo
+
+CREATE INDEX IF NOT EXISTS ix_audit_events_repo ON audit_events (audit_repo);
@@ -0,0 +1,3 @@
+-- name: create-index-builds-created
+
+CREATE INDEX IF NOT EXISTS ix_build_created ON builds (build_created);
@@ -0,0 +1,18 @@
+-- name: create-table-org-secrets
+
+CREATE TABLE IF NOT EXISTS org_secrets (
+ secret_id          INTEGER PRIMARY KEY AUTOINCREMENT
+,secret_owner       TEXT
+,secret_name        TEXT
+,secret_value       BLOB
+,secret_images      TEXT
+,secret_events      TEXT
+,secret_skip_verify BOOLEAN
+,secret_conceal     BOOLEAN
+
+,UNIQUE(secret_owner, secret_name)
+);
+
+-- name: create-index-org-secrets-owner
+
+CREATE INDEX IF NOT EXISTS ix_org_secrets_owner ON org_secrets (secret_owner);
@@ -0,0 +1,23 @@
+-- name: alter-table-add-task-status
+
+ALTER TABLE tasks ADD COLUMN task_status TEXT;
+
+-- name: alter-table-add-task-error
+
+ALTER TABLE tasks ADD COLUMN task_error TEXT;
+
+-- name: alter-table-add-task-deadline
+
+ALTER TABLE tasks ADD COLUMN task_deadline INTEGER;
+
+-- name: alter-table-add-task-created
+
+ALTER TABLE tasks ADD COLUMN task_created INTEGER;
+
+-- name: alter-table-add-task-updated
+
+ALTER TABLE tasks ADD COLUMN task_updated INTEGER;
+
+-- name: update-table-set-task-status
+
+UPDATE tasks SET task_status = 'pending', task_error = '', task_deadline = 0, task_created = 0, task_updated = 0;
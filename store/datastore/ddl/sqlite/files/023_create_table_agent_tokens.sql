@@ -0,0 +1,13 @@
+-- name: create-table-agent-tokens
+
+CREATE TABLE IF NOT EXISTS agent_tokens (
+ token_id        INTEGER PRIMARY KEY AUTOINCREMENT
+,token_name      TEXT
+,token_hash      TEXT
+,token_platforms TEXT
+,token_labels    TEXT
+,token_created   INTEGER
+
+,UNIQUE(token_name)
+,UNIQUE(token_hash)
+);
@@ -0,0 +1,36 @@
+-- name: create-table-webhooks
+
+CREATE TABLE IF NOT EXISTS webhooks (
+ webhook_id          INTEGER PRIMARY KEY AUTOINCREMENT
+,webhook_repo_id     INTEGER
+,webhook_url         TEXT
+,webhook_secret      TEXT
+,webhook_events      TEXT
+,webhook_skip_verify BOOLEAN
+,webhook_disabled    BOOLEAN
+,webhook_created     INTEGER
+,webhook_updated     INTEGER
+);
+
+-- name: create-index-webhooks-repo
+
+CREATE INDEX IF NOT EXISTS ix_webhooks_repo ON webhooks (webhook_repo_id);
+
+-- name: create-table-webhook-deliveries
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+ delivery_id         INTEGER PRIMARY KEY AUTOINCREMENT
+,delivery_webhook_id INTEGER
+,delivery_event      TEXT
+,delivery_payload    BLOB
+,delivery_status     INTEGER
+,delivery_error      TEXT
+,delivery_attempts   INTEGER
+,delivery_redelivery BOOLEAN
+,delivery_created    INTEGER
+,delivery_updated    INTEGER
+);
+
+-- name: create-index-webhook-deliveries-webhook
+
+CREATE INDEX IF NOT EXISTS ix_webhook_deliveries_webhook ON webhook_deliveries (delivery_webhook_id);
@@ -0,0 +1,7 @@
+-- name: alter-table-add-build-pruned
+
+ALTER TABLE builds ADD COLUMN build_pruned BOOLEAN;
+
+-- name: update-table-set-build-pruned
+
+UPDATE builds SET build_pruned = 0;
@@ -0,0 +1,20 @@
+-- name: create-table-audit-events
+
+CREATE TABLE IF NOT EXISTS audit_events (
+ audit_id      INTEGER PRIMARY KEY AUTOINCREMENT
+,audit_actor   TEXT
+,audit_action  TEXT
+,audit_repo    TEXT
+,audit_target  TEXT
+,audit_ip      TEXT
+,audit_diff    TEXT
+,audit_created INTEGER
+);
+
+-- name: create-index-audit-events-actor
+
+CREATE INDEX IF NOT EXISTS ix_audit_events_actor ON audit_events (audit_actor);
+
+-- name: create-index-audit-events-repo
+
+CREATE INDEX IF NOT EXISTS ix_audit_events_repo ON audit_events (audit_repo);
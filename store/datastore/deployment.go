@@ -0,0 +1,48 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"github.com/drone/drone/model"
+	"github.com/drone/drone/store/datastore/sql"
+	"github.com/russross/meddler"
+)
+
+func (db *datastore) DeploymentFindBuild(build *model.Build) (*model.Deployment, error) {
+	stmt := sql.Lookup(db.driver, "deployment-find-build")
+	data := new(model.Deployment)
+	err := meddler.QueryRow(db, data, stmt, build.ID)
+	return data, err
+}
+
+func (db *datastore) DeploymentList(repo *model.Repo, target string) ([]*model.Deployment, error) {
+	data := []*model.Deployment{}
+	if target == "" {
+		stmt := sql.Lookup(db.driver, "deployment-find-repo")
+		err := meddler.QueryAll(db, &data, stmt, repo.ID)
+		return data, err
+	}
+	stmt := sql.Lookup(db.driver, "deployment-find-repo-target")
+	err := meddler.QueryAll(db, &data, stmt, repo.ID, target)
+	return data, err
+}
+
+func (db *datastore) DeploymentCreate(deployment *model.Deployment) error {
+	return meddler.Insert(db, "deployments", deployment)
+}
+
+func (db *datastore) DeploymentUpdate(deployment *model.Deployment) error {
+	return meddler.Update(db, "deployments", deployment)
+}
@@ -0,0 +1,88 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"testing"
+
+	"github.com/drone/drone/model"
+)
+
+func TestDeploymentList(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from deployments")
+		s.Close()
+	}()
+
+	repo := &model.Repo{ID: 1}
+	s.DeploymentCreate(&model.Deployment{RepoID: 1, BuildID: 10, Number: 10, Source: 8, Target: "production", Status: model.StatusSuccess})
+	s.DeploymentCreate(&model.Deployment{RepoID: 1, BuildID: 11, Number: 11, Source: 9, Target: "staging", Status: model.StatusSuccess})
+	s.DeploymentCreate(&model.Deployment{RepoID: 1, BuildID: 12, Number: 12, Source: 9, Target: "production", Status: model.StatusPending})
+	s.DeploymentCreate(&model.Deployment{RepoID: 2, BuildID: 13, Number: 1, Source: 1, Target: "production", Status: model.StatusSuccess})
+
+	list, err := s.DeploymentList(repo, "")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(list), 3; got != want {
+		t.Errorf("Want %d deployments, got %d", want, got)
+	}
+
+	list, err = s.DeploymentList(repo, "production")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(list), 2; got != want {
+		t.Errorf("Want %d production deployments, got %d", want, got)
+		return
+	}
+	if got, want := list[0].Number, 12; got != want {
+		t.Errorf("Want most recent deployment %d first, got %d", want, got)
+	}
+}
+
+func TestDeploymentFindBuild(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from deployments")
+		s.Close()
+	}()
+
+	deployment := &model.Deployment{RepoID: 1, BuildID: 10, Number: 10, Source: 8, Target: "production", Status: model.StatusPending}
+	if err := s.DeploymentCreate(deployment); err != nil {
+		t.Errorf("Unexpected error: insert deployment: %s", err)
+		return
+	}
+	deployment.Status = model.StatusSuccess
+	if err := s.DeploymentUpdate(deployment); err != nil {
+		t.Errorf("Unexpected error: update deployment: %s", err)
+		return
+	}
+
+	found, err := s.DeploymentFindBuild(&model.Build{ID: 10})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := found.Status, model.StatusSuccess; got != want {
+		t.Errorf("Want deployment status %s, got %s", want, got)
+	}
+	if got, want := found.Target, "production"; got != want {
+		t.Errorf("Want deployment target %s, got %s", want, got)
+	}
+}
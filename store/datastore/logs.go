@@ -43,6 +43,33 @@ func (db *datastore) LogSave(proc *model.Proc, r io.Reader) error {
 	return meddler.Save(db, "logs", data)
 }
 
+func (db *datastore) LogDelete(proc *model.Proc) error {
+	stmt := sql.Lookup(db.driver, "logs-delete-proc")
+	_, err := db.Exec(stmt, proc.ID)
+	return err
+}
+
+// LogListProcs returns the identifiers of procs with logs stored in the
+// database, in ascending order, starting after the given identifier.
+func (db *datastore) LogListProcs(after int64, limit int) ([]int64, error) {
+	stmt := sql.Lookup(db.driver, "logs-find-after")
+	rows, err := db.Query(stmt, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
 type logData struct {
 	ID     int64  `meddler:"log_id,pk"`
 	ProcID int64  `meddler:"log_job_id"`
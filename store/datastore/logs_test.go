@@ -82,3 +82,35 @@ func TestLogUpdate(t *testing.T) {
 		t.Errorf("Want log data %s, got %s", want, got)
 	}
 }
+
+func TestLogDelete(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from logs")
+		s.Close()
+	}()
+
+	for _, id := range []int64{1, 2, 3} {
+		s.LogSave(&model.Proc{ID: id}, bytes.NewBufferString("echo hi"))
+	}
+	if err := s.LogDelete(&model.Proc{ID: 2}); err != nil {
+		t.Errorf("Unexpected error: log delete: %s", err)
+		return
+	}
+	if _, err := s.LogFind(&model.Proc{ID: 2}); err == nil {
+		t.Errorf("Want log deleted")
+	}
+
+	ids, err := s.LogListProcs(1, 10)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(ids), 1; got != want {
+		t.Errorf("Want %d logs after proc 1, got %d", want, got)
+		return
+	}
+	if got, want := ids[0], int64(3); got != want {
+		t.Errorf("Want log for proc %d, got %d", want, got)
+	}
+}
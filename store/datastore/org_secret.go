@@ -0,0 +1,95 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"github.com/drone/drone/model"
+	"github.com/drone/drone/store/datastore/sql"
+	"github.com/russross/meddler"
+)
+
+// orgSecret is the storage representation of a secret shared by an
+// owner or, when the owner is empty, by the entire system.
+type orgSecret struct {
+	ID         int64    `meddler:"secret_id,pk"`
+	Owner      string   `meddler:"secret_owner"`
+	Name       string   `meddler:"secret_name"`
+	Value      string   `meddler:"secret_value"`
+	Images     []string `meddler:"secret_images,json"`
+	Events     []string `meddler:"secret_events,json"`
+	SkipVerify bool     `meddler:"secret_skip_verify"`
+	Conceal    bool     `meddler:"secret_conceal"`
+}
+
+func (db *datastore) OrgSecretFind(owner, name string) (*model.Secret, error) {
+	stmt := sql.Lookup(db.driver, "org-secret-find-owner-name")
+	data := new(orgSecret)
+	err := meddler.QueryRow(db, data, stmt, owner, name)
+	return fromOrgSecret(data), err
+}
+
+func (db *datastore) OrgSecretList(owner string) ([]*model.Secret, error) {
+	stmt := sql.Lookup(db.driver, "org-secret-find-owner")
+	data := []*orgSecret{}
+	err := meddler.QueryAll(db, &data, stmt, owner)
+	list := make([]*model.Secret, 0, len(data))
+	for _, secret := range data {
+		list = append(list, fromOrgSecret(secret))
+	}
+	return list, err
+}
+
+func (db *datastore) OrgSecretCreate(secret *model.Secret) error {
+	data := toOrgSecret(secret)
+	err := meddler.Insert(db, "org_secrets", data)
+	secret.ID = data.ID
+	return err
+}
+
+func (db *datastore) OrgSecretUpdate(secret *model.Secret) error {
+	return meddler.Update(db, "org_secrets", toOrgSecret(secret))
+}
+
+func (db *datastore) OrgSecretDelete(secret *model.Secret) error {
+	stmt := sql.Lookup(db.driver, "org-secret-delete")
+	_, err := db.Exec(stmt, secret.ID)
+	return err
+}
+
+func toOrgSecret(from *model.Secret) *orgSecret {
+	return &orgSecret{
+		ID:         from.ID,
+		Owner:      from.Owner,
+		Name:       from.Name,
+		Value:      from.Value,
+		Images:     from.Images,
+		Events:     from.Events,
+		SkipVerify: from.SkipVerify,
+		Conceal:    from.Conceal,
+	}
+}
+
+func fromOrgSecret(from *orgSecret) *model.Secret {
+	return &model.Secret{
+		ID:         from.ID,
+		Owner:      from.Owner,
+		Name:       from.Name,
+		Value:      from.Value,
+		Images:     from.Images,
+		Events:     from.Events,
+		SkipVerify: from.SkipVerify,
+		Conceal:    from.Conceal,
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"testing"
+
+	"github.com/drone/drone/model"
+)
+
+func TestOrgSecretFind(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from org_secrets")
+		s.Close()
+	}()
+
+	err := s.OrgSecretCreate(&model.Secret{
+		Owner:  "octocat",
+		Name:   "password",
+		Value:  "correct-horse-battery-staple",
+		Images: []string{"golang", "node"},
+		Events: []string{"push", "tag"},
+	})
+	if err != nil {
+		t.Errorf("Unexpected error: insert secret: %s", err)
+		return
+	}
+
+	secret, err := s.OrgSecretFind("octocat", "password")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := secret.Owner, "octocat"; got != want {
+		t.Errorf("Want secret owner %s, got %s", want, got)
+	}
+	if got, want := secret.Name, "password"; got != want {
+		t.Errorf("Want secret name %s, got %s", want, got)
+	}
+	if got, want := secret.Value, "correct-horse-battery-staple"; got != want {
+		t.Errorf("Want secret value %s, got %s", want, got)
+	}
+	if got, want := secret.Events[1], "tag"; got != want {
+		t.Errorf("Want secret event %s, got %s", want, got)
+	}
+	if got, want := secret.Images[1], "node"; got != want {
+		t.Errorf("Want secret image %s, got %s", want, got)
+	}
+
+	if _, err := s.OrgSecretFind(model.GlobalOwner, "password"); err == nil {
+		t.Errorf("Want error finding org secret in the global scope")
+	}
+}
+
+func TestOrgSecretList(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from org_secrets")
+		s.Close()
+	}()
+
+	s.OrgSecretCreate(&model.Secret{
+		Owner: "octocat",
+		Name:  "foo",
+		Value: "bar",
+	})
+	s.OrgSecretCreate(&model.Secret{
+		Owner: "octocat",
+		Name:  "baz",
+		Value: "qux",
+	})
+	s.OrgSecretCreate(&model.Secret{
+		Owner: model.GlobalOwner,
+		Name:  "foo",
+		Value: "bar",
+	})
+
+	list, err := s.OrgSecretList("octocat")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(list), 2; got != want {
+		t.Errorf("Want %d org secrets, got %d", want, got)
+	}
+
+	list, err = s.OrgSecretList(model.GlobalOwner)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(list), 1; got != want {
+		t.Errorf("Want %d global secrets, got %d", want, got)
+	}
+}
+
+func TestOrgSecretUpdate(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from org_secrets")
+		s.Close()
+	}()
+
+	secret := &model.Secret{
+		Owner: "octocat",
+		Name:  "foo",
+		Value: "baz",
+	}
+	if err := s.OrgSecretCreate(secret); err != nil {
+		t.Errorf("Unexpected error: insert secret: %s", err)
+		return
+	}
+	secret.Value = "qux"
+	if err := s.OrgSecretUpdate(secret); err != nil {
+		t.Errorf("Unexpected error: update secret: %s", err)
+		return
+	}
+	updated, err := s.OrgSecretFind("octocat", "foo")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := updated.Value, "qux"; got != want {
+		t.Errorf("Want secret value %s, got %s", want, got)
+	}
+}
+
+func TestOrgSecretDelete(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from org_secrets")
+		s.Close()
+	}()
+
+	secret := &model.Secret{
+		Owner: "octocat",
+		Name:  "foo",
+		Value: "baz",
+	}
+	if err := s.OrgSecretCreate(secret); err != nil {
+		t.Errorf("Unexpected error: insert secret: %s", err)
+		return
+	}
+	if err := s.OrgSecretDelete(secret); err != nil {
+		t.Errorf("Unexpected error: delete secret: %s", err)
+		return
+	}
+	if _, err := s.OrgSecretFind("octocat", "foo"); err == nil {
+		t.Errorf("Expected error: sql.ErrNoRows")
+	}
+}
+
+func TestOrgSecretIndexes(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from org_secrets")
+		s.Close()
+	}()
+
+	if err := s.OrgSecretCreate(&model.Secret{
+		Owner: "octocat",
+		Name:  "foo",
+		Value: "bar",
+	}); err != nil {
+		t.Errorf("Unexpected error: insert secret: %s", err)
+		return
+	}
+
+	// fail due to duplicate name
+	if err := s.OrgSecretCreate(&model.Secret{
+		Owner: "octocat",
+		Name:  "foo",
+		Value: "baz",
+	}); err == nil {
+		t.Errorf("Unexpected error: dupliate name")
+	}
+}
@@ -60,6 +60,13 @@ func (db *datastore) RepoList(user *model.User) ([]*model.Repo, error) {
 	return data, err
 }
 
+func (db *datastore) RepoListActive() ([]*model.Repo, error) {
+	stmt := sql.Lookup(db.driver, "repo-find-active")
+	data := []*model.Repo{}
+	err := meddler.QueryAll(db, &data, stmt, true)
+	return data, err
+}
+
 func (db *datastore) RepoListLatest(user *model.User) ([]*model.Feed, error) {
 	stmt := sql.Lookup(db.driver, "feed-latest-build")
 	data := []*model.Feed{}
@@ -93,6 +100,8 @@ func (db *datastore) RepoBatch(repos []*model.Repo) error {
 			repo.IsGated,
 			repo.Visibility,
 			repo.Counter,
+			repo.CancelPush,
+			repo.CancelPull,
 		)
 		if err != nil {
 			return err
@@ -0,0 +1,81 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"github.com/drone/drone/model"
+	"github.com/drone/drone/store/datastore/sql"
+	"github.com/russross/meddler"
+)
+
+func (db *datastore) RetentionFind(repoID int64) (*model.Retention, error) {
+	stmt := sql.Lookup(db.driver, "retention-find-repo")
+	data := new(model.Retention)
+	err := meddler.QueryRow(db, data, stmt, repoID)
+	return data, err
+}
+
+func (db *datastore) RetentionList() ([]*model.Retention, error) {
+	stmt := sql.Lookup(db.driver, "retention-list")
+	data := []*model.Retention{}
+	err := meddler.QueryAll(db, &data, stmt)
+	return data, err
+}
+
+func (db *datastore) RetentionCreate(policy *model.Retention) error {
+	return meddler.Insert(db, "retention_policies", policy)
+}
+
+func (db *datastore) RetentionUpdate(policy *model.Retention) error {
+	return meddler.Update(db, "retention_policies", policy)
+}
+
+func (db *datastore) RetentionDelete(policy *model.Retention) error {
+	stmt := sql.Lookup(db.driver, "retention-delete")
+	_, err := db.Exec(stmt, policy.ID)
+	return err
+}
+
+func (db *datastore) RetentionBuildList(repo *model.Repo) ([]*model.Build, error) {
+	stmt := sql.Lookup(db.driver, "retention-find-builds")
+	data := []*model.Build{}
+	err := meddler.QueryAll(db, &data, stmt, repo.ID)
+	return data, err
+}
+
+func (db *datastore) BuildPrune(build *model.Build) error {
+	stmt := sql.Lookup(db.driver, "retention-delete-logs")
+	if _, err := db.Exec(stmt, build.ID); err != nil {
+		return err
+	}
+	if err := db.ProcClear(build); err != nil {
+		return err
+	}
+	stmt = sql.Lookup(db.driver, "retention-update-build-pruned")
+	if _, err := db.Exec(stmt, true, build.ID); err != nil {
+		return err
+	}
+	build.Pruned = true
+	return nil
+}
+
+func (db *datastore) BuildDelete(build *model.Build) error {
+	if err := db.BuildPrune(build); err != nil {
+		return err
+	}
+	stmt := sql.Lookup(db.driver, "retention-delete-build")
+	_, err := db.Exec(stmt, build.ID)
+	return err
+}
@@ -0,0 +1,142 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/drone/drone/model"
+)
+
+func TestRetentionFind(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from retention_policies")
+		s.Close()
+	}()
+
+	s.RetentionCreate(&model.Retention{MaxAge: 3600})
+	policy := &model.Retention{RepoID: 1, KeepBuilds: 10}
+	if err := s.RetentionCreate(policy); err != nil {
+		t.Errorf("Unexpected error: insert policy: %s", err)
+		return
+	}
+	policy.KeepSuccess = true
+	if err := s.RetentionUpdate(policy); err != nil {
+		t.Errorf("Unexpected error: update policy: %s", err)
+		return
+	}
+
+	found, err := s.RetentionFind(1)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := found.KeepBuilds, 10; got != want {
+		t.Errorf("Want keep builds %d, got %d", want, got)
+	}
+	if !found.KeepSuccess {
+		t.Errorf("Want keep success updated")
+	}
+
+	list, err := s.RetentionList()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(list), 2; got != want {
+		t.Errorf("Want %d policies, got %d", want, got)
+		return
+	}
+	if got, want := list[0].MaxAge, int64(3600); got != want {
+		t.Errorf("Want global policy first")
+	}
+
+	if err := s.RetentionDelete(found); err != nil {
+		t.Errorf("Unexpected error: delete policy: %s", err)
+	}
+	if _, err := s.RetentionFind(1); err == nil {
+		t.Errorf("Want policy deleted")
+	}
+}
+
+func TestBuildPrune(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from repos")
+		s.Exec("delete from builds")
+		s.Exec("delete from procs")
+		s.Exec("delete from logs")
+		s.Exec("delete from files")
+		s.Close()
+	}()
+
+	repo := &model.Repo{
+		UserID:   1,
+		FullName: "bradrydzewski/drone",
+		Owner:    "bradrydzewski",
+		Name:     "drone",
+	}
+	s.CreateRepo(repo)
+	build := &model.Build{RepoID: repo.ID, Status: model.StatusSuccess}
+	running := &model.Build{RepoID: repo.ID, Status: model.StatusRunning}
+	s.CreateBuild(build, &model.Proc{PID: 1, State: model.StatusSuccess})
+	s.CreateBuild(running)
+
+	procs, _ := s.ProcList(build)
+	s.LogSave(procs[0], bytes.NewBufferString("echo hi"))
+	s.FileCreate(&model.File{BuildID: build.ID, ProcID: procs[0].ID, Name: "hello.txt", Mime: "text/plain"}, bytes.NewBufferString("hi"))
+
+	builds, err := s.RetentionBuildList(repo)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(builds), 1; got != want {
+		t.Errorf("Want %d finished builds, got %d", want, got)
+		return
+	}
+
+	if err := s.BuildPrune(builds[0]); err != nil {
+		t.Errorf("Unexpected error: prune build: %s", err)
+		return
+	}
+	if _, err := s.LogFind(procs[0]); err == nil {
+		t.Errorf("Want logs pruned")
+	}
+	if files, _ := s.FileList(build); len(files) != 0 {
+		t.Errorf("Want files pruned")
+	}
+	if procs, _ := s.ProcList(build); len(procs) != 0 {
+		t.Errorf("Want procs pruned")
+	}
+	found, err := s.GetBuild(build.ID)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !found.Pruned {
+		t.Errorf("Want build marked as pruned")
+	}
+
+	if err := s.BuildDelete(found); err != nil {
+		t.Errorf("Unexpected error: delete build: %s", err)
+		return
+	}
+	if _, err := s.GetBuild(build.ID); err == nil {
+		t.Errorf("Want build deleted")
+	}
+}
@@ -0,0 +1,51 @@
+-- name: agent-find-addr
+
+SELECT
+ agent_id
+,agent_addr
+,agent_platform
+,agent_capacity
+,agent_labels
+,agent_filter
+,agent_version
+,agent_draining
+,agent_created
+,agent_updated
+FROM agents
+WHERE agent_addr = ?
+
+-- name: agent-list
+
+SELECT
+ agent_id
+,agent_addr
+,agent_platform
+,agent_capacity
+,agent_labels
+,agent_filter
+,agent_version
+,agent_draining
+,agent_created
+,agent_updated
+FROM agents
+ORDER BY agent_addr
+
+-- name: agent-update-seen
+
+UPDATE agents SET agent_updated = ? WHERE agent_id = ?
+
+-- name: agent-update-checkin
+
+UPDATE agents
+SET
+ agent_platform = ?
+,agent_capacity = ?
+,agent_labels = ?
+,agent_filter = ?
+,agent_version = ?
+,agent_updated = ?
+WHERE agent_id = ?
+
+-- name: agent-delete
+
+DELETE FROM agents WHERE agent_id = ?
@@ -0,0 +1,63 @@
+-- name: build-find-filter
+
+SELECT *
+FROM builds
+WHERE build_repo_id = ?
+  AND (? = '' OR build_branch LIKE ? ESCAPE '!')
+  AND (? = '' OR build_event = ?)
+  AND (? = '' OR build_status = ?)
+  AND (? = '' OR build_author = ?)
+  AND (? = '' OR build_commit LIKE ? ESCAPE '!')
+  AND (? = 0 OR build_created >= ?)
+  AND (? = 0 OR build_created < ?)
+  AND (? = 0 OR build_number < ?)
+ORDER BY build_number DESC
+LIMIT ?
+
+-- name: build-search
+
+SELECT
+ repo_owner
+,repo_name
+,repo_full_name
+,build_id
+,build_number
+,build_event
+,build_status
+,build_created
+,build_started
+,build_finished
+,build_commit
+,build_branch
+,build_ref
+,build_refspec
+,build_remote
+,build_title
+,build_message
+,build_author
+,build_email
+,build_avatar
+FROM builds
+INNER JOIN repos ON repo_id = build_repo_id
+WHERE (? = '' OR repo_full_name LIKE ? ESCAPE '!')
+  AND (? = '' OR build_branch LIKE ? ESCAPE '!')
+  AND (? = '' OR build_event = ?)
+  AND (? = '' OR build_status = ?)
+  AND (? = '' OR build_author = ?)
+  AND (? = '' OR build_commit LIKE ? ESCAPE '!')
+  AND (? = 0 OR build_created >= ?)
+  AND (? = 0 OR build_created < ?)
+  AND (? = 0 OR build_id < ?)
+ORDER BY build_id DESC
+LIMIT ?
+
+-- name: build-find-superseded
+
+SELECT *
+FROM builds
+WHERE build_repo_id = ?
+  AND build_event = ?
+  AND build_ref = ?
+  AND build_number < ?
+  AND build_status IN ('pending', 'running')
+ORDER BY build_number ASC
@@ -0,0 +1,59 @@
+-- name: deployment-find-build
+
+SELECT
+ deployment_id
+,deployment_repo_id
+,deployment_build_id
+,deployment_number
+,deployment_source
+,deployment_target
+,deployment_commit
+,deployment_status
+,deployment_rollback
+,deployment_creator
+,deployment_created
+,deployment_updated
+FROM deployments
+WHERE deployment_build_id = ?
+LIMIT 1
+
+-- name: deployment-find-repo
+
+SELECT
+ deployment_id
+,deployment_repo_id
+,deployment_build_id
+,deployment_number
+,deployment_source
+,deployment_target
+,deployment_commit
+,deployment_status
+,deployment_rollback
+,deployment_creator
+,deployment_created
+,deployment_updated
+FROM deployments
+WHERE deployment_repo_id = ?
+ORDER BY deployment_id DESC
+LIMIT 50
+
+-- name: deployment-find-repo-target
+
+SELECT
+ deployment_id
+,deployment_repo_id
+,deployment_build_id
+,deployment_number
+,deployment_source
+,deployment_target
+,deployment_commit
+,deployment_status
+,deployment_rollback
+,deployment_creator
+,deployment_created
+,deployment_updated
+FROM deployments
+WHERE deployment_repo_id = ?
+  AND deployment_target = ?
+ORDER BY deployment_id DESC
+LIMIT 50
@@ -0,0 +1,57 @@
+-- name: retention-find-repo
+
+SELECT
+ policy_id
+,policy_repo_id
+,policy_max_age
+,policy_keep_builds
+,policy_keep_success
+,policy_delete_builds
+,policy_created
+,policy_updated
+FROM retention_policies
+WHERE policy_repo_id = ?
+LIMIT 1
+
+-- name: retention-list
+
+SELECT
+ policy_id
+,policy_repo_id
+,policy_max_age
+,policy_keep_builds
+,policy_keep_success
+,policy_delete_builds
+,policy_created
+,policy_updated
+FROM retention_policies
+ORDER BY policy_repo_id
+
+-- name: retention-delete
+
+DELETE FROM retention_policies WHERE policy_id = ?
+
+-- name: retention-find-builds
+
+SELECT *
+FROM builds
+WHERE build_repo_id = ?
+  AND build_status NOT IN ('pending', 'running', 'blocked')
+ORDER BY build_number DESC
+
+-- name: retention-delete-logs
+
+DELETE FROM logs
+WHERE log_job_id IN (
+  SELECT proc_id
+  FROM procs
+  WHERE proc_build_id = ?
+)
+
+-- name: retention-update-build-pruned
+
+UPDATE builds SET build_pruned = ? WHERE build_id = ?
+
+-- name: retention-delete-build
+
+DELETE FROM builds WHERE build_id = ?
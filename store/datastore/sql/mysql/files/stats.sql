@@ -0,0 +1,31 @@
+-- name: stats-builds
+
+SELECT
+ build_branch
+,build_status
+,build_enqueued
+,build_created
+,build_started
+,build_finished
+FROM builds
+WHERE build_repo_id = ?
+  AND build_created >= ?
+  AND (? = '' OR build_branch = ?)
+  AND build_status IN ('success', 'failure', 'killed', 'error')
+
+-- name: stats-procs
+
+SELECT
+ proc_name
+,proc_state
+,proc_started
+,proc_stopped
+,build_branch
+,build_created
+FROM builds
+INNER JOIN procs ON proc_build_id = build_id
+WHERE build_repo_id = ?
+  AND build_created >= ?
+  AND (? = '' OR build_branch = ?)
+  AND build_status IN ('success', 'failure', 'killed', 'error')
+  AND proc_ppid != 0
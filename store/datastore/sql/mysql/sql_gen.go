@@ -20,53 +20,262 @@ func Lookup(name string) string {
 }
 
 var index = map[string]string{
-	"config-find-id":              configFindId,
-	"config-find-repo-hash":       configFindRepoHash,
-	"config-find-approved":        configFindApproved,
-	"count-users":                 countUsers,
-	"count-repos":                 countRepos,
-	"count-builds":                countBuilds,
-	"feed-latest-build":           feedLatestBuild,
-	"feed":                        feed,
-	"files-find-build":            filesFindBuild,
-	"files-find-proc-name":        filesFindProcName,
-	"files-find-proc-name-data":   filesFindProcNameData,
-	"files-delete-build":          filesDeleteBuild,
-	"logs-find-proc":              logsFindProc,
-	"perms-find-user":             permsFindUser,
-	"perms-find-user-repo":        permsFindUserRepo,
-	"perms-insert-replace":        permsInsertReplace,
-	"perms-insert-replace-lookup": permsInsertReplaceLookup,
-	"perms-delete-user-repo":      permsDeleteUserRepo,
-	"perms-delete-user-date":      permsDeleteUserDate,
-	"procs-find-id":               procsFindId,
-	"procs-find-build":            procsFindBuild,
-	"procs-find-build-pid":        procsFindBuildPid,
-	"procs-find-build-ppid":       procsFindBuildPpid,
-	"procs-delete-build":          procsDeleteBuild,
-	"registry-find-repo":          registryFindRepo,
-	"registry-find-repo-addr":     registryFindRepoAddr,
-	"registry-delete-repo":        registryDeleteRepo,
-	"registry-delete":             registryDelete,
-	"repo-update-counter":         repoUpdateCounter,
-	"repo-find-user":              repoFindUser,
-	"repo-insert-ignore":          repoInsertIgnore,
-	"repo-delete":                 repoDelete,
-	"secret-find-repo":            secretFindRepo,
-	"secret-find-repo-name":       secretFindRepoName,
-	"secret-delete":               secretDelete,
-	"sender-find-repo":            senderFindRepo,
-	"sender-find-repo-login":      senderFindRepoLogin,
-	"sender-delete-repo":          senderDeleteRepo,
-	"sender-delete":               senderDelete,
-	"task-list":                   taskList,
-	"task-delete":                 taskDelete,
-	"user-find":                   userFind,
-	"user-find-login":             userFindLogin,
-	"user-update":                 userUpdate,
-	"user-delete":                 userDelete,
+	"agent-find-addr":                 agentFindAddr,
+	"agent-list":                      agentList,
+	"agent-update-seen":               agentUpdateSeen,
+	"agent-update-checkin":            agentUpdateCheckin,
+	"agent-delete":                    agentDelete,
+	"agent-token-find-hash":           agentTokenFindHash,
+	"agent-token-list":                agentTokenList,
+	"agent-token-delete":              agentTokenDelete,
+	"audit-find":                      auditFind,
+	"build-find-filter":               buildFindFilter,
+	"build-search":                    buildSearch,
+	"build-find-superseded":           buildFindSuperseded,
+	"config-find-id":                  configFindId,
+	"config-find-repo-hash":           configFindRepoHash,
+	"config-find-approved":            configFindApproved,
+	"count-users":                     countUsers,
+	"count-repos":                     countRepos,
+	"count-builds":                    countBuilds,
+	"cron-find-repo":                  cronFindRepo,
+	"cron-find-repo-name":             cronFindRepoName,
+	"cron-find-ready":                 cronFindReady,
+	"cron-delete":                     cronDelete,
+	"cron-claim":                      cronClaim,
+	"deployment-find-build":           deploymentFindBuild,
+	"deployment-find-repo":            deploymentFindRepo,
+	"deployment-find-repo-target":     deploymentFindRepoTarget,
+	"feed-latest-build":               feedLatestBuild,
+	"feed":                            feed,
+	"files-find-build":                filesFindBuild,
+	"files-find-proc-name":            filesFindProcName,
+	"files-find-proc-name-data":       filesFindProcNameData,
+	"files-delete-build":              filesDeleteBuild,
+	"logs-find-proc":                  logsFindProc,
+	"logs-delete-proc":                logsDeleteProc,
+	"logs-find-after":                 logsFindAfter,
+	"org-secret-find-owner":           orgSecretFindOwner,
+	"org-secret-find-owner-name":      orgSecretFindOwnerName,
+	"org-secret-delete":               orgSecretDelete,
+	"perms-find-user":                 permsFindUser,
+	"perms-find-user-repo":            permsFindUserRepo,
+	"perms-insert-replace":            permsInsertReplace,
+	"perms-insert-replace-lookup":     permsInsertReplaceLookup,
+	"perms-delete-user-repo":          permsDeleteUserRepo,
+	"perms-delete-user-date":          permsDeleteUserDate,
+	"procs-find-id":                   procsFindId,
+	"procs-find-build":                procsFindBuild,
+	"procs-find-build-pid":            procsFindBuildPid,
+	"procs-find-build-ppid":           procsFindBuildPpid,
+	"procs-delete-build":              procsDeleteBuild,
+	"registry-find-repo":              registryFindRepo,
+	"registry-find-repo-addr":         registryFindRepoAddr,
+	"registry-delete-repo":            registryDeleteRepo,
+	"registry-delete":                 registryDelete,
+	"repo-update-counter":             repoUpdateCounter,
+	"repo-find-user":                  repoFindUser,
+	"repo-insert-ignore":              repoInsertIgnore,
+	"repo-delete":                     repoDelete,
+	"repo-find-active":                repoFindActive,
+	"retention-find-repo":             retentionFindRepo,
+	"retention-list":                  retentionList,
+	"retention-delete":                retentionDelete,
+	"retention-find-builds":           retentionFindBuilds,
+	"retention-delete-logs":           retentionDeleteLogs,
+	"retention-update-build-pruned":   retentionUpdateBuildPruned,
+	"retention-delete-build":          retentionDeleteBuild,
+	"secret-find-repo":                secretFindRepo,
+	"secret-find-repo-name":           secretFindRepoName,
+	"secret-delete":                   secretDelete,
+	"sender-find-repo":                senderFindRepo,
+	"sender-find-repo-login":          senderFindRepoLogin,
+	"sender-delete-repo":              senderDeleteRepo,
+	"sender-delete":                   senderDelete,
+	"stats-builds":                    statsBuilds,
+	"stats-procs":                     statsProcs,
+	"task-list":                       taskList,
+	"task-find":                       taskFind,
+	"task-find-status":                taskFindStatus,
+	"task-find-ready":                 taskFindReady,
+	"task-claim":                      taskClaim,
+	"task-extend":                     taskExtend,
+	"task-complete":                   taskComplete,
+	"task-evict":                      taskEvict,
+	"task-purge":                      taskPurge,
+	"task-delete":                     taskDelete,
+	"user-find":                       userFind,
+	"user-find-login":                 userFindLogin,
+	"user-update":                     userUpdate,
+	"user-delete":                     userDelete,
+	"webhook-find-repo":               webhookFindRepo,
+	"webhook-delete":                  webhookDelete,
+	"webhook-delivery-find-webhook":   webhookDeliveryFindWebhook,
+	"webhook-delivery-delete-webhook": webhookDeliveryDeleteWebhook,
 }
 
+var agentFindAddr = `
+SELECT
+ agent_id
+,agent_addr
+,agent_platform
+,agent_capacity
+,agent_labels
+,agent_filter
+,agent_version
+,agent_draining
+,agent_created
+,agent_updated
+FROM agents
+WHERE agent_addr = ?
+`
+
+var agentList = `
+SELECT
+ agent_id
+,agent_addr
+,agent_platform
+,agent_capacity
+,agent_labels
+,agent_filter
+,agent_version
+,agent_draining
+,agent_created
+,agent_updated
+FROM agents
+ORDER BY agent_addr
+`
+
+var agentUpdateSeen = `
+UPDATE agents SET agent_updated = ? WHERE agent_id = ?
+`
+
+var agentUpdateCheckin = `
+UPDATE agents
+SET
+ agent_platform = ?
+,agent_capacity = ?
+,agent_labels = ?
+,agent_filter = ?
+,agent_version = ?
+,agent_updated = ?
+WHERE agent_id = ?
+`
+
+var agentDelete = `
+DELETE FROM agents WHERE agent_id = ?
+`
+
+var agentTokenFindHash = `
+SELECT
+ token_id
+,token_name
+,token_hash
+,token_platforms
+,token_labels
+,token_created
+FROM agent_tokens
+WHERE token_hash = ?
+`
+
+var agentTokenList = `
+SELECT
+ token_id
+,token_name
+,token_hash
+,token_platforms
+,token_labels
+,token_created
+FROM agent_tokens
+ORDER BY token_name
+`
+
+var agentTokenDelete = `
+DELETE FROM agent_tokens WHERE token_id = ?
+`
+
+var auditFind = `
+SELECT
+ audit_id
+,audit_actor
+,audit_action
+,audit_repo
+,audit_target
+,audit_ip
+,audit_diff
+,audit_created
+FROM audit_events
+WHERE (? = '' OR audit_actor = ?)
+  AND (? = '' OR audit_repo = ?)
+  AND (? = '' OR audit_action = ?)
+ORDER BY audit_id DESC
+LIMIT ? OFFSET ?
+`
+
+var buildFindFilter = `
+SELECT *
+FROM builds
+WHERE build_repo_id = ?
+  AND (? = '' OR build_branch LIKE ? ESCAPE '!')
+  AND (? = '' OR build_event = ?)
+  AND (? = '' OR build_status = ?)
+  AND (? = '' OR build_author = ?)
+  AND (? = '' OR build_commit LIKE ? ESCAPE '!')
+  AND (? = 0 OR build_created >= ?)
+  AND (? = 0 OR build_created < ?)
+  AND (? = 0 OR build_number < ?)
+ORDER BY build_number DESC
+LIMIT ?
+`
+
+var buildSearch = `
+SELECT
+ repo_owner
+,repo_name
+,repo_full_name
+,build_id
+,build_number
+,build_event
+,build_status
+,build_created
+,build_started
+,build_finished
+,build_commit
+,build_branch
+,build_ref
+,build_refspec
+,build_remote
+,build_title
+,build_message
+,build_author
+,build_email
+,build_avatar
+FROM builds
+INNER JOIN repos ON repo_id = build_repo_id
+WHERE (? = '' OR repo_full_name LIKE ? ESCAPE '!')
+  AND (? = '' OR build_branch LIKE ? ESCAPE '!')
+  AND (? = '' OR build_event = ?)
+  AND (? = '' OR build_status = ?)
+  AND (? = '' OR build_author = ?)
+  AND (? = '' OR build_commit LIKE ? ESCAPE '!')
+  AND (? = 0 OR build_created >= ?)
+  AND (? = 0 OR build_created < ?)
+  AND (? = 0 OR build_id < ?)
+ORDER BY build_id DESC
+LIMIT ?
+`
+
+var buildFindSuperseded = `
+SELECT *
+FROM builds
+WHERE build_repo_id = ?
+  AND build_event = ?
+  AND build_ref = ?
+  AND build_number < ?
+  AND build_status IN ('pending', 'running')
+ORDER BY build_number ASC
+`
+
 var configFindId = `
 SELECT
  config_id
@@ -112,6 +321,131 @@ SELECT count(1)
 FROM builds
 `
 
+var cronFindRepo = `
+SELECT
+ cron_id
+,cron_repo_id
+,cron_name
+,cron_expr
+,cron_branch
+,cron_next
+,cron_prev
+,cron_disabled
+,cron_created
+,cron_updated
+FROM crons
+WHERE cron_repo_id = ?
+ORDER BY cron_name
+`
+
+var cronFindRepoName = `
+SELECT
+ cron_id
+,cron_repo_id
+,cron_name
+,cron_expr
+,cron_branch
+,cron_next
+,cron_prev
+,cron_disabled
+,cron_created
+,cron_updated
+FROM crons
+WHERE cron_repo_id = ?
+  AND cron_name = ?
+`
+
+var cronFindReady = `
+SELECT
+ cron_id
+,cron_repo_id
+,cron_name
+,cron_expr
+,cron_branch
+,cron_next
+,cron_prev
+,cron_disabled
+,cron_created
+,cron_updated
+FROM crons
+WHERE cron_next <= ?
+  AND cron_disabled = ?
+ORDER BY cron_next
+`
+
+var cronDelete = `
+DELETE FROM crons WHERE cron_id = ?
+`
+
+var cronClaim = `
+UPDATE crons
+SET
+ cron_next = ?
+,cron_prev = ?
+WHERE cron_id = ?
+  AND cron_next = ?
+`
+
+var deploymentFindBuild = `
+SELECT
+ deployment_id
+,deployment_repo_id
+,deployment_build_id
+,deployment_number
+,deployment_source
+,deployment_target
+,deployment_commit
+,deployment_status
+,deployment_rollback
+,deployment_creator
+,deployment_created
+,deployment_updated
+FROM deployments
+WHERE deployment_build_id = ?
+LIMIT 1
+`
+
+var deploymentFindRepo = `
+SELECT
+ deployment_id
+,deployment_repo_id
+,deployment_build_id
+,deployment_number
+,deployment_source
+,deployment_target
+,deployment_commit
+,deployment_status
+,deployment_rollback
+,deployment_creator
+,deployment_created
+,deployment_updated
+FROM deployments
+WHERE deployment_repo_id = ?
+ORDER BY deployment_id DESC
+LIMIT 50
+`
+
+var deploymentFindRepoTarget = `
+SELECT
+ deployment_id
+,deployment_repo_id
+,deployment_build_id
+,deployment_number
+,deployment_source
+,deployment_target
+,deployment_commit
+,deployment_status
+,deployment_rollback
+,deployment_creator
+,deployment_created
+,deployment_updated
+FROM deployments
+WHERE deployment_repo_id = ?
+  AND deployment_target = ?
+ORDER BY deployment_id DESC
+LIMIT 50
+`
+
 var feedLatestBuild = `
 SELECT
  repo_owner
@@ -242,6 +576,52 @@ WHERE log_job_id = ?
 LIMIT 1
 `
 
+var logsDeleteProc = `
+DELETE FROM logs WHERE log_job_id = ?
+`
+
+var logsFindAfter = `
+SELECT log_job_id
+FROM logs
+WHERE log_job_id > ?
+ORDER BY log_job_id
+LIMIT ?
+`
+
+var orgSecretFindOwner = `
+SELECT
+ secret_id
+,secret_owner
+,secret_name
+,secret_value
+,secret_images
+,secret_events
+,secret_conceal
+,secret_skip_verify
+FROM org_secrets
+WHERE secret_owner = ?
+ORDER BY secret_name
+`
+
+var orgSecretFindOwnerName = `
+SELECT
+ secret_id
+,secret_owner
+,secret_name
+,secret_value
+,secret_images
+,secret_events
+,secret_conceal
+,secret_skip_verify
+FROM org_secrets
+WHERE secret_owner = ?
+  AND secret_name = ?
+`
+
+var orgSecretDelete = `
+DELETE FROM org_secrets WHERE secret_id = ?
+`
+
 var permsFindUser = `
 SELECT
  perm_user_id
@@ -455,6 +835,8 @@ SELECT
 ,repo_gated
 ,repo_visibility
 ,repo_counter
+,repo_cancel_push
+,repo_cancel_pull
 FROM repos
 INNER JOIN perms ON perms.perm_repo_id = repos.repo_id
 WHERE perms.perm_user_id = ?
@@ -485,13 +867,80 @@ INSERT IGNORE INTO repos (
 ,repo_gated
 ,repo_visibility
 ,repo_counter
-) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)
+,repo_cancel_push
+,repo_cancel_pull
+) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)
 `
 
 var repoDelete = `
 DELETE FROM repos WHERE repo_id = ?
 `
 
+var repoFindActive = `
+SELECT *
+FROM repos
+WHERE repo_active = ?
+ORDER BY repo_full_name
+`
+
+var retentionFindRepo = `
+SELECT
+ policy_id
+,policy_repo_id
+,policy_max_age
+,policy_keep_builds
+,policy_keep_success
+,policy_delete_builds
+,policy_created
+,policy_updated
+FROM retention_policies
+WHERE policy_repo_id = ?
+LIMIT 1
+`
+
+var retentionList = `
+SELECT
+ policy_id
+,policy_repo_id
+,policy_max_age
+,policy_keep_builds
+,policy_keep_success
+,policy_delete_builds
+,policy_created
+,policy_updated
+FROM retention_policies
+ORDER BY policy_repo_id
+`
+
+var retentionDelete = `
+DELETE FROM retention_policies WHERE policy_id = ?
+`
+
+var retentionFindBuilds = `
+SELECT *
+FROM builds
+WHERE build_repo_id = ?
+  AND build_status NOT IN ('pending', 'running', 'blocked')
+ORDER BY build_number DESC
+`
+
+var retentionDeleteLogs = `
+DELETE FROM logs
+WHERE log_job_id IN (
+  SELECT proc_id
+  FROM procs
+  WHERE proc_build_id = ?
+)
+`
+
+var retentionUpdateBuildPruned = `
+UPDATE builds SET build_pruned = ? WHERE build_id = ?
+`
+
+var retentionDeleteBuild = `
+DELETE FROM builds WHERE build_id = ?
+`
+
 var secretFindRepo = `
 SELECT
  secret_id
@@ -556,12 +1005,132 @@ var senderDelete = `
 DELETE FROM senders WHERE sender_id = ?
 `
 
+var statsBuilds = `
+SELECT
+ build_branch
+,build_status
+,build_enqueued
+,build_created
+,build_started
+,build_finished
+FROM builds
+WHERE build_repo_id = ?
+  AND build_created >= ?
+  AND (? = '' OR build_branch = ?)
+  AND build_status IN ('success', 'failure', 'killed', 'error')
+`
+
+var statsProcs = `
+SELECT
+ proc_name
+,proc_state
+,proc_started
+,proc_stopped
+,build_branch
+,build_created
+FROM builds
+INNER JOIN procs ON proc_build_id = build_id
+WHERE build_repo_id = ?
+  AND build_created >= ?
+  AND (? = '' OR build_branch = ?)
+  AND build_status IN ('success', 'failure', 'killed', 'error')
+  AND proc_ppid != 0
+`
+
 var taskList = `
 SELECT
  task_id
 ,task_data
 ,task_labels
+,task_status
+,task_error
+,task_deadline
+,task_created
+,task_updated
+FROM tasks
+`
+
+var taskFind = `
+SELECT
+ task_id
+,task_data
+,task_labels
+,task_status
+,task_error
+,task_deadline
+,task_created
+,task_updated
+FROM tasks
+WHERE task_id = ?
+`
+
+var taskFindStatus = `
+SELECT
+ task_id
+,task_data
+,task_labels
+,task_status
+,task_error
+,task_deadline
+,task_created
+,task_updated
+FROM tasks
+WHERE task_status = ?
+ORDER BY task_created ASC, task_id ASC
+`
+
+var taskFindReady = `
+SELECT
+ task_id
+,task_data
+,task_labels
+,task_status
+,task_error
+,task_deadline
+,task_created
+,task_updated
 FROM tasks
+WHERE task_status = ?
+   OR (task_status = ? AND task_deadline < ?)
+ORDER BY task_created ASC, task_id ASC
+`
+
+var taskClaim = `
+UPDATE tasks
+SET
+ task_status   = ?
+,task_deadline = ?
+,task_updated  = ?
+WHERE task_id = ?
+  AND task_status = ?
+  AND task_deadline = ?
+`
+
+var taskExtend = `
+UPDATE tasks
+SET
+ task_deadline = ?
+,task_updated  = ?
+WHERE task_id = ?
+  AND task_status = ?
+`
+
+var taskComplete = `
+UPDATE tasks
+SET
+ task_status  = ?
+,task_error   = ?
+,task_updated = ?
+WHERE task_id = ?
+  AND task_status = ?
+`
+
+var taskEvict = `
+DELETE FROM tasks WHERE task_id = ? AND task_status = ?
+`
+
+var taskPurge = `
+DELETE FROM tasks WHERE task_status = ? AND task_updated < ?
 `
 
 var taskDelete = `
@@ -621,3 +1190,45 @@ WHERE user_id = ?
 var userDelete = `
 DELETE FROM users WHERE user_id = ?
 `
+
+var webhookFindRepo = `
+SELECT
+ webhook_id
+,webhook_repo_id
+,webhook_url
+,webhook_secret
+,webhook_events
+,webhook_skip_verify
+,webhook_disabled
+,webhook_created
+,webhook_updated
+FROM webhooks
+WHERE webhook_repo_id = ?
+ORDER BY webhook_id
+`
+
+var webhookDelete = `
+DELETE FROM webhooks WHERE webhook_id = ?
+`
+
+var webhookDeliveryFindWebhook = `
+SELECT
+ delivery_id
+,delivery_webhook_id
+,delivery_event
+,delivery_payload
+,delivery_status
+,delivery_error
+,delivery_attempts
+,delivery_redelivery
+,delivery_created
+,delivery_updated
+FROM webhook_deliveries
+WHERE delivery_webhook_id = ?
+ORDER BY delivery_id DESC
+LIMIT 50
+`
+
+var webhookDeliveryDeleteWebhook = `
+DELETE FROM webhook_deliveries WHERE delivery_webhook_id = ?
+`
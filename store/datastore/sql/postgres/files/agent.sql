@@ -0,0 +1,51 @@
+-- name: agent-find-addr
+
+SELECT
+ agent_id
+,agent_addr
+,agent_platform
+,agent_capacity
+,agent_labels
+,agent_filter
+,agent_version
+,agent_draining
+,agent_created
+,agent_updated
+FROM agents
+WHERE agent_addr = $1
+
+-- name: agent-list
+
+SELECT
+ agent_id
+,agent_addr
+,agent_platform
+,agent_capacity
+,agent_labels
+,agent_filter
+,agent_version
+,agent_draining
+,agent_created
+,agent_updated
+FROM agents
+ORDER BY agent_addr
+
+-- name: agent-update-seen
+
+UPDATE agents SET agent_updated = $1 WHERE agent_id = $2
+
+-- name: agent-update-checkin
+
+UPDATE agents
+SET
+ agent_platform = $1
+,agent_capacity = $2
+,agent_labels = $3
+,agent_filter = $4
+,agent_version = $5
+,agent_updated = $6
+WHERE agent_id = $7
+
+-- name: agent-delete
+
+DELETE FROM agents WHERE agent_id = $1
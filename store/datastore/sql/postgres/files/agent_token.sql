@@ -0,0 +1,27 @@
+-- name: agent-token-find-hash
+
+SELECT
+ token_id
+,token_name
+,token_hash
+,token_platforms
+,token_labels
+,token_created
+FROM agent_tokens
+WHERE token_hash = $1
+
+-- name: agent-token-list
+
+SELECT
+ token_id
+,token_name
+,token_hash
+,token_platforms
+,token_labels
+,token_created
+FROM agent_tokens
+ORDER BY token_name
+
+-- name: agent-token-delete
+
+DELETE FROM agent_tokens WHERE token_id = $1
@@ -0,0 +1,17 @@
+-- name: audit-find
+
+SELECT
+ audit_id
+,audit_actor
+,audit_action
+,audit_repo
+,audit_target
+,audit_ip
+,audit_diff
+,audit_created
+FROM audit_events
+WHERE ($1 = '' OR audit_actor = $2)
+  AND ($3 = '' OR audit_repo = $4)
+  AND ($5 = '' OR audit_action = $6)
+ORDER BY audit_id DESC
+LIMIT $7 OFFSET $8
@@ -0,0 +1,63 @@
+-- name: build-find-filter
+
+SELECT *
+FROM builds
+WHERE build_repo_id = $1
+  AND ($2 = '' OR build_branch LIKE $3 ESCAPE '!')
+  AND ($4 = '' OR build_event = $5)
+  AND ($6 = '' OR build_status = $7)
+  AND ($8 = '' OR build_author = $9)
+  AND ($10 = '' OR build_commit LIKE $11 ESCAPE '!')
+  AND ($12 = 0 OR build_created >= $13)
+  AND ($14 = 0 OR build_created < $15)
+  AND ($16 = 0 OR build_number < $17)
+ORDER BY build_number DESC
+LIMIT $18
+
+-- name: build-search
+
+SELECT
+ repo_owner
+,repo_name
+,repo_full_name
+,build_id
+,build_number
+,build_event
+,build_status
+,build_created
+,build_started
+,build_finished
+,build_commit
+,build_branch
+,build_ref
+,build_refspec
+,build_remote
+,build_title
+,build_message
+,build_author
+,build_email
+,build_avatar
+FROM builds
+INNER JOIN repos ON repo_id = build_repo_id
+WHERE ($1 = '' OR repo_full_name LIKE $2 ESCAPE '!')
+  AND ($3 = '' OR build_branch LIKE $4 ESCAPE '!')
+  AND ($5 = '' OR build_event = $6)
+  AND ($7 = '' OR build_status = $8)
+  AND ($9 = '' OR build_author = $10)
+  AND ($11 = '' OR build_commit LIKE $12 ESCAPE '!')
+  AND ($13 = 0 OR build_created >= $14)
+  AND ($15 = 0 OR build_created < $16)
+  AND ($17 = 0 OR build_id < $18)
+ORDER BY build_id DESC
+LIMIT $19
+
+-- name: build-find-superseded
+
+SELECT *
+FROM builds
+WHERE build_repo_id = $1
+  AND build_event = $2
+  AND build_ref = $3
+  AND build_number < $4
+  AND build_status IN ('pending', 'running')
+ORDER BY build_number ASC
@@ -0,0 +1,64 @@
+-- name: cron-find-repo
+
+SELECT
+ cron_id
+,cron_repo_id
+,cron_name
+,cron_expr
+,cron_branch
+,cron_next
+,cron_prev
+,cron_disabled
+,cron_created
+,cron_updated
+FROM crons
+WHERE cron_repo_id = $1
+ORDER BY cron_name
+
+-- name: cron-find-repo-name
+
+SELECT
+ cron_id
+,cron_repo_id
+,cron_name
+,cron_expr
+,cron_branch
+,cron_next
+,cron_prev
+,cron_disabled
+,cron_created
+,cron_updated
+FROM crons
+WHERE cron_repo_id = $1
+  AND cron_name = $2
+
+-- name: cron-find-ready
+
+SELECT
+ cron_id
+,cron_repo_id
+,cron_name
+,cron_expr
+,cron_branch
+,cron_next
+,cron_prev
+,cron_disabled
+,cron_created
+,cron_updated
+FROM crons
+WHERE cron_next <= $1
+  AND cron_disabled = $2
+ORDER BY cron_next
+
+-- name: cron-delete
+
+DELETE FROM crons WHERE cron_id = $1
+
+-- name: cron-claim
+
+UPDATE crons
+SET
+ cron_next = $1
+,cron_prev = $2
+WHERE cron_id = $3
+  AND cron_next = $4
@@ -7,3 +7,15 @@ SELECT
 FROM logs
 WHERE log_job_id = $1
 LIMIT 1
+
+-- name: logs-delete-proc
+
+DELETE FROM logs WHERE log_job_id = $1
+
+-- name: logs-find-after
+
+SELECT log_job_id
+FROM logs
+WHERE log_job_id > $1
+ORDER BY log_job_id
+LIMIT $2
@@ -30,6 +30,8 @@ SELECT
 ,repo_gated
 ,repo_visibility
 ,repo_counter
+,repo_cancel_push
+,repo_cancel_pull
 FROM repos
 INNER JOIN perms ON perms.perm_repo_id = repos.repo_id
 WHERE perms.perm_user_id = $1
@@ -60,10 +62,19 @@ INSERT INTO repos (
 ,repo_gated
 ,repo_visibility
 ,repo_counter
-) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22)
+,repo_cancel_push
+,repo_cancel_pull
+) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24)
 ON CONFLICT (repo_full_name) DO NOTHING
 
 -- name: repo-delete
 
 DELETE FROM repos
 WHERE repo_id = $1
+
+-- name: repo-find-active
+
+SELECT *
+FROM repos
+WHERE repo_active = $1
+ORDER BY repo_full_name
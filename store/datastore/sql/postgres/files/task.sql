@@ -0,0 +1,99 @@
+-- name: task-list
+
+SELECT
+ task_id
+,task_data
+,task_labels
+,task_status
+,task_error
+,task_deadline
+,task_created
+,task_updated
+FROM tasks
+
+-- name: task-find
+
+SELECT
+ task_id
+,task_data
+,task_labels
+,task_status
+,task_error
+,task_deadline
+,task_created
+,task_updated
+FROM tasks
+WHERE task_id = $1
+
+-- name: task-find-status
+
+SELECT
+ task_id
+,task_data
+,task_labels
+,task_status
+,task_error
+,task_deadline
+,task_created
+,task_updated
+FROM tasks
+WHERE task_status = $1
+ORDER BY task_created ASC, task_id ASC
+
+-- name: task-find-ready
+
+SELECT
+ task_id
+,task_data
+,task_labels
+,task_status
+,task_error
+,task_deadline
+,task_created
+,task_updated
+FROM tasks
+WHERE task_status = $1
+   OR (task_status = $2 AND task_deadline < $3)
+ORDER BY task_created ASC, task_id ASC
+
+-- name: task-claim
+
+UPDATE tasks
+SET
+ task_status   = $1
+,task_deadline = $2
+,task_updated  = $3
+WHERE task_id = $4
+  AND task_status = $5
+  AND task_deadline = $6
+
+-- name: task-extend
+
+UPDATE tasks
+SET
+ task_deadline = $1
+,task_updated  = $2
+WHERE task_id = $3
+  AND task_status = $4
+
+-- name: task-complete
+
+UPDATE tasks
+SET
+ task_status  = $1
+,task_error   = $2
+,task_updated = $3
+WHERE task_id = $4
+  AND task_status = $5
+
+-- name: task-evict
+
+DELETE FROM tasks WHERE task_id = $1 AND task_status = $2
+
+-- name: task-purge
+
+DELETE FROM tasks WHERE task_status = $1 AND task_updated < $2
+
+-- name: task-delete
+
+DELETE FROM tasks WHERE task_id = $1
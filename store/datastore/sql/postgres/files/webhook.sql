@@ -0,0 +1,41 @@
+-- name: webhook-find-repo
+
+SELECT
+ webhook_id
+,webhook_repo_id
+,webhook_url
+,webhook_secret
+,webhook_events
+,webhook_skip_verify
+,webhook_disabled
+,webhook_created
+,webhook_updated
+FROM webhooks
+WHERE webhook_repo_id = $1
+ORDER BY webhook_id
+
+-- name: webhook-delete
+
+DELETE FROM webhooks WHERE webhook_id = $1
+
+-- name: webhook-delivery-find-webhook
+
+SELECT
+ delivery_id
+,delivery_webhook_id
+,delivery_event
+,delivery_payload
+,delivery_status
+,delivery_error
+,delivery_attempts
+,delivery_redelivery
+,delivery_created
+,delivery_updated
+FROM webhook_deliveries
+WHERE delivery_webhook_id = $1
+ORDER BY delivery_id DESC
+LIMIT 50
+
+-- name: webhook-delivery-delete-webhook
+
+DELETE FROM webhook_deliveries WHERE delivery_webhook_id = $1
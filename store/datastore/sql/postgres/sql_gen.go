@@ -6,53 +6,262 @@ func Lookup(name string) string {
 }
 
 var index = map[string]string{
-	"config-find-id":              configFindId,
-	"config-find-repo-hash":       configFindRepoHash,
-	"config-find-approved":        configFindApproved,
-	"count-users":                 countUsers,
-	"count-repos":                 countRepos,
-	"count-builds":                countBuilds,
-	"feed-latest-build":           feedLatestBuild,
-	"feed":                        feed,
-	"files-find-build":            filesFindBuild,
-	"files-find-proc-name":        filesFindProcName,
-	"files-find-proc-name-data":   filesFindProcNameData,
-	"files-delete-build":          filesDeleteBuild,
-	"logs-find-proc":              logsFindProc,
-	"perms-find-user":             permsFindUser,
-	"perms-find-user-repo":        permsFindUserRepo,
-	"perms-insert-replace":        permsInsertReplace,
-	"perms-insert-replace-lookup": permsInsertReplaceLookup,
-	"perms-delete-user-repo":      permsDeleteUserRepo,
-	"perms-delete-user-date":      permsDeleteUserDate,
-	"procs-find-id":               procsFindId,
-	"procs-find-build":            procsFindBuild,
-	"procs-find-build-pid":        procsFindBuildPid,
-	"procs-find-build-ppid":       procsFindBuildPpid,
-	"procs-delete-build":          procsDeleteBuild,
-	"registry-find-repo":          registryFindRepo,
-	"registry-find-repo-addr":     registryFindRepoAddr,
-	"registry-delete-repo":        registryDeleteRepo,
-	"registry-delete":             registryDelete,
-	"repo-update-counter":         repoUpdateCounter,
-	"repo-find-user":              repoFindUser,
-	"repo-insert-ignore":          repoInsertIgnore,
-	"repo-delete":                 repoDelete,
-	"secret-find-repo":            secretFindRepo,
-	"secret-find-repo-name":       secretFindRepoName,
-	"secret-delete":               secretDelete,
-	"sender-find-repo":            senderFindRepo,
-	"sender-find-repo-login":      senderFindRepoLogin,
-	"sender-delete-repo":          senderDeleteRepo,
-	"sender-delete":               senderDelete,
-	"task-list":                   taskList,
-	"task-delete":                 taskDelete,
-	"user-find":                   userFind,
-	"user-find-login":             userFindLogin,
-	"user-update":                 userUpdate,
-	"user-delete":                 userDelete,
+	"agent-find-addr":                 agentFindAddr,
+	"agent-list":                      agentList,
+	"agent-update-seen":               agentUpdateSeen,
+	"agent-update-checkin":            agentUpdateCheckin,
+	"agent-delete":                    agentDelete,
+	"agent-token-find-hash":           agentTokenFindHash,
+	"agent-token-list":                agentTokenList,
+	"agent-token-delete":              agentTokenDelete,
+	"audit-find":                      auditFind,
+	"build-find-filter":               buildFindFilter,
+	"build-search":                    buildSearch,
+	"build-find-superseded":           buildFindSuperseded,
+	"config-find-id":                  configFindId,
+	"config-find-repo-hash":           configFindRepoHash,
+	"config-find-approved":            configFindApproved,
+	"count-users":                     countUsers,
+	"count-repos":                     countRepos,
+	"count-builds":                    countBuilds,
+	"cron-find-repo":                  cronFindRepo,
+	"cron-find-repo-name":             cronFindRepoName,
+	"cron-find-ready":                 cronFindReady,
+	"cron-delete":                     cronDelete,
+	"cron-claim":                      cronClaim,
+	"deployment-find-build":           deploymentFindBuild,
+	"deployment-find-repo":            deploymentFindRepo,
+	"deployment-find-repo-target":     deploymentFindRepoTarget,
+	"feed-latest-build":               feedLatestBuild,
+	"feed":                            feed,
+	"files-find-build":                filesFindBuild,
+	"files-find-proc-name":            filesFindProcName,
+	"files-find-proc-name-data":       filesFindProcNameData,
+	"files-delete-build":              filesDeleteBuild,
+	"logs-find-proc":                  logsFindProc,
+	"logs-delete-proc":                logsDeleteProc,
+	"logs-find-after":                 logsFindAfter,
+	"org-secret-find-owner":           orgSecretFindOwner,
+	"org-secret-find-owner-name":      orgSecretFindOwnerName,
+	"org-secret-delete":               orgSecretDelete,
+	"perms-find-user":                 permsFindUser,
+	"perms-find-user-repo":            permsFindUserRepo,
+	"perms-insert-replace":            permsInsertReplace,
+	"perms-insert-replace-lookup":     permsInsertReplaceLookup,
+	"perms-delete-user-repo":          permsDeleteUserRepo,
+	"perms-delete-user-date":          permsDeleteUserDate,
+	"procs-find-id":                   procsFindId,
+	"procs-find-build":                procsFindBuild,
+	"procs-find-build-pid":            procsFindBuildPid,
+	"procs-find-build-ppid":           procsFindBuildPpid,
+	"procs-delete-build":              procsDeleteBuild,
+	"registry-find-repo":              registryFindRepo,
+	"registry-find-repo-addr":         registryFindRepoAddr,
+	"registry-delete-repo":            registryDeleteRepo,
+	"registry-delete":                 registryDelete,
+	"repo-update-counter":             repoUpdateCounter,
+	"repo-find-user":                  repoFindUser,
+	"repo-insert-ignore":              repoInsertIgnore,
+	"repo-delete":                     repoDelete,
+	"repo-find-active":                repoFindActive,
+	"retention-find-repo":             retentionFindRepo,
+	"retention-list":                  retentionList,
+	"retention-delete":                retentionDelete,
+	"retention-find-builds":           retentionFindBuilds,
+	"retention-delete-logs":           retentionDeleteLogs,
+	"retention-update-build-pruned":   retentionUpdateBuildPruned,
+	"retention-delete-build":          retentionDeleteBuild,
+	"secret-find-repo":                secretFindRepo,
+	"secret-find-repo-name":           secretFindRepoName,
+	"secret-delete":                   secretDelete,
+	"sender-find-repo":                senderFindRepo,
+	"sender-find-repo-login":          senderFindRepoLogin,
+	"sender-delete-repo":              senderDeleteRepo,
+	"sender-delete":                   senderDelete,
+	"stats-builds":                    statsBuilds,
+	"stats-procs":                     statsProcs,
+	"task-list":                       taskList,
+	"task-find":                       taskFind,
+	"task-find-status":                taskFindStatus,
+	"task-find-ready":                 taskFindReady,
+	"task-claim":                      taskClaim,
+	"task-extend":                     taskExtend,
+	"task-complete":                   taskComplete,
+	"task-evict":                      taskEvict,
+	"task-purge":                      taskPurge,
+	"task-delete":                     taskDelete,
+	"user-find":                       userFind,
+	"user-find-login":                 userFindLogin,
+	"user-update":                     userUpdate,
+	"user-delete":                     userDelete,
+	"webhook-find-repo":               webhookFindRepo,
+	"webhook-delete":                  webhookDelete,
+	"webhook-delivery-find-webhook":   webhookDeliveryFindWebhook,
+	"webhook-delivery-delete-webhook": webhookDeliveryDeleteWebhook,
 }
 
+var agentFindAddr = `
+SELECT
+ agent_id
+,agent_addr
+,agent_platform
+,agent_capacity
+,agent_labels
+,agent_filter
+,agent_version
+,agent_draining
+,agent_created
+,agent_updated
+FROM agents
+WHERE agent_addr = $1
+`
+
+var agentList = `
+SELECT
+ agent_id
+,agent_addr
+,agent_platform
+,agent_capacity
+,agent_labels
+,agent_filter
+,agent_version
+,agent_draining
+,agent_created
+,agent_updated
+FROM agents
+ORDER BY agent_addr
+`
+
+var agentUpdateSeen = `
+UPDATE agents SET agent_updated = $1 WHERE agent_id = $2
+`
+
+var agentUpdateCheckin = `
+UPDATE agents
+SET
+ agent_platform = $1
+,agent_capacity = $2
+,agent_labels = $3
+,agent_filter = $4
+,agent_version = $5
+,agent_updated = $6
+WHERE agent_id = $7
+`
+
+var agentDelete = `
+DELETE FROM agents WHERE agent_id = $1
+`
+
+var agentTokenFindHash = `
+SELECT
+ token_id
+,token_name
+,token_hash
+,token_platforms
+,token_labels
+,token_created
+FROM agent_tokens
+WHERE token_hash = $1
+`
+
+var agentTokenList = `
+SELECT
+ token_id
+,token_name
+,token_hash
+,token_platforms
+,token_labels
+,token_created
+FROM agent_tokens
+ORDER BY token_name
+`
+
+var agentTokenDelete = `
+DELETE FROM agent_tokens WHERE token_id = $1
+`
+
+var auditFind = `
+SELECT
+ audit_id
+,audit_actor
+,audit_action
+,audit_repo
+,audit_target
+,audit_ip
+,audit_diff
+,audit_created
+FROM audit_events
+WHERE ($1 = '' OR audit_actor = $2)
+  AND ($3 = '' OR audit_repo = $4)
+  AND ($5 = '' OR audit_action = $6)
+ORDER BY audit_id DESC
+LIMIT $7 OFFSET $8
+`
+
+var buildFindFilter = `
+SELECT *
+FROM builds
+WHERE build_repo_id = $1
+  AND ($2 = '' OR build_branch LIKE $3 ESCAPE '!')
+  AND ($4 = '' OR build_event = $5)
+  AND ($6 = '' OR build_status = $7)
+  AND ($8 = '' OR build_author = $9)
+  AND ($10 = '' OR build_commit LIKE $11 ESCAPE '!')
+  AND ($12 = 0 OR build_created >= $13)
+  AND ($14 = 0 OR build_created < $15)
+  AND ($16 = 0 OR build_number < $17)
+ORDER BY build_number DESC
+LIMIT $18
+`
+
+var buildSearch = `
+SELECT
+ repo_owner
+,repo_name
+,repo_full_name
+,build_id
+,build_number
+,build_event
+,build_status
+,build_created
+,build_started
+,build_finished
+,build_commit
+,build_branch
+,build_ref
+,build_refspec
+,build_remote
+,build_title
+,build_message
+,build_author
+,build_email
+,build_avatar
+FROM builds
+INNER JOIN repos ON repo_id = build_repo_id
+WHERE ($1 = '' OR repo_full_name LIKE $2 ESCAPE '!')
+  AND ($3 = '' OR build_branch LIKE $4 ESCAPE '!')
+  AND ($5 = '' OR build_event = $6)
+  AND ($7 = '' OR build_status = $8)
+  AND ($9 = '' OR build_author = $10)
+  AND ($11 = '' OR build_commit LIKE $12 ESCAPE '!')
+  AND ($13 = 0 OR build_created >= $14)
+  AND ($15 = 0 OR build_created < $16)
+  AND ($17 = 0 OR build_id < $18)
+ORDER BY build_id DESC
+LIMIT $19
+`
+
+var buildFindSuperseded = `
+SELECT *
+FROM builds
+WHERE build_repo_id = $1
+  AND build_event = $2
+  AND build_ref = $3
+  AND build_number < $4
+  AND build_status IN ('pending', 'running')
+ORDER BY build_number ASC
+`
+
 var configFindId = `
 SELECT
  config_id
@@ -97,6 +306,131 @@ var countBuilds = `
 SELECT currval('builds_build_id_seq');
 `
 
+var cronFindRepo = `
+SELECT
+ cron_id
+,cron_repo_id
+,cron_name
+,cron_expr
+,cron_branch
+,cron_next
+,cron_prev
+,cron_disabled
+,cron_created
+,cron_updated
+FROM crons
+WHERE cron_repo_id = $1
+ORDER BY cron_name
+`
+
+var cronFindRepoName = `
+SELECT
+ cron_id
+,cron_repo_id
+,cron_name
+,cron_expr
+,cron_branch
+,cron_next
+,cron_prev
+,cron_disabled
+,cron_created
+,cron_updated
+FROM crons
+WHERE cron_repo_id = $1
+  AND cron_name = $2
+`
+
+var cronFindReady = `
+SELECT
+ cron_id
+,cron_repo_id
+,cron_name
+,cron_expr
+,cron_branch
+,cron_next
+,cron_prev
+,cron_disabled
+,cron_created
+,cron_updated
+FROM crons
+WHERE cron_next <= $1
+  AND cron_disabled = $2
+ORDER BY cron_next
+`
+
+var cronDelete = `
+DELETE FROM crons WHERE cron_id = $1
+`
+
+var cronClaim = `
+UPDATE crons
+SET
+ cron_next = $1
+,cron_prev = $2
+WHERE cron_id = $3
+  AND cron_next = $4
+`
+
+var deploymentFindBuild = `
+SELECT
+ deployment_id
+,deployment_repo_id
+,deployment_build_id
+,deployment_number
+,deployment_source
+,deployment_target
+,deployment_commit
+,deployment_status
+,deployment_rollback
+,deployment_creator
+,deployment_created
+,deployment_updated
+FROM deployments
+WHERE deployment_build_id = $1
+LIMIT 1
+`
+
+var deploymentFindRepo = `
+SELECT
+ deployment_id
+,deployment_repo_id
+,deployment_build_id
+,deployment_number
+,deployment_source
+,deployment_target
+,deployment_commit
+,deployment_status
+,deployment_rollback
+,deployment_creator
+,deployment_created
+,deployment_updated
+FROM deployments
+WHERE deployment_repo_id = $1
+ORDER BY deployment_id DESC
+LIMIT 50
+`
+
+var deploymentFindRepoTarget = `
+SELECT
+ deployment_id
+,deployment_repo_id
+,deployment_build_id
+,deployment_number
+,deployment_source
+,deployment_target
+,deployment_commit
+,deployment_status
+,deployment_rollback
+,deployment_creator
+,deployment_created
+,deployment_updated
+FROM deployments
+WHERE deployment_repo_id = $1
+  AND deployment_target = $2
+ORDER BY deployment_id DESC
+LIMIT 50
+`
+
 var feedLatestBuild = `
 SELECT
  repo_owner
@@ -227,6 +561,52 @@ WHERE log_job_id = $1
 LIMIT 1
 `
 
+var logsDeleteProc = `
+DELETE FROM logs WHERE log_job_id = $1
+`
+
+var logsFindAfter = `
+SELECT log_job_id
+FROM logs
+WHERE log_job_id > $1
+ORDER BY log_job_id
+LIMIT $2
+`
+
+var orgSecretFindOwner = `
+SELECT
+ secret_id
+,secret_owner
+,secret_name
+,secret_value
+,secret_images
+,secret_events
+,secret_conceal
+,secret_skip_verify
+FROM org_secrets
+WHERE secret_owner = $1
+ORDER BY secret_name
+`
+
+var orgSecretFindOwnerName = `
+SELECT
+ secret_id
+,secret_owner
+,secret_name
+,secret_value
+,secret_images
+,secret_events
+,secret_conceal
+,secret_skip_verify
+FROM org_secrets
+WHERE secret_owner = $1
+  AND secret_name = $2
+`
+
+var orgSecretDelete = `
+DELETE FROM org_secrets WHERE secret_id = $1
+`
+
 var permsFindUser = `
 SELECT
  perm_user_id
@@ -445,6 +825,8 @@ SELECT
 ,repo_gated
 ,repo_visibility
 ,repo_counter
+,repo_cancel_push
+,repo_cancel_pull
 FROM repos
 INNER JOIN perms ON perms.perm_repo_id = repos.repo_id
 WHERE perms.perm_user_id = $1
@@ -475,7 +857,9 @@ INSERT INTO repos (
 ,repo_gated
 ,repo_visibility
 ,repo_counter
-) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22)
+,repo_cancel_push
+,repo_cancel_pull
+) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24)
 ON CONFLICT (repo_full_name) DO NOTHING
 `
 
@@ -484,6 +868,71 @@ DELETE FROM repos
 WHERE repo_id = $1
 `
 
+var repoFindActive = `
+SELECT *
+FROM repos
+WHERE repo_active = $1
+ORDER BY repo_full_name
+`
+
+var retentionFindRepo = `
+SELECT
+ policy_id
+,policy_repo_id
+,policy_max_age
+,policy_keep_builds
+,policy_keep_success
+,policy_delete_builds
+,policy_created
+,policy_updated
+FROM retention_policies
+WHERE policy_repo_id = $1
+LIMIT 1
+`
+
+var retentionList = `
+SELECT
+ policy_id
+,policy_repo_id
+,policy_max_age
+,policy_keep_builds
+,policy_keep_success
+,policy_delete_builds
+,policy_created
+,policy_updated
+FROM retention_policies
+ORDER BY policy_repo_id
+`
+
+var retentionDelete = `
+DELETE FROM retention_policies WHERE policy_id = $1
+`
+
+var retentionFindBuilds = `
+SELECT *
+FROM builds
+WHERE build_repo_id = $1
+  AND build_status NOT IN ('pending', 'running', 'blocked')
+ORDER BY build_number DESC
+`
+
+var retentionDeleteLogs = `
+DELETE FROM logs
+WHERE log_job_id IN (
+  SELECT proc_id
+  FROM procs
+  WHERE proc_build_id = $1
+)
+`
+
+var retentionUpdateBuildPruned = `
+UPDATE builds SET build_pruned = $1 WHERE build_id = $2
+`
+
+var retentionDeleteBuild = `
+DELETE FROM builds WHERE build_id = $1
+`
+
 var secretFindRepo = `
 SELECT
  secret_id
@@ -548,12 +997,132 @@ var senderDelete = `
 DELETE FROM senders WHERE sender_id = $1
 `
 
+var statsBuilds = `
+SELECT
+ build_branch
+,build_status
+,build_enqueued
+,build_created
+,build_started
+,build_finished
+FROM builds
+WHERE build_repo_id = $1
+  AND build_created >= $2
+  AND ($3 = '' OR build_branch = $4)
+  AND build_status IN ('success', 'failure', 'killed', 'error')
+`
+
+var statsProcs = `
+SELECT
+ proc_name
+,proc_state
+,proc_started
+,proc_stopped
+,build_branch
+,build_created
+FROM builds
+INNER JOIN procs ON proc_build_id = build_id
+WHERE build_repo_id = $1
+  AND build_created >= $2
+  AND ($3 = '' OR build_branch = $4)
+  AND build_status IN ('success', 'failure', 'killed', 'error')
+  AND proc_ppid != 0
+`
+
 var taskList = `
 SELECT
  task_id
 ,task_data
 ,task_labels
+,task_status
+,task_error
+,task_deadline
+,task_created
+,task_updated
+FROM tasks
+`
+
+var taskFind = `
+SELECT
+ task_id
+,task_data
+,task_labels
+,task_status
+,task_error
+,task_deadline
+,task_created
+,task_updated
+FROM tasks
+WHERE task_id = $1
+`
+
+var taskFindStatus = `
+SELECT
+ task_id
+,task_data
+,task_labels
+,task_status
+,task_error
+,task_deadline
+,task_created
+,task_updated
+FROM tasks
+WHERE task_status = $1
+ORDER BY task_created ASC, task_id ASC
+`
+
+var taskFindReady = `
+SELECT
+ task_id
+,task_data
+,task_labels
+,task_status
+,task_error
+,task_deadline
+,task_created
+,task_updated
 FROM tasks
+WHERE task_status = $1
+   OR (task_status = $2 AND task_deadline < $3)
+ORDER BY task_created ASC, task_id ASC
+`
+
+var taskClaim = `
+UPDATE tasks
+SET
+ task_status   = $1
+,task_deadline = $2
+,task_updated  = $3
+WHERE task_id = $4
+  AND task_status = $5
+  AND task_deadline = $6
+`
+
+var taskExtend = `
+UPDATE tasks
+SET
+ task_deadline = $1
+,task_updated  = $2
+WHERE task_id = $3
+  AND task_status = $4
+`
+
+var taskComplete = `
+UPDATE tasks
+SET
+ task_status  = $1
+,task_error   = $2
+,task_updated = $3
+WHERE task_id = $4
+  AND task_status = $5
+`
+
+var taskEvict = `
+DELETE FROM tasks WHERE task_id = $1 AND task_status = $2
+`
+
+var taskPurge = `
+DELETE FROM tasks WHERE task_status = $1 AND task_updated < $2
 `
 
 var taskDelete = `
@@ -613,3 +1182,45 @@ WHERE user_id = $10
 var userDelete = `
 DELETE FROM users WHERE user_id = $1
 `
+
+var webhookFindRepo = `
+SELECT
+ webhook_id
+,webhook_repo_id
+,webhook_url
+,webhook_secret
+,webhook_events
+,webhook_skip_verify
+,webhook_disabled
+,webhook_created
+,webhook_updated
+FROM webhooks
+WHERE webhook_repo_id = $1
+ORDER BY webhook_id
+`
+
+var webhookDelete = `
+DELETE FROM webhooks WHERE webhook_id = $1
+`
+
+var webhookDeliveryFindWebhook = `
+SELECT
+ delivery_id
+,delivery_webhook_id
+,delivery_event
+,delivery_payload
+,delivery_status
+,delivery_error
+,delivery_attempts
+,delivery_redelivery
+,delivery_created
+,delivery_updated
+FROM webhook_deliveries
+WHERE delivery_webhook_id = $1
+ORDER BY delivery_id DESC
+LIMIT 50
+`
+
+var webhookDeliveryDeleteWebhook = `
+DELETE FROM webhook_deliveries WHERE delivery_webhook_id = $1
+`
@@ -0,0 +1,17 @@
+-- name: audit-find
+
+SELECT
+ audit_id
+,audit_actor
+,audit_action
+,audit_repo
+,audit_target
+,audit_ip
+,audit_diff
+,audit_created
+FROM audit_events
+WHERE (? = '' OR audit_actor = ?)
+  AND (? = '' OR audit_repo = ?)
+  AND (? = '' OR audit_action = ?)
+ORDER BY audit_id DESC
+LIMIT ? OFFSET ?
@@ -0,0 +1,64 @@
+-- name: cron-find-repo
+
+SELECT
+ cron_id
+,cron_repo_id
+,cron_name
+,cron_expr
+,cron_branch
+,cron_next
+,cron_prev
+,cron_disabled
+,cron_created
+,cron_updated
+FROM crons
+WHERE cron_repo_id = ?
+ORDER BY cron_name
+
+-- name: cron-find-repo-name
+
+SELECT
+ cron_id
+,cron_repo_id
+,cron_name
+,cron_expr
+,cron_branch
+,cron_next
+,cron_prev
+,cron_disabled
+,cron_created
+,cron_updated
+FROM crons
+WHERE cron_repo_id = ?
+  AND cron_name = ?
+
+-- name: cron-find-ready
+
+SELECT
+ cron_id
+,cron_repo_id
+,cron_name
+,cron_expr
+,cron_branch
+,cron_next
+,cron_prev
+,cron_disabled
+,cron_created
+,cron_updated
+FROM crons
+WHERE cron_next <= ?
+  AND cron_disabled = ?
+ORDER BY cron_next
+
+-- name: cron-delete
+
+DELETE FROM crons WHERE cron_id = ?
+
+-- name: cron-claim
+
+UPDATE crons
+SET
+ cron_next = ?
+,cron_prev = ?
+WHERE cron_id = ?
+  AND cron_next = ?
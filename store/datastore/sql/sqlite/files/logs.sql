@@ -7,3 +7,15 @@ SELECT
 FROM logs
 WHERE log_job_id = ?
 LIMIT 1
+
+-- name: logs-delete-proc
+
+DELETE FROM logs WHERE log_job_id = ?
+
+-- name: logs-find-after
+
+SELECT log_job_id
+FROM logs
+WHERE log_job_id > ?
+ORDER BY log_job_id
+LIMIT ?
@@ -0,0 +1,33 @@
+-- name: org-secret-find-owner
+
+SELECT
+ secret_id
+,secret_owner
+,secret_name
+,secret_value
+,secret_images
+,secret_events
+,secret_conceal
+,secret_skip_verify
+FROM org_secrets
+WHERE secret_owner = ?
+ORDER BY secret_name
+
+-- name: org-secret-find-owner-name
+
+SELECT
+ secret_id
+,secret_owner
+,secret_name
+,secret_value
+,secret_images
+,secret_events
+,secret_conceal
+,secret_skip_verify
+FROM org_secrets
+WHERE secret_owner = ?
+  AND secret_name = ?
+
+-- name: org-secret-delete
+
+DELETE FROM org_secrets WHERE secret_id = ?
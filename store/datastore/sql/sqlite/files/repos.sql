@@ -30,6 +30,8 @@ SELECT
 ,repo_gated
 ,repo_visibility
 ,repo_counter
+,repo_cancel_push
+,repo_cancel_pull
 FROM repos
 INNER JOIN perms ON perms.perm_repo_id = repos.repo_id
 WHERE perms.perm_user_id = ?
@@ -60,8 +62,17 @@ INSERT OR IGNORE INTO repos (
 ,repo_gated
 ,repo_visibility
 ,repo_counter
-) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)
+,repo_cancel_push
+,repo_cancel_pull
+) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)
 
 -- name: repo-delete
 
 DELETE FROM repos WHERE repo_id = ?
+
+-- name: repo-find-active
+
+SELECT *
+FROM repos
+WHERE repo_active = ?
+ORDER BY repo_full_name
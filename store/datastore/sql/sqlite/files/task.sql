@@ -4,8 +4,96 @@ SELECT
  task_id
 ,task_data
 ,task_labels
+,task_status
+,task_error
+,task_deadline
+,task_created
+,task_updated
 FROM tasks
 
+-- name: task-find
+
+SELECT
+ task_id
+,task_data
+,task_labels
+,task_status
+,task_error
+,task_deadline
+,task_created
+,task_updated
+FROM tasks
+WHERE task_id = ?
+
+-- name: task-find-status
+
+SELECT
+ task_id
+,task_data
+,task_labels
+,task_status
+,task_error
+,task_deadline
+,task_created
+,task_updated
+FROM tasks
+WHERE task_status = ?
+ORDER BY task_created ASC, task_id ASC
+
+-- name: task-find-ready
+
+SELECT
+ task_id
+,task_data
+,task_labels
+,task_status
+,task_error
+,task_deadline
+,task_created
+,task_updated
+FROM tasks
+WHERE task_status = ?
+   OR (task_status = ? AND task_deadline < ?)
+ORDER BY task_created ASC, task_id ASC
+
+-- name: task-claim
+
+UPDATE tasks
+SET
+ task_status   = ?
+,task_deadline = ?
+,task_updated  = ?
+WHERE task_id = ?
+  AND task_status = ?
+  AND task_deadline = ?
+
+-- name: task-extend
+
+UPDATE tasks
+SET
+ task_deadline = ?
+,task_updated  = ?
+WHERE task_id = ?
+  AND task_status = ?
+
+-- name: task-complete
+
+UPDATE tasks
+SET
+ task_status  = ?
+,task_error   = ?
+,task_updated = ?
+WHERE task_id = ?
+  AND task_status = ?
+
+-- name: task-evict
+
+DELETE FROM tasks WHERE task_id = ? AND task_status = ?
+
+-- name: task-purge
+
+DELETE FROM tasks WHERE task_status = ? AND task_updated < ?
+
 -- name: task-delete
 
 DELETE FROM tasks WHERE task_id = ?
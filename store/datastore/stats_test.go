@@ -0,0 +1,77 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"testing"
+
+	"github.com/drone/drone/model"
+)
+
+func TestStatsList(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from repos")
+		s.Exec("delete from builds")
+		s.Exec("delete from procs")
+		s.Close()
+	}()
+
+	repo := &model.Repo{UserID: 1, FullName: "octocat/hello-world", Owner: "octocat", Name: "hello-world"}
+	s.CreateRepo(repo)
+
+	build := &model.Build{RepoID: repo.ID, Status: model.StatusSuccess, Branch: "master"}
+	s.CreateBuild(build,
+		&model.Proc{PID: 1, PPID: 0, Name: "pipeline", State: model.StatusSuccess},
+		&model.Proc{PID: 2, PPID: 1, Name: "test", State: model.StatusSuccess, Started: 10, Stopped: 70},
+	)
+	s.CreateBuild(&model.Build{RepoID: repo.ID, Status: model.StatusRunning, Branch: "master"},
+		&model.Proc{PID: 2, PPID: 1, Name: "test", State: model.StatusRunning, Started: 10},
+	)
+
+	builds, err := s.StatsBuildList(repo, "", build.Created)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(builds), 1; got != want {
+		t.Errorf("Want %d finished builds, got %d", want, got)
+		return
+	}
+	if got, want := builds[0].Branch, "master"; got != want {
+		t.Errorf("Want build branch %s, got %s", want, got)
+	}
+	if builds, _ := s.StatsBuildList(repo, "develop", build.Created); len(builds) != 0 {
+		t.Errorf("Want builds filtered by branch")
+	}
+
+	procs, err := s.StatsProcList(repo, "", build.Created)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(procs), 1; got != want {
+		t.Errorf("Want %d steps, got %d", want, got)
+		return
+	}
+	if got, want := procs[0].Stopped-procs[0].Started, int64(60); got != want {
+		t.Errorf("Want step duration %d, got %d", want, got)
+	}
+
+	builds, _ = s.StatsBuildList(repo, "", build.Created+3600)
+	if got, want := len(builds), 0; got != want {
+		t.Errorf("Want %d builds since an hour later, got %d", want, got)
+	}
+}
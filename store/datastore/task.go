@@ -15,6 +15,9 @@
 package datastore
 
 import (
+	gosql "database/sql"
+	"time"
+
 	"github.com/drone/drone/model"
 	"github.com/drone/drone/store/datastore/sql"
 	"github.com/russross/meddler"
@@ -36,3 +39,89 @@ func (db *datastore) TaskDelete(id string) error {
 	_, err := db.Exec(stmt, id)
 	return err
 }
+
+func (db *datastore) TaskFind(id string) (*model.Task, error) {
+	stmt := sql.Lookup(db.driver, "task-find")
+	data := new(model.Task)
+	err := meddler.QueryRow(db, data, stmt, id)
+	return data, err
+}
+
+func (db *datastore) TaskListStatus(status string) ([]*model.Task, error) {
+	stmt := sql.Lookup(db.driver, "task-find-status")
+	data := []*model.Task{}
+	err := meddler.QueryAll(db, &data, stmt, status)
+	return data, err
+}
+
+func (db *datastore) TaskListReady(now int64) ([]*model.Task, error) {
+	stmt := sql.Lookup(db.driver, "task-find-ready")
+	data := []*model.Task{}
+	err := meddler.QueryAll(db, &data, stmt, model.TaskPending, model.TaskRunning, now)
+	return data, err
+}
+
+func (db *datastore) TaskClaim(task *model.Task, deadline int64) (bool, error) {
+	stmt := sql.Lookup(db.driver, "task-claim")
+	now := time.Now().Unix()
+	res, err := db.Exec(stmt, model.TaskRunning, deadline, now, task.ID, task.Status, task.Deadline)
+	if err != nil {
+		return false, err
+	}
+	if ok, err := affected(res); !ok || err != nil {
+		return false, err
+	}
+	task.Status = model.TaskRunning
+	task.Deadline = deadline
+	task.Updated = now
+	return true, nil
+}
+
+func (db *datastore) TaskExtend(id string, deadline int64) (bool, error) {
+	stmt := sql.Lookup(db.driver, "task-extend")
+	res, err := db.Exec(stmt, deadline, time.Now().Unix(), id, model.TaskRunning)
+	if err != nil {
+		return false, err
+	}
+	if ok, err := affected(res); ok || err != nil {
+		return ok, err
+	}
+	// mysql does not count rows that are matched but left unchanged,
+	// which happens when the deadline is extended twice in one second.
+	task, err := db.TaskFind(id)
+	if err != nil {
+		return false, nil
+	}
+	return task.Status == model.TaskRunning, nil
+}
+
+func (db *datastore) TaskComplete(id, message string) (bool, error) {
+	stmt := sql.Lookup(db.driver, "task-complete")
+	res, err := db.Exec(stmt, model.TaskDone, message, time.Now().Unix(), id, model.TaskRunning)
+	if err != nil {
+		return false, err
+	}
+	return affected(res)
+}
+
+func (db *datastore) TaskEvict(id string) (bool, error) {
+	stmt := sql.Lookup(db.driver, "task-evict")
+	res, err := db.Exec(stmt, id, model.TaskPending)
+	if err != nil {
+		return false, err
+	}
+	return affected(res)
+}
+
+func (db *datastore) TaskPurge(before int64) error {
+	stmt := sql.Lookup(db.driver, "task-purge")
+	_, err := db.Exec(stmt, model.TaskDone, before)
+	return err
+}
+
+// helper function returns true if the statement modified
+// at least one row.
+func affected(res gosql.Result) (bool, error) {
+	rows, err := res.RowsAffected()
+	return rows != 0, err
+}
@@ -15,8 +15,12 @@
 package datastore
 
 import (
+	"context"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/cncd/queue"
 	"github.com/drone/drone/model"
 )
 
@@ -64,3 +68,182 @@ func TestTaskList(t *testing.T) {
 		t.Errorf("Want empty task list after delete")
 	}
 }
+
+func TestTaskListOrder(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from tasks")
+		s.Close()
+	}()
+
+	// tasks created in the same second are ordered by id.
+	for _, id := range []string{"3", "1", "2"} {
+		s.TaskInsert(&model.Task{ID: id, Status: model.TaskPending, Created: 1})
+	}
+	list, err := s.TaskListStatus(model.TaskPending)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	var ids []string
+	for _, task := range list {
+		ids = append(ids, task.ID)
+	}
+	if got, want := strings.Join(ids, ","), "1,2,3"; got != want {
+		t.Errorf("Want tasks ordered %s, got %s", want, got)
+	}
+}
+
+func TestTaskClaim(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from tasks")
+		s.Close()
+	}()
+
+	s.TaskInsert(&model.Task{
+		ID:     "1",
+		Data:   []byte("foo"),
+		Status: model.TaskPending,
+	})
+
+	list, err := s.TaskListReady(time.Now().Unix())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(list), 1; got != want {
+		t.Errorf("Want %d ready task, got %d", want, got)
+		return
+	}
+
+	// a stale copy of the task cannot be claimed twice
+	stale := *list[0]
+	ok, err := s.TaskClaim(list[0], time.Now().Add(time.Minute).Unix())
+	if err != nil || !ok {
+		t.Errorf("Want task claimed, got %v, %v", ok, err)
+	}
+	ok, err = s.TaskClaim(&stale, time.Now().Add(time.Minute).Unix())
+	if err != nil || ok {
+		t.Errorf("Want stale task claim rejected, got %v, %v", ok, err)
+	}
+
+	list, _ = s.TaskListReady(time.Now().Unix())
+	if got, want := len(list), 0; got != want {
+		t.Errorf("Want no ready tasks while leased, got %d", got)
+	}
+
+	// an expired lease makes the task ready again
+	list, _ = s.TaskListReady(time.Now().Add(time.Hour).Unix())
+	if got, want := len(list), 1; got != want {
+		t.Errorf("Want ready task after lease expired, got %d", got)
+	}
+}
+
+func TestTaskComplete(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from tasks")
+		s.Close()
+	}()
+
+	task := &model.Task{
+		ID:     "1",
+		Status: model.TaskPending,
+	}
+	s.TaskInsert(task)
+
+	if ok, _ := s.TaskExtend("1", time.Now().Unix()); ok {
+		t.Errorf("Want pending task extend rejected")
+	}
+	if ok, _ := s.TaskComplete("1", ""); ok {
+		t.Errorf("Want pending task completion rejected")
+	}
+
+	s.TaskClaim(task, time.Now().Add(time.Minute).Unix())
+	if ok, _ := s.TaskExtend("1", time.Now().Add(time.Hour).Unix()); !ok {
+		t.Errorf("Want running task extended")
+	}
+	if ok, _ := s.TaskEvict("1"); ok {
+		t.Errorf("Want running task eviction rejected")
+	}
+	if ok, _ := s.TaskComplete("1", "queue: task cancelled"); !ok {
+		t.Errorf("Want running task completed")
+	}
+
+	done, err := s.TaskFind("1")
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := done.Status, model.TaskDone; got != want {
+		t.Errorf("Want task status %s, got %s", want, got)
+	}
+	if got, want := done.Error, "queue: task cancelled"; got != want {
+		t.Errorf("Want task error %s, got %s", want, got)
+	}
+
+	s.TaskPurge(time.Now().Add(time.Minute).Unix())
+	if _, err := s.TaskFind("1"); err == nil {
+		t.Errorf("Want completed task purged")
+	}
+}
+
+func TestTaskQueue(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from tasks")
+		s.Close()
+	}()
+
+	// the in-memory database is not shared between connections.
+	s.SetMaxOpenConns(1)
+
+	// two queues sharing a database simulate two servers.
+	q1 := model.NewTaskQueue(s)
+	q2 := model.NewTaskQueue(s)
+
+	c := context.Background()
+	q1.Push(c, &queue.Task{ID: "1", Labels: map[string]string{"platform": "linux/amd64"}})
+	q1.Push(c, &queue.Task{ID: "2", Labels: map[string]string{"platform": "linux/arm"}})
+
+	ctx, cancel := context.WithTimeout(c, time.Second*5)
+	defer cancel()
+
+	filter := func(task *queue.Task) bool {
+		return task.Labels["platform"] == "linux/arm"
+	}
+	task, err := q2.Poll(ctx, filter)
+	if err != nil || task == nil {
+		t.Errorf("Want task polled, got %v", err)
+		return
+	}
+	if got, want := task.ID, "2"; got != want {
+		t.Errorf("Want task %s matching filter, got %s", want, got)
+	}
+
+	any := func(*queue.Task) bool { return true }
+	task, _ = q1.Poll(ctx, any)
+	if got, want := task.ID, "1"; got != want {
+		t.Errorf("Want task %s, got %s", want, got)
+	}
+
+	info := q1.Info(c)
+	if got, want := info.Stats.Running, 2; got != want {
+		t.Errorf("Want %d running tasks, got %d", want, got)
+	}
+
+	if err := q2.Evict(c, "1"); err != queue.ErrNotFound {
+		t.Errorf("Want running task eviction rejected, got %v", err)
+	}
+
+	waited := make(chan error, 1)
+	go func() {
+		waited <- q2.Wait(ctx, "1")
+	}()
+	q1.Error(c, "1", queue.ErrCancel)
+
+	if got, want := <-waited, queue.ErrCancel; got != want {
+		t.Errorf("Want wait cancelled, got %v", got)
+	}
+}
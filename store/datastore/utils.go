@@ -16,6 +16,7 @@ package datastore
 
 import (
 	"strconv"
+	"strings"
 
 	"github.com/russross/meddler"
 )
@@ -47,3 +48,18 @@ func rebind(query string) string {
 	}
 	return string(rqb)
 }
+
+// likeEscaper escapes the LIKE wildcards using the ! escape character,
+// which is supported by all drivers with the ESCAPE '!' clause.
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// likeEscape escapes the string for use in a LIKE pattern.
+func likeEscape(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// likeGlob converts the glob pattern to a LIKE pattern, where * matches
+// any sequence of characters and ? matches a single character.
+func likeGlob(pattern string) string {
+	return strings.NewReplacer("*", "%", "?", "_").Replace(likeEscape(pattern))
+}
@@ -0,0 +1,80 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"github.com/drone/drone/model"
+	"github.com/drone/drone/store/datastore/sql"
+	"github.com/russross/meddler"
+)
+
+func (db *datastore) WebhookFind(id int64) (*model.Webhook, error) {
+	data := new(model.Webhook)
+	err := meddler.Load(db, "webhooks", data, id)
+	return data, err
+}
+
+func (db *datastore) WebhookList(repo *model.Repo) ([]*model.Webhook, error) {
+	stmt := sql.Lookup(db.driver, "webhook-find-repo")
+	data := []*model.Webhook{}
+	err := meddler.QueryAll(db, &data, stmt, repo.ID)
+	return data, err
+}
+
+func (db *datastore) WebhookListGlobal() ([]*model.Webhook, error) {
+	stmt := sql.Lookup(db.driver, "webhook-find-repo")
+	data := []*model.Webhook{}
+	err := meddler.QueryAll(db, &data, stmt, 0)
+	return data, err
+}
+
+func (db *datastore) WebhookCreate(hook *model.Webhook) error {
+	return meddler.Insert(db, "webhooks", hook)
+}
+
+func (db *datastore) WebhookUpdate(hook *model.Webhook) error {
+	return meddler.Update(db, "webhooks", hook)
+}
+
+func (db *datastore) WebhookDelete(hook *model.Webhook) error {
+	stmt := sql.Lookup(db.driver, "webhook-delivery-delete-webhook")
+	if _, err := db.Exec(stmt, hook.ID); err != nil {
+		return err
+	}
+	stmt = sql.Lookup(db.driver, "webhook-delete")
+	_, err := db.Exec(stmt, hook.ID)
+	return err
+}
+
+func (db *datastore) WebhookDeliveryFind(id int64) (*model.WebhookDelivery, error) {
+	data := new(model.WebhookDelivery)
+	err := meddler.Load(db, "webhook_deliveries", data, id)
+	return data, err
+}
+
+func (db *datastore) WebhookDeliveryList(hook *model.Webhook) ([]*model.WebhookDelivery, error) {
+	stmt := sql.Lookup(db.driver, "webhook-delivery-find-webhook")
+	data := []*model.WebhookDelivery{}
+	err := meddler.QueryAll(db, &data, stmt, hook.ID)
+	return data, err
+}
+
+func (db *datastore) WebhookDeliveryCreate(delivery *model.WebhookDelivery) error {
+	return meddler.Insert(db, "webhook_deliveries", delivery)
+}
+
+func (db *datastore) WebhookDeliveryUpdate(delivery *model.WebhookDelivery) error {
+	return meddler.Update(db, "webhook_deliveries", delivery)
+}
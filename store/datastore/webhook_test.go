@@ -0,0 +1,137 @@
+// Copyright 2018 Drone.IO Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"testing"
+
+	"github.com/drone/drone/model"
+)
+
+func TestWebhookList(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from webhooks")
+		s.Close()
+	}()
+
+	s.WebhookCreate(&model.Webhook{RepoID: 1, URL: "https://example.com/a"})
+	s.WebhookCreate(&model.Webhook{RepoID: 1, URL: "https://example.com/b", Events: []model.EventType{model.Finished}})
+	s.WebhookCreate(&model.Webhook{RepoID: 2, URL: "https://example.com/c"})
+	s.WebhookCreate(&model.Webhook{URL: "https://example.com/global"})
+
+	list, err := s.WebhookList(&model.Repo{ID: 1})
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(list), 2; got != want {
+		t.Errorf("Want %d webhooks, got %d", want, got)
+		return
+	}
+	if got, want := list[1].Events[0], model.Finished; got != want {
+		t.Errorf("Want webhook event %s, got %s", want, got)
+	}
+
+	list, err = s.WebhookListGlobal()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(list), 1; got != want {
+		t.Errorf("Want %d global webhooks, got %d", want, got)
+		return
+	}
+	if got, want := list[0].URL, "https://example.com/global"; got != want {
+		t.Errorf("Want webhook url %s, got %s", want, got)
+	}
+}
+
+func TestWebhookUpdate(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from webhooks")
+		s.Close()
+	}()
+
+	hook := &model.Webhook{RepoID: 1, URL: "https://example.com/a"}
+	if err := s.WebhookCreate(hook); err != nil {
+		t.Errorf("Unexpected error: insert webhook: %s", err)
+		return
+	}
+	hook.Disabled = true
+	if err := s.WebhookUpdate(hook); err != nil {
+		t.Errorf("Unexpected error: update webhook: %s", err)
+		return
+	}
+	found, err := s.WebhookFind(hook.ID)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if !found.Disabled {
+		t.Errorf("Want webhook disabled")
+	}
+}
+
+func TestWebhookDeliveries(t *testing.T) {
+	s := newTest()
+	defer func() {
+		s.Exec("delete from webhooks")
+		s.Exec("delete from webhook_deliveries")
+		s.Close()
+	}()
+
+	hook := &model.Webhook{RepoID: 1, URL: "https://example.com/a"}
+	if err := s.WebhookCreate(hook); err != nil {
+		t.Errorf("Unexpected error: insert webhook: %s", err)
+		return
+	}
+	first := &model.WebhookDelivery{WebhookID: hook.ID, Event: model.Started, Payload: []byte("{}")}
+	second := &model.WebhookDelivery{WebhookID: hook.ID, Event: model.Finished, Payload: []byte("{}")}
+	s.WebhookDeliveryCreate(first)
+	s.WebhookDeliveryCreate(second)
+
+	second.Status = 200
+	second.Attempts = 2
+	if err := s.WebhookDeliveryUpdate(second); err != nil {
+		t.Errorf("Unexpected error: update delivery: %s", err)
+		return
+	}
+
+	list, err := s.WebhookDeliveryList(hook)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if got, want := len(list), 2; got != want {
+		t.Errorf("Want %d deliveries, got %d", want, got)
+		return
+	}
+	if got, want := list[0].ID, second.ID; got != want {
+		t.Errorf("Want most recent delivery first")
+	}
+	if got, want := list[0].Attempts, 2; got != want {
+		t.Errorf("Want delivery attempts %d, got %d", want, got)
+	}
+
+	if err := s.WebhookDelete(hook); err != nil {
+		t.Errorf("Unexpected error: delete webhook: %s", err)
+		return
+	}
+	if _, err := s.WebhookDeliveryFind(first.ID); err == nil {
+		t.Errorf("Want deliveries removed with the webhook")
+	}
+}
@@ -83,6 +83,16 @@ type Store interface {
 	// GetBuildList gets a list of builds for the repository
 	GetBuildList(*model.Repo, int) ([]*model.Build, error)
 
+	// GetBuildListFilter gets a filtered list of builds for the repository.
+	GetBuildListFilter(*model.Repo, *model.BuildFilter) ([]*model.Build, error)
+
+	// GetBuildSearch gets a filtered list of builds for all repositories.
+	GetBuildSearch(*model.BuildFilter) ([]*model.Feed, error)
+
+	// GetBuildSuperseded gets a list of pending and running builds of the
+	// same repository, event and ref that are older than the build.
+	GetBuildSuperseded(*model.Build) ([]*model.Build, error)
+
 	// GetBuildQueue gets a list of build in queue.
 	GetBuildQueue() ([]*model.Feed, error)
 
@@ -103,6 +113,7 @@ type Store interface {
 
 	RepoList(*model.User) ([]*model.Repo, error)
 	RepoListLatest(*model.User) ([]*model.Feed, error)
+	RepoListActive() ([]*model.Repo, error)
 	RepoBatch([]*model.Repo) error
 
 	PermFind(user *model.User, repo *model.Repo) (*model.Perm, error)
@@ -128,12 +139,26 @@ type Store interface {
 	SecretUpdate(*model.Secret) error
 	SecretDelete(*model.Secret) error
 
+	OrgSecretFind(string, string) (*model.Secret, error)
+	OrgSecretList(string) ([]*model.Secret, error)
+	OrgSecretCreate(*model.Secret) error
+	OrgSecretUpdate(*model.Secret) error
+	OrgSecretDelete(*model.Secret) error
+
 	RegistryFind(*model.Repo, string) (*model.Registry, error)
 	RegistryList(*model.Repo) ([]*model.Registry, error)
 	RegistryCreate(*model.Registry) error
 	RegistryUpdate(*model.Registry) error
 	RegistryDelete(*model.Registry) error
 
+	CronFind(*model.Repo, string) (*model.Cron, error)
+	CronList(*model.Repo) ([]*model.Cron, error)
+	CronListReady(int64) ([]*model.Cron, error)
+	CronCreate(*model.Cron) error
+	CronUpdate(*model.Cron) error
+	CronClaim(*model.Cron, int64) (bool, error)
+	CronDelete(*model.Cron) error
+
 	ProcLoad(int64) (*model.Proc, error)
 	ProcFind(*model.Build, int) (*model.Proc, error)
 	ProcChild(*model.Build, int, string) (*model.Proc, error)
@@ -144,6 +169,8 @@ type Store interface {
 
 	LogFind(*model.Proc) (io.ReadCloser, error)
 	LogSave(*model.Proc, io.Reader) error
+	LogDelete(*model.Proc) error
+	LogListProcs(int64, int) ([]int64, error)
 
 	FileList(*model.Build) ([]*model.File, error)
 	FileFind(*model.Proc, string) (*model.File, error)
@@ -153,6 +180,59 @@ type Store interface {
 	TaskList() ([]*model.Task, error)
 	TaskInsert(*model.Task) error
 	TaskDelete(string) error
+	TaskFind(string) (*model.Task, error)
+	TaskListStatus(string) ([]*model.Task, error)
+	TaskListReady(int64) ([]*model.Task, error)
+	TaskClaim(*model.Task, int64) (bool, error)
+	TaskExtend(string, int64) (bool, error)
+	TaskComplete(string, string) (bool, error)
+	TaskEvict(string) (bool, error)
+	TaskPurge(int64) error
+
+	AgentFind(string) (*model.Agent, error)
+	AgentList() ([]*model.Agent, error)
+	AgentCreate(*model.Agent) error
+	AgentUpdate(*model.Agent) error
+	AgentCheckin(*model.Agent) error
+	AgentSeen(*model.Agent) error
+	AgentDelete(*model.Agent) error
+
+	AgentTokenFind(int64) (*model.AgentToken, error)
+	AgentTokenFindHash(string) (*model.AgentToken, error)
+	AgentTokenList() ([]*model.AgentToken, error)
+	AgentTokenCreate(*model.AgentToken) error
+	AgentTokenDelete(*model.AgentToken) error
+
+	WebhookFind(int64) (*model.Webhook, error)
+	WebhookList(*model.Repo) ([]*model.Webhook, error)
+	WebhookListGlobal() ([]*model.Webhook, error)
+	WebhookCreate(*model.Webhook) error
+	WebhookUpdate(*model.Webhook) error
+	WebhookDelete(*model.Webhook) error
+	WebhookDeliveryFind(int64) (*model.WebhookDelivery, error)
+	WebhookDeliveryList(*model.Webhook) ([]*model.WebhookDelivery, error)
+	WebhookDeliveryCreate(*model.WebhookDelivery) error
+	WebhookDeliveryUpdate(*model.WebhookDelivery) error
+
+	DeploymentFindBuild(*model.Build) (*model.Deployment, error)
+	DeploymentList(*model.Repo, string) ([]*model.Deployment, error)
+	DeploymentCreate(*model.Deployment) error
+	DeploymentUpdate(*model.Deployment) error
+
+	RetentionFind(int64) (*model.Retention, error)
+	RetentionList() ([]*model.Retention, error)
+	RetentionCreate(*model.Retention) error
+	RetentionUpdate(*model.Retention) error
+	RetentionDelete(*model.Retention) error
+	RetentionBuildList(*model.Repo) ([]*model.Build, error)
+	BuildPrune(*model.Build) error
+	BuildDelete(*model.Build) error
+
+	AuditCreate(*model.AuditEvent) error
+	AuditList(*model.AuditFilter) ([]*model.AuditEvent, error)
+
+	StatsBuildList(*model.Repo, string, int64) ([]*model.StatsBuild, error)
+	StatsProcList(*model.Repo, string, int64) ([]*model.StatsProc, error)
 
 	Ping() error
 }
@@ -241,6 +321,18 @@ func GetBuildList(c context.Context, repo *model.Repo, page int) ([]*model.Build
 	return FromContext(c).GetBuildList(repo, page)
 }
 
+func GetBuildListFilter(c context.Context, repo *model.Repo, filter *model.BuildFilter) ([]*model.Build, error) {
+	return FromContext(c).GetBuildListFilter(repo, filter)
+}
+
+func GetBuildSearch(c context.Context, filter *model.BuildFilter) ([]*model.Feed, error) {
+	return FromContext(c).GetBuildSearch(filter)
+}
+
+func GetBuildSuperseded(c context.Context, build *model.Build) ([]*model.Build, error) {
+	return FromContext(c).GetBuildSuperseded(build)
+}
+
 func GetBuildQueue(c context.Context) ([]*model.Feed, error) {
 	return FromContext(c).GetBuildQueue()
 }